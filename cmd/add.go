@@ -2,14 +2,19 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
 	"strings"
 	"time"
 
+	"charm.land/lipgloss/v2"
 	"github.com/spf13/cobra"
 	"github.com/yagnikpt/flashback/internal/app"
-	"github.com/yagnikpt/flashback/internal/components/spinner"
-	"github.com/yagnikpt/flashback/internal/contentloaders"
+	"github.com/yagnikpt/flashback/internal/ingest"
+	"github.com/yagnikpt/flashback/internal/models"
+	"github.com/yagnikpt/flashback/internal/utils"
+	"github.com/yagnikpt/flashback/internal/worker"
 )
 
 func NewAddCmd(app *app.App) *cobra.Command {
@@ -19,91 +24,179 @@ func NewAddCmd(app *app.App) *cobra.Command {
 		Short:   "Add a new note from text or URL",
 		Long: `Add a new note to the flashback database. Provide text directly or a URL to fetch and store webpage content. The tool automatically generates metadata and embeddings for semantic search.
 
+The note is saved right away and its metadata and embedding are generated in the background by the TUI or flashback worker. Use --wait to generate them before returning. If generating fails, the note is kept and the job is retried later (see flashback jobs).
+
+URLs are saved without tracking parameters, fragments and trailing slashes. When the note duplicates a saved one (the same URL or text, or a text note with nearly the same meaning), you are asked whether to merge its tags into the saved note, update the saved note with it or keep both. --on-duplicate chooses the action up front, and is needed to add a duplicate without a terminal.
+
+With --in the note is added to a collection, which is created if needed (repeatable). Without it the note goes to default_collection when the config sets one.
+
+With --quiet only the ID of the new note is printed.
+
 Examples:
   flashback add Remember to buy groceries
   flashback add https://example.com/useful-article
+  flashback add --wait --tags k8s,infra kubectl rollout restart deployment web
+  flashback add --on-duplicate keep https://example.com/useful-article
+  flashback add --in homelab https://example.com/useful-article
 `,
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) == 0 {
 				fmt.Println(cmd.Long)
-				return
+				return nil
 			}
 
-			statusChan := make(chan string)
-			errorChan := make(chan error)
+			tagsFlag, _ := cmd.Flags().GetString("tags")
+			tags := utils.SplitTags(tagsFlag)
 
-			ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
-			defer cancel()
+			wait, _ := cmd.Flags().GetBool("wait")
+			onDuplicateFlag, _ := cmd.Flags().GetString("on-duplicate")
+			onDuplicate, err := ingest.ParseDuplicateAction(onDuplicateFlag)
+			if err != nil {
+				return withCode(exitUsage, "Error: %w", err)
+			}
 
-			go func() {
-				words := strings.Join(args, " ")
-				var metadata map[string]string
-				var noteType string
-				if len(args) == 1 && strings.HasPrefix(words, "http") {
-					statusChan <- "Fetching webpage content..."
-					pageContent, err := contentloaders.GetWebPage(ctx, words)
-					if err != nil {
-						errorChan <- err
-						return
-					}
-					pageContentWithUrl := fmt.Sprintf("URL: %s\n\n%s", words, pageContent)
-					statusChan <- "Generating metadata for webpage..."
-					metadata, err = app.GenerateMetadataForWebNote(ctx, pageContentWithUrl)
-					if err != nil {
-						errorChan <- err
-						return
-					}
-					noteType = "url"
-				} else {
-					statusChan <- "Generating metadata for note..."
-					_metadata, err := app.GenerateMetadataForSimpleNote(ctx, words)
-					if err != nil {
-						errorChan <- err
-						return
-					}
-					metadata = _metadata
-					noteType = "text"
+			collections, _ := cmd.Flags().GetStringSlice("in")
+
+			// --wait only runs the jobs this add enqueues, which come after
+			// the newest job so far.
+			var lastJob int64
+			if wait {
+				ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+				lastJob, err = app.LastJobID(ctx)
+				cancel()
+				if err != nil {
+					return withCode(exitStorage, "Error reading the jobs queue: %w", err)
 				}
-				var finalContent strings.Builder
-				userInput := fmt.Sprintf("USER INPUT:\n content: %s\n", words)
-				finalContent.WriteString(userInput)
-				finalContent.WriteString("METADATA:\n")
-				for key, value := range metadata {
-					metadataLine := fmt.Sprintf(" %s: %s\n", key, value)
-					finalContent.WriteString(metadataLine)
+			}
+
+			note := &ingest.Note{Content: strings.Join(args, " "), Tags: tags, Collections: collections, OnDuplicate: onDuplicate}
+			store := func() error {
+				ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+				defer cancel()
+
+				return runWithProgress(ctx, cmd, func(ctx context.Context, report func(string)) error {
+					events := make(chan ingest.Event)
+					done := make(chan error, 1)
+					go func() {
+						done <- ingest.New(app).Run(ctx, note, events)
+					}()
+					for event := range events {
+						report(event.Message)
+					}
+					return <-done
+				})
+			}
+
+			err = store()
+			if errors.Is(err, ingest.ErrDuplicate) {
+				if !utils.IsInteractive() {
+					return withCode(exitUsage, "The note duplicates %s; use --on-duplicate merge, update or keep to choose what to do without a terminal.", note.Duplicate.Note.ID)
 				}
-				statusChan <- "Saving the note..."
-				embeddings, err := app.GenerateEmbeddingForNote(ctx, finalContent.String(), "RETRIEVAL_DOCUMENT")
+				note.OnDuplicate, err = askDuplicateAction(*note.Duplicate)
 				if err != nil {
-					errorChan <- err
-					return
+					return withCode(exitUsage, "Error reading the answer: %w", err)
 				}
-				err = app.InsertNote(ctx, words, noteType, metadata, embeddings)
+				err = store()
+			}
+			if err != nil {
+				return ingestError(err)
+			}
+
+			// running is set when another worker, such as the TUI's, claimed
+			// the job first.
+			running := false
+			if wait {
+				err = runWithProgress(context.Background(), cmd, func(ctx context.Context, report func(string)) error {
+					for {
+						job, ok, err := app.ClaimNoteJob(ctx, note.ID, lastJob)
+						if err != nil {
+							return withCode(exitStorage, "Error reading the jobs queue: %w", err)
+						}
+						if !ok {
+							break
+						}
+						if err := worker.Process(ctx, app, job, report); err != nil {
+							return err
+						}
+					}
+					var err error
+					running, err = app.RunningNoteJob(ctx, note.ID, lastJob)
+					if err != nil {
+						return withCode(exitStorage, "Error reading the jobs queue: %w", err)
+					}
+					return nil
+				})
 				if err != nil {
-					errorChan <- err
-				} else {
-					close(statusChan)
-					close(errorChan)
+					fmt.Fprintf(os.Stderr, "Note %s was saved; generating its metadata will be retried by flashback worker.\n", note.ID)
+					return ingestError(err)
 				}
-			}()
-
-			go func() {
-				err, ok := <-errorChan
-				if !ok {
-					fmt.Print("\033[A\033[2K")
-					fmt.Println("Note added successfully!")
-					return
-				}
-				fmt.Printf("Error: %v\n", err)
-				close(statusChan)
-				close(errorChan)
-			}()
+			}
 
-			spinner.Run(statusChan, false)
+			if isQuiet(cmd) {
+				fmt.Println(note.ID)
+			} else if note.Duplicate != nil && note.OnDuplicate == ingest.DuplicateMerge {
+				fmt.Printf("Already saved as %s; tags were merged into it.\n", note.ID)
+			} else if note.Duplicate != nil && note.OnDuplicate == ingest.DuplicateUpdate {
+				fmt.Printf("Updated note %s; its metadata is generated again.\n", note.ID)
+			} else if running {
+				fmt.Printf("Note %s was saved; another worker is generating its metadata.\n", note.ID)
+			} else if wait {
+				fmt.Println("Note added successfully!")
+			} else {
+				fmt.Println("Note added successfully! Metadata is generated in the background; run flashback worker if the TUI isn't open.")
+			}
+			return nil
 		},
 	}
 
 	cmd.Flags().StringP("tags", "t", "", "Comma separated tags for the record")
+	cmd.Flags().StringSlice("in", nil, "Add the note to this collection (repeatable)")
+	cmd.Flags().BoolP("wait", "w", false, "Generate metadata and embedding before returning")
+	cmd.Flags().String("on-duplicate", string(ingest.DuplicateAsk), "What to do when the note duplicates a saved one: ask, merge, update or keep")
 
 	return cmd
 }
+
+// askDuplicateAction shows the saved note d and asks what to do with the new
+// one.
+func askDuplicateAction(d app.Duplicate) (ingest.DuplicateAction, error) {
+	fmt.Fprintf(os.Stderr, "This looks like a note you already saved (%s):\n", d.Match())
+	lipgloss.Fprint(os.Stderr, utils.FormatNoteList([]models.FlashbackWithMetadata{d.Note}))
+	for {
+		answer, err := promptChoice("[m]erge tags into it, [u]pdate it or [k]eep both? (m)", "m")
+		if err != nil {
+			return "", err
+		}
+		switch answer {
+		case "m":
+			return ingest.DuplicateMerge, nil
+		case "u":
+			return ingest.DuplicateUpdate, nil
+		case "k":
+			return ingest.DuplicateKeep, nil
+		}
+	}
+}
+
+// ingestError tags an error from the ingest pipeline with the exit code for
+// the stage that failed.
+func ingestError(err error) error {
+	var stageErr *ingest.Error
+	if !errors.As(err, &stageErr) {
+		return err
+	}
+	switch stageErr.Stage {
+	case ingest.StageDedupe:
+		return withCode(exitStorage, "Error checking for duplicates: %w", stageErr.Err)
+	case ingest.StageLoad:
+		return withCode(exitNetwork, "Error fetching webpage: %w", stageErr.Err)
+	case ingest.StageEnrich:
+		return withCode(exitProvider, "Error generating metadata: %w", stageErr.Err)
+	case ingest.StageEmbed:
+		return withCode(exitProvider, "Error generating embedding: %w", stageErr.Err)
+	case ingest.StageStore:
+		return withCode(exitStorage, "Error saving note: %w", stageErr.Err)
+	default:
+		return withCode(exitUsage, "Error: %w", stageErr.Err)
+	}
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+
+	"charm.land/lipgloss/v2"
+	"github.com/spf13/cobra"
+	"github.com/yagnikpt/flashback/internal/app"
+	"github.com/yagnikpt/flashback/internal/providers"
+	"github.com/yagnikpt/flashback/internal/utils"
+)
+
+func NewAskCmd(app *app.App) *cobra.Command {
+	askCmd := &cobra.Command{
+		Use:   "ask <question>",
+		Short: "Answer a question from your notes",
+		Long: `Answer a question using the notes most similar to it. The answer is streamed as it is written and cites the notes it comes from by ID. When no note is related to the question, nothing is generated.
+
+Examples:
+  flashback ask "what was the command we used to rotate the staging certs?"
+  flashback ask -o json "which tools did I save for load testing?"`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			question := strings.TrimSpace(strings.Join(args, " "))
+			if question == "" {
+				return withCode(exitUsage, "Please provide a question.")
+			}
+			output, _ := cmd.Flags().GetString("output")
+			if output != "text" && output != "json" {
+				return withCode(exitUsage, "Error: invalid output format %q (expected text or json)", output)
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+
+			emit := func(part string) { fmt.Print(part) }
+			if output == "json" {
+				emit = func(string) {}
+			}
+			answer, err := app.Ask(ctx, question, emit)
+			if output == "text" && answer.Text != "" {
+				fmt.Println()
+			}
+			if err != nil {
+				return askError(err)
+			}
+
+			if output == "json" {
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(answer)
+			}
+			if len(answer.Citations) > 0 {
+				lipgloss.Println("\n" + utils.FormatCitations(answer.Sources, answer.Citations))
+			}
+			return nil
+		},
+	}
+
+	askCmd.Flags().StringP("output", "o", "text", "Output format: text or json")
+
+	return askCmd
+}
+
+// askError maps a failed answer to an exit code.
+func askError(err error) error {
+	if errors.Is(err, app.ErrNothingRelevant) {
+		return withCode(exitNotFound, "Nothing in your notes seems related to that question.")
+	}
+	if errors.Is(err, context.Canceled) {
+		return withCode(exitInterrupted, "Interrupted.")
+	}
+	var providerErr *providers.Error
+	if errors.As(err, &providerErr) {
+		return withCode(exitProvider, "Error answering the question: %w", err)
+	}
+	return withCode(exitStorage, "Error retrieving notes: %w", err)
+}
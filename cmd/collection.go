@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/yagnikpt/flashback/internal/app"
+)
+
+func NewCollectionCmd(app *app.App) *cobra.Command {
+	collectionCmd := &cobra.Command{
+		Use:     "collection",
+		Aliases: []string{"collections"},
+		Short:   "Organise notes in named collections",
+		Long: `Collections group notes by project; a note can belong to several. Adding a note to a collection that doesn't exist creates it. Use --in with add, list and search to work within a collection, and default_collection in the config to add new notes to one automatically.
+
+Examples:
+  flashback collection create homelab
+  flashback collection list
+  flashback collection add homelab 3C5uPKK4yvGZ3qUMJoCcdv
+  flashback collection remove homelab 3C5uPKK4yvGZ3qUMJoCcdv
+  flashback list --in homelab`,
+		Args: cobra.NoArgs,
+	}
+
+	collectionCmd.AddCommand(newCollectionCreateCmd(app))
+	collectionCmd.AddCommand(newCollectionListCmd(app))
+	collectionCmd.AddCommand(newCollectionAddCmd(app))
+	collectionCmd.AddCommand(newCollectionRemoveCmd(app))
+	collectionCmd.AddCommand(newCollectionDeleteCmd(app))
+
+	return collectionCmd
+}
+
+func newCollectionCreateCmd(app *app.App) *cobra.Command {
+	return &cobra.Command{
+		Use:   "create <name>",
+		Short: "Create an empty collection",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			if err := app.CreateCollection(ctx, args[0]); err != nil {
+				return withCode(exitStorage, "Error creating collection: %w", err)
+			}
+			printInfo(cmd, fmt.Sprintf("Created collection %s.", args[0]))
+			return nil
+		},
+	}
+}
+
+func newCollectionListCmd(app *app.App) *cobra.Command {
+	return &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List collections with the number of notes in them",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			collections, err := app.ListCollections(ctx)
+			if err != nil {
+				return withCode(exitStorage, "Error retrieving collections: %w", err)
+			}
+			if len(collections) == 0 {
+				printInfo(cmd, "No collections yet.")
+				return nil
+			}
+
+			nameWidth := len("Collection")
+			for _, c := range collections {
+				nameWidth = max(nameWidth, len(c.Name))
+			}
+			fmt.Printf("%-*s  %s\n\n", nameWidth, "Collection", "Notes")
+			for _, c := range collections {
+				fmt.Printf("%-*s  %d\n", nameWidth, c.Name, c.Count)
+			}
+			return nil
+		},
+	}
+}
+
+func newCollectionAddCmd(app *app.App) *cobra.Command {
+	return &cobra.Command{
+		Use:   "add <collection> <id>...",
+		Short: "Add notes to a collection, creating it if needed",
+		Args:  cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			name, ids := args[0], args[1:]
+			if err := app.AddToCollection(ctx, name, ids); err != nil {
+				return withCode(exitStorage, "Error adding notes to %s: %w", name, err)
+			}
+			printInfo(cmd, fmt.Sprintf("Added %d notes to %s.", len(ids), name))
+			return nil
+		},
+	}
+}
+
+func newCollectionRemoveCmd(app *app.App) *cobra.Command {
+	return &cobra.Command{
+		Use:     "remove <collection> <id>...",
+		Aliases: []string{"rm"},
+		Short:   "Take notes out of a collection without deleting them",
+		Args:    cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			name, ids := args[0], args[1:]
+			removed, err := app.RemoveFromCollection(ctx, name, ids)
+			if err != nil {
+				return withCode(exitStorage, "Error removing notes from %s: %w", name, err)
+			}
+			printInfo(cmd, fmt.Sprintf("Removed %d notes from %s.", removed, name))
+			return nil
+		},
+	}
+}
+
+func newCollectionDeleteCmd(app *app.App) *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete <collection>",
+		Short: "Delete a collection, keeping its notes",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			if err := app.DeleteCollection(ctx, args[0]); err != nil {
+				return withCode(exitStorage, "Error deleting collection %s: %w", args[0], err)
+			}
+			printInfo(cmd, fmt.Sprintf("Deleted collection %s.", args[0]))
+			return nil
+		},
+	}
+}
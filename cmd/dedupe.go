@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"charm.land/lipgloss/v2"
+	"github.com/spf13/cobra"
+	"github.com/yagnikpt/flashback/internal/app"
+	"github.com/yagnikpt/flashback/internal/models"
+	"github.com/yagnikpt/flashback/internal/utils"
+)
+
+func NewDedupeCmd(a *app.App) *cobra.Command {
+	dedupeCmd := &cobra.Command{
+		Use:   "dedupe",
+		Short: "Find and merge duplicate notes",
+		Long: `Find groups of notes that duplicate each other: the same URL (ignoring tracking parameters, fragments, trailing slashes, http/https and www), the same text, or embeddings that are nearly identical. Each group is merged into one note, which keeps the union of the tags and collections and the oldest creation time, and takes over the review schedule of a merged note when it has none. The note kept is the oldest one with generated metadata.
+
+You are asked before each group is merged. Use --yes to merge every group without asking, e.g. from a script, or --dry-run to only list them.
+
+Examples:
+  flashback dedupe --dry-run
+  flashback dedupe --exact --yes
+  flashback dedupe --threshold 0.98`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			threshold, _ := cmd.Flags().GetFloat64("threshold")
+			if threshold <= 0 || threshold > 1 {
+				return withCode(exitUsage, "--threshold must be between 0 and 1.")
+			}
+			exact, _ := cmd.Flags().GetBool("exact")
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			yes, _ := cmd.Flags().GetBool("yes")
+			if !dryRun && !yes && !utils.IsInteractive() {
+				return withCode(exitUsage, "dedupe asks before merging and needs a terminal; use --yes or --dry-run.")
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+
+			var clusters []app.DuplicateCluster
+			err := runWithProgress(ctx, cmd, func(ctx context.Context, report func(string)) error {
+				var err error
+				clusters, err = a.FindDuplicateClusters(ctx, threshold, !exact, report)
+				return err
+			})
+			if err != nil {
+				return withCode(exitStorage, "Error finding duplicates: %w", err)
+			}
+			if len(clusters) == 0 {
+				printInfo(cmd, "No duplicates found.")
+				return nil
+			}
+
+			merged, notes := 0, 0
+			for i, cluster := range clusters {
+				fmt.Printf("Group %d of %d:\n", i+1, len(clusters))
+				lipgloss.Print(formatDuplicateCluster(cluster))
+				if dryRun {
+					fmt.Println()
+					continue
+				}
+
+				if !yes {
+					answer, err := promptChoice("Merge? [y]es, [n]o, [a]ll or [q]uit (n)", "n")
+					if err != nil {
+						return withCode(exitUsage, "Error reading the answer: %w", err)
+					}
+					if answer == "q" {
+						break
+					}
+					if answer == "a" {
+						yes = true
+					} else if answer != "y" {
+						fmt.Println()
+						continue
+					}
+				}
+
+				ids := make([]string, len(cluster.Duplicates))
+				for j, d := range cluster.Duplicates {
+					ids[j] = d.Note.ID
+				}
+				if err := a.MergeNotes(ctx, cluster.Keep.ID, ids); err != nil {
+					return withCode(exitStorage, "Error merging notes into %s: %w", cluster.Keep.ID, err)
+				}
+				merged++
+				notes += len(ids)
+				fmt.Println()
+			}
+
+			if dryRun {
+				printInfo(cmd, fmt.Sprintf("Found %d groups of duplicates. Run without --dry-run to merge them.", len(clusters)))
+			} else {
+				printInfo(cmd, fmt.Sprintf("Merged %d notes in %d groups.", notes, merged))
+			}
+			return nil
+		},
+	}
+
+	dedupeCmd.Flags().Float64("threshold", app.DuplicateSimilarity, "Minimum embedding similarity (0-1) for notes to be duplicates")
+	dedupeCmd.Flags().Bool("exact", false, "Only find notes with the same URL or text")
+	dedupeCmd.Flags().Bool("dry-run", false, "List the duplicates without merging them")
+	dedupeCmd.Flags().BoolP("yes", "y", false, "Merge every group without asking")
+
+	return dedupeCmd
+}
+
+var labelStyles = lipgloss.NewStyle().Bold(true).Render
+
+// formatDuplicateCluster lists the note kept and the notes merged into it,
+// with how they match.
+func formatDuplicateCluster(cluster app.DuplicateCluster) string {
+	width, _ := utils.TerminalSize()
+	line := func(label string, note models.FlashbackWithMetadata, suffix string) string {
+		title := utils.NoteTitle(note)
+		maxWidth := max(width-len(label)-len(note.ID)-len(suffix)-6, 20)
+		if runes := []rune(title); len(runes) > maxWidth {
+			title = string(runes[:maxWidth-1]) + "…"
+		}
+		return fmt.Sprintf("  %s %s  %s%s\n", labelStyles(label), note.ID, title, suffix)
+	}
+
+	result := line("keep ", cluster.Keep, "")
+	for _, d := range cluster.Duplicates {
+		suffix := " (same URL or text)"
+		if !d.Exact {
+			suffix = fmt.Sprintf(" (%.0f%% similar)", d.Similarity*100)
+		}
+		result += line("merge", d.Note, suffix)
+	}
+	return result
+}
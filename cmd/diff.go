@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"charm.land/lipgloss/v2"
+	"github.com/spf13/cobra"
+	"github.com/yagnikpt/flashback/internal/app"
+	"github.com/yagnikpt/flashback/internal/utils"
+)
+
+func NewDiffCmd(a *app.App) *cobra.Command {
+	diffCmd := &cobra.Command{
+		Use:   "diff <id> [rev1] [rev2]",
+		Short: "Show how a note changed between revisions",
+		Long: `Show a unified diff of a note between two revisions, including the metadata and tags you own. Without revisions, the latest change is shown; with one, the changes from that revision to the latest.
+
+Examples:
+  flashback diff 3C5uPKK4yvGZ3qUMJoCcdv
+  flashback diff 3C5uPKK4yvGZ3qUMJoCcdv 2
+  flashback diff 3C5uPKK4yvGZ3qUMJoCcdv 1 3`,
+		Args: cobra.RangeArgs(1, 3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			id := args[0]
+			revisions, err := a.ListRevisions(ctx, id)
+			if err != nil {
+				return withCode(exitStorage, "Error retrieving revisions: %w", err)
+			}
+			if len(revisions) == 0 {
+				printInfo(cmd, "No revisions yet.")
+				return nil
+			}
+
+			latest := revisions[len(revisions)-1].Number
+			from, to := latest-1, latest
+			if len(args) > 1 {
+				if from, err = parseRevision(args[1]); err != nil {
+					return err
+				}
+			}
+			if len(args) > 2 {
+				if to, err = parseRevision(args[2]); err != nil {
+					return err
+				}
+			}
+			if from < 1 {
+				printInfo(cmd, "The note has a single revision.")
+				return nil
+			}
+
+			byNumber := make(map[int]app.Revision, len(revisions))
+			for _, r := range revisions {
+				byNumber[r.Number] = r
+			}
+			texts := make([]string, 2)
+			for i, n := range []int{from, to} {
+				r, ok := byNumber[n]
+				if !ok {
+					return withCode(exitNotFound, "Error: %s has no revision %d: %w", id, n, sql.ErrNoRows)
+				}
+				if texts[i], err = revisionText(r); err != nil {
+					return withCode(exitError, "Error rendering revision %d: %w", n, err)
+				}
+			}
+
+			diff := utils.FormatDiff(fmt.Sprintf("%s@%d", id, from), fmt.Sprintf("%s@%d", id, to), texts[0], texts[1])
+			if diff == "" {
+				printInfo(cmd, "No differences.")
+				return nil
+			}
+			lipgloss.Print(diff)
+			return nil
+		},
+	}
+
+	return diffCmd
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/yagnikpt/flashback/internal/app"
+	"github.com/yagnikpt/flashback/internal/utils"
+)
+
+func NewEditCmd(app *app.App) *cobra.Command {
+	editCmd := &cobra.Command{
+		Use:     "edit",
+		Aliases: []string{"e"},
+		Short:   "Edit a note in your $EDITOR",
+		Long: `Open a note in $EDITOR with its metadata as TOML front matter. The note keeps its ID and creation time, and its embedding is regenerated on save.
+
+Metadata you change or add is marked as yours and is kept when metadata is regenerated with --regenerate.
+
+Examples:
+  flashback edit 3C5uPKK4yvGZ3qUMJoCcdv
+  flashback edit --regenerate 3C5uPKK4yvGZ3qUMJoCcdv`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return withCode(exitUsage, "Please provide the ID of the note to edit.")
+			}
+			if !utils.IsInteractive() {
+				return withCode(exitUsage, "flashback edit needs a terminal to run $EDITOR.")
+			}
+			regenerate, _ := cmd.Flags().GetBool("regenerate")
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			note, err := app.GetNoteByID(ctx, args[0])
+			cancel()
+			if err != nil {
+				return withCode(exitStorage, "Error retrieving note: %w", err)
+			}
+
+			path, err := utils.WriteTempNoteFile(note.Content, note.Metadata, note.Tags)
+			if err != nil {
+				return withCode(exitError, "Error preparing note for editing: %w", err)
+			}
+			defer os.Remove(path)
+
+			editor := utils.EditorCommand(path)
+			editor.Stdin, editor.Stdout, editor.Stderr = os.Stdin, os.Stdout, os.Stderr
+			if err := editor.Run(); err != nil {
+				return withCode(exitError, "Error running editor: %w", err)
+			}
+
+			content, metadata, tags, err := utils.ReadNoteFile(path)
+			if err != nil {
+				return withCode(exitUsage, "Error reading edited note: %w", err)
+			}
+
+			ctx, cancel = context.WithTimeout(context.Background(), 15*time.Second)
+			defer cancel()
+			err = runWithProgress(ctx, cmd, func(ctx context.Context, report func(string)) error {
+				report("Saving the note...")
+				return app.ApplyNoteEdit(ctx, note, content, metadata, tags, regenerate)
+			})
+			if err != nil {
+				return withCode(exitProvider, "Error updating note: %w", err)
+			}
+			printInfo(cmd, "Note updated successfully.")
+			return nil
+		},
+	}
+
+	editCmd.Flags().BoolP("regenerate", "r", false, "Regenerate metadata that you haven't edited yourself")
+
+	return editCmd
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// Exit codes returned by flashback, so scripts can tell failures apart.
+const (
+	exitOK          = 0
+	exitError       = 1 // unexpected failure
+	exitUsage       = 2 // bad arguments or flags, or no terminal for an interactive command
+	exitNotFound    = 3 // the requested note or tag doesn't exist
+	exitProvider    = 4 // the AI provider failed
+	exitNetwork     = 5 // fetching a web page failed
+	exitStorage     = 6 // reading or writing the database failed
+	exitInterrupted = 130
+)
+
+type codedError struct {
+	code int
+	err  error
+}
+
+func (e *codedError) Error() string { return e.err.Error() }
+func (e *codedError) Unwrap() error { return e.err }
+
+// withCode formats an error like fmt.Errorf and tags it with an exit code.
+func withCode(code int, format string, args ...any) error {
+	return &codedError{code: code, err: fmt.Errorf(format, args...)}
+}
+
+func exitCode(err error) int {
+	if err == nil {
+		return exitOK
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return exitNotFound
+	}
+	if errors.Is(err, context.Canceled) {
+		return exitInterrupted
+	}
+	var e *codedError
+	if errors.As(err, &e) {
+		return e.code
+	}
+	// Errors about the command line are tagged by usageErrors, so anything
+	// left is a failure nothing expected.
+	return exitError
+}
+
+// usageErrors tags the errors of the argument checks of cmd and its
+// subcommands with exitUsage. Commands without a check get cobra's default,
+// which rejects unknown subcommands, made explicit so it is tagged too.
+// Commands that only group others show their help, so that the check runs.
+func usageErrors(cmd *cobra.Command) {
+	check := cmd.Args
+	if check == nil {
+		check = cobra.ArbitraryArgs
+		if cmd.HasSubCommands() {
+			check = cobra.NoArgs
+		}
+	}
+	if !cmd.Runnable() && cmd.HasSubCommands() {
+		cmd.RunE = func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		}
+	}
+	cmd.Args = func(cmd *cobra.Command, args []string) error {
+		if err := check(cmd, args); err != nil {
+			return withCode(exitUsage, "Error: %w\nRun '%s --help' for usage.", err, cmd.CommandPath())
+		}
+		return nil
+	}
+	for _, sub := range cmd.Commands() {
+		usageErrors(sub)
+	}
+}
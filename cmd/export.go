@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/yagnikpt/flashback/internal/app"
+	"github.com/yagnikpt/flashback/internal/utils"
+)
+
+func NewExportCmd(app *app.App) *cobra.Command {
+	exportCmd := &cobra.Command{
+		Use:   "export [path]",
+		Short: "Export notes to JSON, a Markdown vault or HTML bookmarks",
+		Long: `Export notes from the flashback database.
+
+Formats:
+  json       a versioned dump with metadata and tag sources, for backups and import
+  markdown   a directory with one Markdown file per note, for Obsidian or Logseq
+  bookmarks  Netscape bookmark HTML with the URL notes, for browsers
+
+json and bookmarks are written to path, or to stdout without one. markdown needs a directory.
+
+Examples:
+  flashback export > backup.json
+  flashback export --embeddings backup.json
+  flashback export --format markdown ~/vault/flashback
+  flashback export --format bookmarks --tag reading bookmarks.html`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			formatFlag, _ := cmd.Flags().GetString("format")
+			format, err := utils.ParseExportFormat(formatFlag)
+			if err != nil {
+				return withCode(exitUsage, "Error: %w", err)
+			}
+			filter, err := filterFromFlags(cmd)
+			if err != nil {
+				return withCode(exitUsage, "Error: %w", err)
+			}
+			if format == utils.ExportMarkdown && len(args) == 0 {
+				return withCode(exitUsage, "Please provide the directory to export the Markdown files to.")
+			}
+			withEmbeddings, _ := cmd.Flags().GetBool("embeddings")
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+			defer cancel()
+
+			notes, err := app.ExportNotes(ctx, filter, withEmbeddings && format == utils.ExportJSON)
+			if err != nil {
+				return withCode(exitStorage, "Error retrieving notes: %w", err)
+			}
+
+			if format == utils.ExportMarkdown {
+				if err := utils.WriteMarkdownVault(args[0], notes); err != nil {
+					return withCode(exitError, "Error writing Markdown files: %w", err)
+				}
+				printInfo(cmd, fmt.Sprintf("Exported %d notes to %s.", len(notes), args[0]))
+				return nil
+			}
+
+			var w io.Writer = os.Stdout
+			if len(args) == 1 {
+				f, err := os.Create(args[0])
+				if err != nil {
+					return withCode(exitError, "Error creating export file: %w", err)
+				}
+				defer f.Close()
+				w = f
+			}
+
+			if format == utils.ExportBookmarks {
+				err = utils.WriteBookmarksHTML(w, notes)
+			} else {
+				err = utils.WriteExportJSON(w, notes)
+			}
+			if err != nil {
+				return withCode(exitError, "Error writing export: %w", err)
+			}
+			if len(args) == 1 {
+				printInfo(cmd, fmt.Sprintf("Exported %d notes to %s.", len(notes), args[0]))
+			}
+			return nil
+		},
+	}
+
+	exportCmd.Flags().StringP("format", "f", string(utils.ExportJSON), "Export format: json, markdown or bookmarks")
+	exportCmd.Flags().Bool("embeddings", false, "Include embeddings in the JSON dump")
+	addFilterFlags(exportCmd)
+
+	return exportCmd
+}
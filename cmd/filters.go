@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/yagnikpt/flashback/internal/app"
+)
+
+func addFilterFlags(cmd *cobra.Command) {
+	cmd.Flags().StringSlice("type", nil, "Only include notes of this type: url, text or command")
+	cmd.Flags().StringSlice("tag", nil, "Only include notes with this tag (repeatable)")
+	cmd.Flags().String("in", "", "Only include notes in this collection")
+	cmd.Flags().String("since", "", "Only include notes created after this date (YYYY-MM-DD or relative like 7d)")
+	cmd.Flags().String("until", "", "Only include notes created up to this date, inclusive (YYYY-MM-DD or relative like 7d)")
+}
+
+// applyFilterFlags adds the filter flags set on cmd to filter.
+func applyFilterFlags(cmd *cobra.Command, filter *app.NoteFilter) error {
+	now := time.Now()
+
+	types, _ := cmd.Flags().GetStringSlice("type")
+	for _, value := range types {
+		t, err := app.ParseNoteType(value)
+		if err != nil {
+			return err
+		}
+		filter.Types = append(filter.Types, t)
+	}
+
+	tags, _ := cmd.Flags().GetStringSlice("tag")
+	filter.Tags = append(filter.Tags, tags...)
+
+	if collection, _ := cmd.Flags().GetString("in"); collection != "" {
+		filter.Collection = collection
+	}
+
+	if since, _ := cmd.Flags().GetString("since"); since != "" {
+		t, err := app.ParseTime(since, now)
+		if err != nil {
+			return err
+		}
+		filter.Since = t
+	}
+	if until, _ := cmd.Flags().GetString("until"); until != "" {
+		t, err := app.ParseUntil(until, now)
+		if err != nil {
+			return err
+		}
+		filter.Until = t
+	}
+
+	return nil
+}
+
+// filterFromFlags builds a filter from the filter flags set on cmd.
+func filterFromFlags(cmd *cobra.Command) (app.NoteFilter, error) {
+	var filter app.NoteFilter
+	err := applyFilterFlags(cmd, &filter)
+	return filter, err
+}
@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+	"github.com/yagnikpt/flashback/internal/app"
+	"github.com/yagnikpt/flashback/internal/utils"
+)
+
+func NewHistoryCmd(app *app.App) *cobra.Command {
+	historyCmd := &cobra.Command{
+		Use:   "history <id>",
+		Short: "List the revisions of a note",
+		Long: `List every revision of a note, newest first, with what changed in each. A revision is kept whenever the content of a note or the metadata and tags you own change; generated metadata isn't part of revisions.
+
+Examples:
+  flashback history 3C5uPKK4yvGZ3qUMJoCcdv
+  flashback diff 3C5uPKK4yvGZ3qUMJoCcdv 1
+  flashback restore 3C5uPKK4yvGZ3qUMJoCcdv 1`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			output, _ := cmd.Flags().GetString("output")
+			if output != "text" && output != "json" {
+				return withCode(exitUsage, "Error: invalid output format %q (expected text or json)", output)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			revisions, err := app.ListRevisions(ctx, args[0])
+			if err != nil {
+				return withCode(exitStorage, "Error retrieving revisions: %w", err)
+			}
+			if output == "json" {
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(revisions)
+			}
+			if len(revisions) == 0 {
+				printInfo(cmd, "No revisions yet.")
+				return nil
+			}
+
+			rows := historyRows(revisions)
+			savedWidth := len("Saved")
+			for _, row := range rows {
+				savedWidth = max(savedWidth, len(row[1]))
+			}
+			fmt.Printf("%-4s  %-*s  %s\n\n", "Rev", savedWidth, "Saved", "Changes")
+			for _, row := range rows {
+				fmt.Printf("%-4s  %-*s  %s\n", row[0], savedWidth, row[1], row[2])
+			}
+			return nil
+		},
+	}
+
+	historyCmd.Flags().StringP("output", "o", "text", "Output format: text or json")
+
+	return historyCmd
+}
+
+// historyRows describes revisions newest first, with when each was saved
+// and what it changed.
+func historyRows(revisions []app.Revision) [][3]string {
+	rows := make([][3]string, 0, len(revisions))
+	for i := len(revisions) - 1; i >= 0; i-- {
+		saved := revisions[i].CreatedAt
+		if t, err := utils.ParseTimestamp(saved); err == nil {
+			saved = humanize.Time(t)
+		}
+		changes := "created"
+		if i > 0 {
+			changes = revisionChanges(revisions[i-1], revisions[i])
+		}
+		rows = append(rows, [3]string{strconv.Itoa(revisions[i].Number), saved, changes})
+	}
+	return rows
+}
+
+// revisionChanges lists the parts of a note that differ between two
+// revisions.
+func revisionChanges(old, new app.Revision) string {
+	var changed []string
+	if old.Content != new.Content {
+		changed = append(changed, "content")
+	}
+	if !maps.Equal(old.Metadata, new.Metadata) {
+		changed = append(changed, "metadata")
+	}
+	if !slices.Equal(old.Tags, new.Tags) {
+		changed = append(changed, "tags")
+	}
+	if len(changed) == 0 {
+		return "no changes"
+	}
+	return strings.Join(changed, ", ")
+}
+
+// revisionText is the text of a revision that diffs compare.
+func revisionText(r app.Revision) (string, error) {
+	return utils.RenderRevision(r.Content, r.Metadata, r.Tags)
+}
+
+// parseRevision reads a revision number argument.
+func parseRevision(value string) (int, error) {
+	n, err := strconv.Atoi(strings.TrimPrefix(value, "r"))
+	if err != nil || n < 1 {
+		return 0, withCode(exitUsage, "Error: invalid revision %q (expected a number from flashback history)", value)
+	}
+	return n, nil
+}
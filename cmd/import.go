@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/yagnikpt/flashback/internal/app"
+	"github.com/yagnikpt/flashback/internal/importer"
+)
+
+func NewImportCmd(app *app.App) *cobra.Command {
+	importCmd := &cobra.Command{
+		Use:   "import <file|dir>",
+		Short: "Import notes from exports, Markdown folders and bookmarks",
+		Long: `Import notes from a flashback JSON export, a folder of Markdown files (Obsidian, Logseq or flashback export), browser bookmark HTML, or a Pocket/Raindrop CSV export.
+
+The format is detected from the file name and contents. Notes whose content already exists are skipped, and original creation times are kept. Like notes added with flashback add, imported notes are queued for the background worker to generate their metadata and embeddings, unless --no-ai is set.
+
+Progress is saved after every note. If an import is interrupted, run the same command again to continue where it stopped.
+
+Examples:
+  flashback import backup.json
+  flashback import ~/vault/notes
+  flashback import --no-ai bookmarks.html
+  flashback import --format csv pocket-export.txt`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			source, err := filepath.Abs(args[0])
+			if err != nil {
+				return withCode(exitUsage, "Error: %w", err)
+			}
+
+			format, _ := cmd.Flags().GetString("format")
+			var f importer.Format
+			if format == "" {
+				f, err = importer.Detect(source)
+			} else {
+				f, err = importer.ParseFormat(format)
+			}
+			if err != nil {
+				return withCode(exitUsage, "Error: %w", err)
+			}
+
+			notes, err := importer.Load(source, f)
+			if err != nil {
+				return withCode(exitError, "Error reading %s: %w", args[0], err)
+			}
+			noAI, _ := cmd.Flags().GetBool("no-ai")
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+
+			var progress importer.Progress
+			err = runWithProgress(ctx, cmd, func(ctx context.Context, report func(string)) error {
+				report(fmt.Sprintf("Importing %d notes from %s...", len(notes), args[0]))
+				progress.ImportProgress, err = importer.Run(ctx, app, source, notes, importer.Options{NoAI: noAI}, func(p importer.Progress) {
+					status := fmt.Sprintf("Imported %d/%d notes (%d skipped, %d failed)", p.Position, p.Total, p.Skipped, p.Failed)
+					if p.Resumed {
+						status += ", resumed"
+					}
+					report(status)
+				})
+				return err
+			})
+			if errors.Is(err, context.Canceled) {
+				return withCode(exitInterrupted, "Import interrupted after %d of %d notes. Run the same command again to continue.", progress.Position, progress.Total)
+			}
+			if err != nil {
+				return withCode(exitStorage, "Error importing notes: %w", err)
+			}
+
+			printInfo(cmd, fmt.Sprintf("Imported %d notes, skipped %d duplicates, %d failed.", progress.Imported, progress.Skipped, progress.Failed))
+			if !noAI && progress.Imported > 0 {
+				printInfo(cmd, "Metadata and embeddings are generated in the background; run flashback worker if the TUI isn't open.")
+			}
+			if progress.Failed > 0 {
+				return withCode(exitError, "Some notes could not be imported, see the debug log for details.")
+			}
+			return nil
+		},
+	}
+
+	importCmd.Flags().StringP("format", "f", "", "Input format: json, markdown, bookmarks or csv (detected when omitted)")
+	importCmd.Flags().Bool("no-ai", false, "Store notes as they are, without generating metadata or embeddings")
+
+	return importCmd
+}
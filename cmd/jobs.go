@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/yagnikpt/flashback/internal/app"
+)
+
+func NewJobsCmd(a *app.App) *cobra.Command {
+	jobsCmd := &cobra.Command{
+		Use:   "jobs",
+		Short: "List pending and failed background jobs",
+		Long: `List the background jobs that are waiting, running or failed. Jobs are removed once they succeed.
+
+Examples:
+  flashback jobs
+  flashback jobs --failed
+  flashback jobs retry
+  flashback jobs retry 12 13`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			var statuses []string
+			if failed, _ := cmd.Flags().GetBool("failed"); failed {
+				statuses = append(statuses, app.JobFailed)
+			}
+			jobs, err := a.ListJobs(ctx, statuses...)
+			if err != nil {
+				return withCode(exitStorage, "Error retrieving jobs: %w", err)
+			}
+			if len(jobs) == 0 {
+				printInfo(cmd, "No jobs.")
+				return nil
+			}
+
+			fmt.Printf("%-6s %-8s %-8s %-8s %s\n\n", "ID", "Kind", "Status", "Attempts", "Note")
+			for _, job := range jobs {
+				content := strings.Join(strings.Fields(job.Content), " ")
+				if runes := []rune(content); len(runes) > 50 {
+					content = string(runes[:47]) + "..."
+				}
+				fmt.Printf("%-6d %-8s %-8s %-8d %s %s\n", job.ID, job.Kind, job.Status, job.Attempts, job.FlashbackID, content)
+				if job.LastError != "" {
+					fmt.Printf("       last error: %s\n", job.LastError)
+				}
+			}
+			return nil
+		},
+	}
+
+	jobsCmd.Flags().Bool("failed", false, "Only list failed jobs")
+	jobsCmd.AddCommand(newJobsRetryCmd(a))
+
+	return jobsCmd
+}
+
+func newJobsRetryCmd(app *app.App) *cobra.Command {
+	return &cobra.Command{
+		Use:   "retry [id...]",
+		Short: "Run failed jobs again, or all waiting jobs right away",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ids := make([]int64, 0, len(args))
+			for _, arg := range args {
+				id, err := strconv.ParseInt(arg, 10, 64)
+				if err != nil {
+					return withCode(exitUsage, "Invalid job ID %q.", arg)
+				}
+				ids = append(ids, id)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			n, err := app.RetryJobs(ctx, ids...)
+			if err != nil {
+				return withCode(exitStorage, "Error retrying jobs: %w", err)
+			}
+			printInfo(cmd, fmt.Sprintf("%d jobs will run again with the next flashback worker.", n))
+			return nil
+		},
+	}
+}
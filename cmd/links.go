@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"charm.land/lipgloss/v2"
+	"github.com/spf13/cobra"
+	"github.com/yagnikpt/flashback/internal/app"
+	"github.com/yagnikpt/flashback/internal/utils"
+)
+
+func NewLinksCmd(app *app.App) *cobra.Command {
+	linksCmd := &cobra.Command{
+		Use:     "links <id>",
+		Aliases: []string{"link"},
+		Short:   "List and manage the links between notes",
+		Long: `List the notes linked to a note, or link and unlink notes. A link shows on both notes.
+
+Examples:
+  flashback links 3C5uPKK4yvGZ3qUMJoCcdv
+  flashback links add 3C5uPKK4yvGZ3qUMJoCcdv 9dKx2VfPq7sLmWnB4tRzYe
+  flashback links remove 3C5uPKK4yvGZ3qUMJoCcdv 9dKx2VfPq7sLmWnB4tRzYe`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, err := outputFormatFlag(cmd)
+			if err != nil {
+				return withCode(exitUsage, "Error: %w", err)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			id := args[0]
+			if _, err := app.GetNoteByID(ctx, id); err != nil {
+				return withCode(exitStorage, "Error retrieving note: %w", err)
+			}
+			links, err := app.GetLinkedNotes(ctx, id)
+			if err != nil {
+				return withCode(exitStorage, "Error retrieving linked notes: %w", err)
+			}
+			if format != utils.OutputTable {
+				if err := utils.WriteNotes(os.Stdout, format, unscored(links)); err != nil {
+					return withCode(exitError, "Error writing notes: %w", err)
+				}
+				return nil
+			}
+			if len(links) == 0 {
+				printInfo(cmd, "No linked notes.")
+				return nil
+			}
+			lipgloss.Print(utils.FormatRelatedNotes(links, nil))
+			return nil
+		},
+	}
+
+	addOutputFlag(linksCmd)
+	linksCmd.AddCommand(newLinksAddCmd(app))
+	linksCmd.AddCommand(newLinksRemoveCmd(app))
+
+	return linksCmd
+}
+
+func newLinksAddCmd(a *app.App) *cobra.Command {
+	return &cobra.Command{
+		Use:   "add <id> <other-id>...",
+		Short: "Link a note to one or more other notes",
+		Args:  cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			for _, other := range args[1:] {
+				err := a.LinkNotes(ctx, args[0], other)
+				if errors.Is(err, app.ErrSelfLink) {
+					return withCode(exitUsage, "Error: %w", err)
+				}
+				if err != nil {
+					return withCode(exitStorage, "Error linking %s to %s: %w", args[0], other, err)
+				}
+				printInfo(cmd, fmt.Sprintf("Linked %s and %s.", args[0], other))
+			}
+			return nil
+		},
+	}
+}
+
+func newLinksRemoveCmd(app *app.App) *cobra.Command {
+	return &cobra.Command{
+		Use:     "remove <id> <other-id>...",
+		Aliases: []string{"rm", "delete"},
+		Short:   "Remove the links between a note and other notes",
+		Args:    cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			for _, other := range args[1:] {
+				err := app.UnlinkNotes(ctx, args[0], other)
+				if err != nil {
+					return withCode(exitStorage, "Error unlinking %s from %s: %w", args[0], other, err)
+				}
+				printInfo(cmd, fmt.Sprintf("Unlinked %s and %s.", args[0], other))
+			}
+			return nil
+		},
+	}
+}
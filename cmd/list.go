@@ -2,34 +2,61 @@ package cmd
 
 import (
 	"context"
-	"fmt"
+	"os"
 	"time"
 
+	"charm.land/lipgloss/v2"
 	"github.com/spf13/cobra"
 	"github.com/yagnikpt/flashback/internal/app"
+	"github.com/yagnikpt/flashback/internal/models"
 	"github.com/yagnikpt/flashback/internal/utils"
 )
 
-func NewListCmd(app *app.App) *cobra.Command {
+func NewListCmd(a *app.App) *cobra.Command {
 	listCmd := &cobra.Command{
 		Use:     "list",
 		Aliases: []string{"ls"},
 		Short:   "List all stored notes",
 		Long: `List all notes stored in the flashback database.
-This command displays a compact list of all notes with their IDs and Content.`,
-		Run: func(cmd *cobra.Command, args []string) {
+This command displays a compact list of all notes with their IDs and Content.
+
+Examples:
+  flashback list
+  flashback list --output json
+  flashback list --in homelab`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, err := outputFormatFlag(cmd)
+			if err != nil {
+				return withCode(exitUsage, "Error: %w", err)
+			}
+
 			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 			defer cancel()
 
-			flashbacks, err := app.GetAllNotes(ctx)
+			var flashbacks []models.FlashbackWithMetadata
+			if collection, _ := cmd.Flags().GetString("in"); collection != "" {
+				flashbacks, err = a.ListNotes(ctx, app.NoteFilter{Collection: collection})
+			} else {
+				flashbacks, err = a.GetAllNotes(ctx)
+			}
 			if err != nil {
-				fmt.Println("Error retrieving notes:", err)
-				return
+				return withCode(exitStorage, "Error retrieving notes: %w", err)
+			}
+			if format != utils.OutputTable {
+				err := utils.WriteNotes(os.Stdout, format, unscored(flashbacks))
+				if err != nil {
+					return withCode(exitError, "Error writing notes: %w", err)
+				}
+				return nil
 			}
 			output := utils.FormatMultipleNotesCompact(flashbacks)
-			fmt.Println(output)
+			lipgloss.Println(output)
+			return nil
 		},
 	}
 
+	listCmd.Flags().String("in", "", "Only list notes in this collection")
+	addOutputFlag(listCmd)
+
 	return listCmd
 }
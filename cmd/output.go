@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/yagnikpt/flashback/internal/app"
+	"github.com/yagnikpt/flashback/internal/models"
+	"github.com/yagnikpt/flashback/internal/utils"
+)
+
+func addOutputFlag(cmd *cobra.Command) {
+	cmd.Flags().StringP("output", "o", string(utils.OutputTable), "Output format: table, json, ndjson, yaml, csv or markdown")
+}
+
+func outputFormatFlag(cmd *cobra.Command) (utils.OutputFormat, error) {
+	value, _ := cmd.Flags().GetString("output")
+	return utils.ParseOutputFormat(value)
+}
+
+func unscored(notes []models.FlashbackWithMetadata) []models.ScoredFlashback {
+	scored := make([]models.ScoredFlashback, len(notes))
+	for i, note := range notes {
+		scored[i] = models.ScoredFlashback{FlashbackWithMetadata: note}
+	}
+	return scored
+}
+
+func scoredResults(result app.SearchResult) []models.ScoredFlashback {
+	scored := unscored(result.Notes)
+	for i := range scored {
+		if score, ok := result.Scores[scored[i].ID]; ok {
+			scored[i].Score = &score
+		}
+		if similarity, ok := result.Similarities[scored[i].ID]; ok {
+			scored[i].Similarity = &similarity
+		}
+		scored[i].Snippet = result.Snippets[scored[i].ID]
+	}
+	return scored
+}
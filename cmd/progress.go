@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/yagnikpt/flashback/internal/components/spinner"
+	"github.com/yagnikpt/flashback/internal/utils"
+)
+
+func isQuiet(cmd *cobra.Command) bool {
+	quiet, _ := cmd.Flags().GetBool("quiet")
+	return quiet
+}
+
+// printInfo prints an informational message to stdout unless --quiet is set.
+func printInfo(cmd *cobra.Command, a ...any) {
+	if !isQuiet(cmd) {
+		fmt.Println(a...)
+	}
+}
+
+// runWithProgress runs work and shows the status lines it reports: with a
+// spinner when stderr is a terminal, as plain lines on stderr otherwise, and
+// not at all with --quiet. Quitting the spinner cancels work.
+func runWithProgress(ctx context.Context, cmd *cobra.Command, work func(ctx context.Context, report func(string)) error) error {
+	if isQuiet(cmd) {
+		return work(ctx, func(string) {})
+	}
+	if !utils.IsTerminal(os.Stderr) {
+		return work(ctx, func(status string) {
+			fmt.Fprintln(os.Stderr, status)
+		})
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	statusChan := make(chan string)
+	done := make(chan error, 1)
+	go func() {
+		done <- work(ctx, func(status string) {
+			select {
+			case statusChan <- status:
+			case <-ctx.Done():
+			}
+		})
+		close(statusChan)
+	}()
+
+	spinner.Run(statusChan, false)
+	cancel()
+	err := <-done
+	fmt.Fprint(os.Stderr, "\033[A\033[2K")
+	return err
+}
@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+var stdin = bufio.NewReader(os.Stdin)
+
+// promptChoice asks question on stderr and returns the first letter of the
+// answer read from stdin, in lower case, or def when the answer is empty.
+func promptChoice(question, def string) (string, error) {
+	fmt.Fprintf(os.Stderr, "%s ", question)
+	answer, err := stdin.ReadString('\n')
+	if err != nil && answer == "" {
+		return "", err
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	if answer == "" {
+		return def, nil
+	}
+	return answer[:1], nil
+}
@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+	"github.com/yagnikpt/flashback/internal/app"
+	"github.com/yagnikpt/flashback/internal/ingest"
+	"github.com/yagnikpt/flashback/internal/utils"
+	"github.com/yagnikpt/flashback/internal/worker"
+)
+
+func NewReadCmd(app *app.App) *cobra.Command {
+	readCmd := &cobra.Command{
+		Use:   "read <id>",
+		Short: "Read the saved copy of a URL note's page",
+		Long: `Show the page behind a URL note as it was saved, in a pager, so it can be read offline or after it has disappeared.
+
+With --refresh the page is fetched again and saved as a new version if it changed; earlier versions are kept and can be read with --version.
+
+Examples:
+  flashback read 3C5uPKK4yvGZ3qUMJoCcdv
+  flashback read --refresh 3C5uPKK4yvGZ3qUMJoCcdv
+  flashback read --list 3C5uPKK4yvGZ3qUMJoCcdv
+  flashback read --version 1 3C5uPKK4yvGZ3qUMJoCcdv`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return withCode(exitUsage, "Please provide the ID of the note to read.")
+			}
+			id := args[0]
+			refresh, _ := cmd.Flags().GetBool("refresh")
+			version, _ := cmd.Flags().GetInt("version")
+			list, _ := cmd.Flags().GetBool("list")
+			html, _ := cmd.Flags().GetBool("html")
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+
+			note, err := app.GetNoteByID(ctx, id)
+			if err != nil {
+				return withCode(exitStorage, "Error retrieving note: %w", err)
+			}
+
+			if refresh {
+				if note.Type != "url" {
+					return withCode(exitUsage, "Note %s is not a URL, it has no page to refresh.", id)
+				}
+				err := runWithProgress(ctx, cmd, func(ctx context.Context, report func(string)) error {
+					ctx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+					defer cancel()
+					return worker.RefreshPage(ctx, app, id, report)
+				})
+				if err := refreshError(err); err != nil {
+					return err
+				}
+			}
+
+			if list {
+				snapshots, err := app.ListSnapshots(ctx, id)
+				if err != nil {
+					return withCode(exitStorage, "Error retrieving saved pages: %w", err)
+				}
+				if len(snapshots) == 0 {
+					printInfo(cmd, "No saved pages.")
+					return nil
+				}
+				fmt.Printf("%-8s %s\n\n", "Version", "Saved")
+				for _, s := range snapshots {
+					fmt.Printf("%-8d %s\n", s.Version, s.CreatedAt)
+				}
+				return nil
+			}
+
+			snapshot, err := app.GetSnapshot(ctx, id, version)
+			if errors.Is(err, sql.ErrNoRows) {
+				if version != 0 {
+					return withCode(exitNotFound, "Note %s has no saved page version %d.", id, version)
+				}
+				if note.Type == "url" {
+					return withCode(exitNotFound, "Note %s has no saved page yet. Run flashback read --refresh %s to save it.", id, id)
+				}
+				return withCode(exitNotFound, "Note %s is not a URL and has no saved page.", id)
+			}
+			if err != nil {
+				return withCode(exitStorage, "Error retrieving saved page: %w", err)
+			}
+
+			if html {
+				if snapshot.HTML == "" {
+					return withCode(exitNotFound, "The HTML of this page wasn't kept. Set snapshot_html = true in the config to keep it for new snapshots.")
+				}
+				_, err := os.Stdout.WriteString(snapshot.HTML)
+				return err
+			}
+
+			var b strings.Builder
+			title := note.Metadata["title"]
+			if title == "" {
+				title = note.Metadata["tldr"]
+			}
+			if title != "" {
+				fmt.Fprintf(&b, "# %s\n\n", title)
+			}
+			fmt.Fprintf(&b, "<%s>\n", note.Content)
+			if t, err := utils.ParseTimestamp(snapshot.CreatedAt); err == nil {
+				fmt.Fprintf(&b, "Saved %s", humanize.Time(t))
+			} else {
+				fmt.Fprintf(&b, "Saved %s", snapshot.CreatedAt)
+			}
+			fmt.Fprintf(&b, " (version %d)\n\n---\n\n%s\n", snapshot.Version, strings.TrimSpace(snapshot.Markdown))
+			return utils.ShowInPager(b.String())
+		},
+	}
+
+	readCmd.Flags().Bool("refresh", false, "Fetch the page again and save it if it changed")
+	readCmd.Flags().Int("version", 0, "Read an earlier version of the page (see --list)")
+	readCmd.Flags().Bool("list", false, "List the saved versions of the page")
+	readCmd.Flags().Bool("html", false, "Print the raw HTML of the page, if it was kept")
+
+	return readCmd
+}
+
+// refreshError maps a failed page refresh to an exit code. Failing to embed
+// the page's passages isn't fatal, as the page was saved first.
+func refreshError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, context.Canceled) {
+		return withCode(exitInterrupted, "Interrupted.")
+	}
+	var stageErr *ingest.Error
+	if errors.As(err, &stageErr) {
+		switch stageErr.Stage {
+		case ingest.StageLoad:
+			return withCode(exitNetwork, "Error refreshing page: %w", err)
+		case ingest.StageEmbed:
+			fmt.Fprintln(os.Stderr, "The page was saved, but its passages couldn't be embedded:", err)
+			return nil
+		}
+	}
+	return withCode(exitStorage, "Error refreshing page: %w", err)
+}
@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/yagnikpt/flashback/internal/app"
+	"github.com/yagnikpt/flashback/internal/worker"
+)
+
+// reindexNoteTimeout bounds the work on a single note, so a provider that
+// stops responding doesn't stall the whole run.
+const reindexNoteTimeout = time.Minute
+
+func NewReindexCmd(a *app.App) *cobra.Command {
+	reindexCmd := &cobra.Command{
+		Use:   "reindex",
+		Short: "Regenerate outdated metadata and embeddings",
+		Long: `Regenerate the metadata and embeddings of notes that were produced by another model or an older version of the prompts, so they can be compared with new notes and queries.
+
+Flashback records the model and prompt version behind every generated value, and skips notes that are already current unless --force is given. Without --metadata or --embeddings both are checked. Regenerating metadata also regenerates the embedding. Metadata you edited or imported is kept.
+
+Examples:
+  flashback reindex --dry-run
+  flashback reindex --embeddings --concurrency 4
+  flashback reindex --metadata --tag k8s --since 30d
+  flashback reindex --force --type url`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			filter, err := filterFromFlags(cmd)
+			if err != nil {
+				return withCode(exitUsage, "%w", err)
+			}
+			opts := reindexOptionsFromFlags(cmd)
+			concurrency, _ := cmd.Flags().GetInt("concurrency")
+			if concurrency < 1 {
+				return withCode(exitUsage, "--concurrency must be at least 1.")
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+
+			listCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+			candidates, err := a.NotesNeedingReindex(listCtx, filter, opts)
+			cancel()
+			if err != nil {
+				return withCode(exitStorage, "Error finding notes to reindex: %w", err)
+			}
+			if len(candidates) == 0 {
+				printInfo(cmd, "Everything is up to date.")
+				return nil
+			}
+
+			if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+				for _, c := range candidates {
+					var parts []string
+					if c.NeedsMetadata {
+						parts = append(parts, "metadata")
+					}
+					if c.NeedsEmbedding {
+						parts = append(parts, "embedding")
+					}
+					content := strings.Join(strings.Fields(c.Content), " ")
+					if runes := []rune(content); len(runes) > 50 {
+						content = string(runes[:47]) + "..."
+					}
+					fmt.Printf("%s %-18s %s\n", c.ID, strings.Join(parts, "+"), content)
+				}
+				printInfo(cmd, fmt.Sprintf("\n%d notes would be reindexed.", len(candidates)))
+				return nil
+			}
+
+			// Failures are printed once the progress line is gone, so the
+			// spinner doesn't draw over them.
+			var done atomic.Int32
+			var mu sync.Mutex
+			var failures []string
+			err = runWithProgress(ctx, cmd, func(ctx context.Context, report func(string)) error {
+				queue := make(chan app.ReindexCandidate)
+				var wg sync.WaitGroup
+				for range concurrency {
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						for c := range queue {
+							noteCtx, cancel := context.WithTimeout(ctx, reindexNoteTimeout)
+							err := worker.Enrich(noteCtx, a, c.ID, c.NeedsMetadata, func(string) {})
+							cancel()
+							if err != nil {
+								if ctx.Err() != nil {
+									return
+								}
+								mu.Lock()
+								failures = append(failures, fmt.Sprintf("Error reindexing %s: %v", c.ID, err))
+								mu.Unlock()
+							}
+							report(fmt.Sprintf("Reindexed %d/%d notes...", done.Add(1), len(candidates)))
+						}
+					}()
+				}
+
+			feed:
+				for _, c := range candidates {
+					select {
+					case queue <- c:
+					case <-ctx.Done():
+						break feed
+					}
+				}
+				close(queue)
+				wg.Wait()
+				return ctx.Err()
+			})
+			for _, failure := range failures {
+				fmt.Fprintln(os.Stderr, failure)
+			}
+			if errors.Is(err, context.Canceled) {
+				return withCode(exitInterrupted, "Interrupted after %d of %d notes. Run flashback reindex again to continue.", done.Load(), len(candidates))
+			}
+
+			printInfo(cmd, fmt.Sprintf("Reindexed %d notes.", int(done.Load())-len(failures)))
+			if len(failures) > 0 {
+				return withCode(exitProvider, "%d notes could not be reindexed.", len(failures))
+			}
+			return nil
+		},
+	}
+
+	reindexCmd.Flags().Bool("metadata", false, "Regenerate metadata and tags (and the embedding built from them)")
+	reindexCmd.Flags().Bool("embeddings", false, "Regenerate embeddings")
+	reindexCmd.Flags().Bool("force", false, "Include notes that are already current")
+	reindexCmd.Flags().Bool("dry-run", false, "List the notes that would be reindexed")
+	reindexCmd.Flags().IntP("concurrency", "c", 2, "Number of notes processed at the same time")
+	addFilterFlags(reindexCmd)
+
+	return reindexCmd
+}
+
+func reindexOptionsFromFlags(cmd *cobra.Command) app.ReindexOptions {
+	metadata, _ := cmd.Flags().GetBool("metadata")
+	embeddings, _ := cmd.Flags().GetBool("embeddings")
+	force, _ := cmd.Flags().GetBool("force")
+	if !metadata && !embeddings {
+		metadata, embeddings = true, true
+	}
+	return app.ReindexOptions{Metadata: metadata, Embeddings: embeddings, Force: force}
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"charm.land/lipgloss/v2"
+	"github.com/spf13/cobra"
+	"github.com/yagnikpt/flashback/internal/app"
+	"github.com/yagnikpt/flashback/internal/utils"
+)
+
+func NewRelatedCmd(a *app.App) *cobra.Command {
+	relatedCmd := &cobra.Command{
+		Use:   "related <id>",
+		Short: "List the notes related to a note",
+		Long: `List the notes you linked to a note and the notes most similar to it by meaning. Other output formats than the table only include the similar notes; use flashback links for the linked ones.
+
+Examples:
+  flashback related 3C5uPKK4yvGZ3qUMJoCcdv
+  flashback related -n 10 -o json 3C5uPKK4yvGZ3qUMJoCcdv`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, err := outputFormatFlag(cmd)
+			if err != nil {
+				return withCode(exitUsage, "Error: %w", err)
+			}
+			limit, _ := cmd.Flags().GetInt("limit")
+			if limit < 1 {
+				return withCode(exitUsage, "--limit must be positive.")
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			id := args[0]
+			similar, err := a.GetRelatedNotes(ctx, id, limit)
+			if err != nil {
+				return withCode(exitStorage, "Error retrieving related notes: %w", err)
+			}
+			if format != utils.OutputTable {
+				if err := utils.WriteNotes(os.Stdout, format, similar); err != nil {
+					return withCode(exitError, "Error writing notes: %w", err)
+				}
+				return nil
+			}
+
+			links, err := a.GetLinkedNotes(ctx, id)
+			if err != nil {
+				return withCode(exitStorage, "Error retrieving linked notes: %w", err)
+			}
+			output := utils.FormatRelatedNotes(links, similar)
+			if output == "" {
+				printInfo(cmd, "No related notes found.")
+				return nil
+			}
+			lipgloss.Print(output)
+			return nil
+		},
+	}
+
+	relatedCmd.Flags().IntP("limit", "n", app.RelatedLimit, "Maximum number of similar notes")
+	addOutputFlag(relatedCmd)
+
+	return relatedCmd
+}
@@ -14,29 +14,43 @@ import (
 
 func NewRemoveCmd(app *app.App) *cobra.Command {
 	removeCmd := &cobra.Command{
-		Use:     "remove",
+		Use:     "remove <id>...",
 		Aliases: []string{"rm", "delete", "del"},
-		Short:   "Remove a note by its ID",
-		Long: `Remove a note from the flashback database by providing its unique ID.
+		Short:   "Move notes to the trash by their ID",
+		Long: `Move notes to the trash by providing their unique IDs. Notes in the trash are left out everywhere else and can be restored with flashback trash restore until they are deleted permanently, after trash_retention_days (30 unless set in the config).
+
+Use --permanent to delete notes right away, skipping the trash.
 
 Examples:
-  flashback remove 12345`,
-		Run: func(cmd *cobra.Command, args []string) {
+  flashback remove 12345
+  flashback remove --permanent 12345 67890`,
+		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) == 0 {
-				fmt.Println("Please provide the ID of the note to remove.")
+				return withCode(exitUsage, "Please provide the ID of the note to remove.")
 			}
+			permanent, _ := cmd.Flags().GetBool("permanent")
+
 			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 			defer cancel()
 
-			noteID := args[0]
-			err := app.DeleteNoteByID(ctx, noteID)
-			if err != nil {
-				fmt.Println("Error removing note:", err)
-				return
+			for _, noteID := range args {
+				if permanent {
+					if err := app.PurgeNote(ctx, noteID); err != nil {
+						return withCode(exitStorage, "Error deleting note %s: %w", noteID, err)
+					}
+					printInfo(cmd, fmt.Sprintf("Deleted %s permanently.", noteID))
+					continue
+				}
+				if err := app.DeleteNoteByID(ctx, noteID); err != nil {
+					return withCode(exitStorage, "Error removing note %s: %w", noteID, err)
+				}
+				printInfo(cmd, fmt.Sprintf("Moved %s to the trash. Restore it with flashback trash restore %s.", noteID, noteID))
 			}
-			fmt.Println("Note removed successfully.")
+			return nil
 		},
 	}
 
+	removeCmd.Flags().Bool("permanent", false, "Delete the notes permanently instead of moving them to the trash")
+
 	return removeCmd
 }
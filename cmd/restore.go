@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/yagnikpt/flashback/internal/app"
+)
+
+func NewRestoreCmd(app *app.App) *cobra.Command {
+	return &cobra.Command{
+		Use:   "restore <id> <rev>",
+		Short: "Bring back an earlier revision of a note",
+		Long: `Bring back the content, metadata and tags you owned in an earlier revision of a note. The restored version is saved as a new revision, so restoring can be undone the same way. Generated metadata and the embedding are regenerated in the background.
+
+To take a note out of the trash, use flashback trash restore.
+
+Examples:
+  flashback history 3C5uPKK4yvGZ3qUMJoCcdv
+  flashback restore 3C5uPKK4yvGZ3qUMJoCcdv 2`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			number, err := parseRevision(args[1])
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			if err := app.RestoreRevision(ctx, args[0], number); err != nil {
+				return withCode(exitStorage, "Error restoring revision %d of %s: %w", number, args[0], err)
+			}
+			printInfo(cmd, fmt.Sprintf("Restored %s to revision %d.", args[0], number))
+			return nil
+		},
+	}
+}
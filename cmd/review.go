@@ -0,0 +1,236 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"charm.land/lipgloss/v2"
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+	"github.com/yagnikpt/flashback/internal/app"
+	"github.com/yagnikpt/flashback/internal/models"
+	"github.com/yagnikpt/flashback/internal/utils"
+)
+
+func NewReviewCmd(a *app.App) *cobra.Command {
+	reviewCmd := &cobra.Command{
+		Use:   "review",
+		Short: "Review notes that are due, with spaced repetition",
+		Long: `Review the notes that are due one at a time. Each note is shown without its summary; try to recall it, reveal the summary and grade how well you remembered it. Notes you remember come back after longer and longer intervals, notes you forgot come back tomorrow (SM-2).
+
+Notes that were never reviewed are added oldest first, up to review_new_per_day a day (10 unless set in the config). Every note is reviewed unless you opt it out, or opt a tag in or out; see flashback review include and exclude. Opting a tag in switches review to opt-in: from then on, only notes with a tag opted in, or opted in themselves, are reviewed, and every other note is left out until the tag is reset.
+
+Examples:
+  flashback review
+  flashback review -n 5
+  flashback review --list -o json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			limit, _ := cmd.Flags().GetInt("limit")
+			if limit < 0 {
+				return withCode(exitUsage, "--limit can't be negative.")
+			}
+			list, _ := cmd.Flags().GetBool("list")
+			format, err := outputFormatFlag(cmd)
+			if err != nil {
+				return withCode(exitUsage, "Error: %w", err)
+			}
+			if !list && !utils.IsInteractive() {
+				return withCode(exitUsage, "review asks for a grade for each note and needs a terminal; use --list or flashback review grade.")
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+
+			cards, err := a.GetReviewQueue(ctx, limit)
+			if err != nil {
+				return withCode(exitStorage, "Error retrieving notes to review: %w", err)
+			}
+
+			if list {
+				if format != utils.OutputTable {
+					notes := make([]models.FlashbackWithMetadata, len(cards))
+					for i, card := range cards {
+						notes[i] = card.Note
+					}
+					if err := utils.WriteNotes(os.Stdout, format, unscored(notes)); err != nil {
+						return withCode(exitError, "Error writing notes: %w", err)
+					}
+					return nil
+				}
+				if len(cards) == 0 {
+					printInfo(cmd, "Nothing to review.")
+					return nil
+				}
+				for _, card := range cards {
+					fmt.Printf("%s  %s  %s\n", card.Note.ID, formatDue(card), utils.NoteTitle(card.Note))
+				}
+				return nil
+			}
+
+			if len(cards) == 0 {
+				printInfo(cmd, "Nothing to review. Come back later.")
+				return nil
+			}
+
+			reviewed := 0
+			for i, card := range cards {
+				fmt.Printf("%s\n\n", labelStyles(fmt.Sprintf("Note %d of %d", i+1, len(cards)))+"  "+card.Note.ID+"  "+formatDue(card))
+				lipgloss.Print(utils.FormatReviewCard(card.Note, false))
+
+				answer, err := promptChoice("\nPress enter to reveal the summary, or [s]kip or [q]uit.", "r")
+				if err != nil {
+					return withCode(exitUsage, "Error reading the answer: %w", err)
+				}
+				if answer == "q" {
+					break
+				}
+				if answer == "s" {
+					fmt.Println()
+					continue
+				}
+				fmt.Println()
+				lipgloss.Print(utils.FormatReviewCard(card.Note, true))
+
+				var grade app.Grade
+				for {
+					answer, err = promptChoice("\nHow well did you remember it? [a]gain, [h]ard, [g]ood, [e]asy, [s]kip or [q]uit (g)", "g")
+					if err != nil {
+						return withCode(exitUsage, "Error reading the answer: %w", err)
+					}
+					if answer == "s" || answer == "q" {
+						break
+					}
+					if grade, err = app.ParseGrade(answer); err == nil {
+						break
+					}
+				}
+				if answer == "q" {
+					break
+				}
+				if answer == "s" {
+					fmt.Println()
+					continue
+				}
+
+				state, err := a.GradeNote(ctx, card.Note.ID, grade)
+				if err != nil {
+					return withCode(exitStorage, "Error saving the review of %s: %w", card.Note.ID, err)
+				}
+				reviewed++
+				printInfo(cmd, fmt.Sprintf("Next review %s.", formatInterval(state.Interval)))
+				fmt.Println()
+			}
+
+			printInfo(cmd, fmt.Sprintf("Reviewed %d of %d notes.", reviewed, len(cards)))
+			return nil
+		},
+	}
+
+	reviewCmd.Flags().IntP("limit", "n", 20, "Maximum number of notes to review; 0 reviews every note due")
+	reviewCmd.Flags().Bool("list", false, "List the notes to review without reviewing them")
+	addOutputFlag(reviewCmd)
+	reviewCmd.AddCommand(newReviewGradeCmd(a))
+	reviewCmd.AddCommand(newReviewChoiceCmd(a, "include", app.ReviewInclude))
+	reviewCmd.AddCommand(newReviewChoiceCmd(a, "exclude", app.ReviewExclude))
+	reviewCmd.AddCommand(newReviewChoiceCmd(a, "reset", app.ReviewDefault))
+
+	return reviewCmd
+}
+
+func newReviewGradeCmd(a *app.App) *cobra.Command {
+	return &cobra.Command{
+		Use:   "grade <id> <again|hard|good|easy>",
+		Short: "Record a review of a note without the interactive session",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			grade, err := app.ParseGrade(args[1])
+			if err != nil {
+				return withCode(exitUsage, "Error: %w", err)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			state, err := a.GradeNote(ctx, args[0], grade)
+			if err != nil {
+				return withCode(exitStorage, "Error saving the review of %s: %w", args[0], err)
+			}
+			printInfo(cmd, fmt.Sprintf("Next review of %s %s.", args[0], formatInterval(state.Interval)))
+			return nil
+		},
+	}
+}
+
+// newReviewChoiceCmd builds the include, exclude and reset subcommands,
+// which only differ by the choice they store.
+func newReviewChoiceCmd(a *app.App, name string, choice app.ReviewChoice) *cobra.Command {
+	short := map[app.ReviewChoice]string{
+		app.ReviewInclude: "Opt notes, or tags with --tag, in to review",
+		app.ReviewExclude: "Opt notes, or tags with --tag, out of review",
+		app.ReviewDefault: "Drop the review choice of notes, or tags with --tag",
+	}
+	done := map[app.ReviewChoice]string{
+		app.ReviewInclude: "Opted %s in to review.",
+		app.ReviewExclude: "Opted %s out of review.",
+		app.ReviewDefault: "Dropped the review choice of %s.",
+	}
+
+	choiceCmd := &cobra.Command{
+		Use:   name + " <id>...",
+		Short: short[choice],
+		Long: short[choice] + `. With --tag, the arguments are tags: once any tag is opted in, only notes with a tag opted in are reviewed and every other note is left out. A note's own choice wins over its tags'.
+
+Examples:
+  flashback review ` + name + ` 3C5uPKK4yvGZ3qUMJoCcdv
+  flashback review ` + name + ` --tag kubernetes`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			tag, _ := cmd.Flags().GetBool("tag")
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			for _, arg := range args {
+				if tag {
+					if err := a.SetTagReview(ctx, arg, choice); err != nil {
+						return withCode(exitStorage, "Error updating tag %s: %w", arg, err)
+					}
+					printInfo(cmd, fmt.Sprintf(done[choice], "tag "+arg))
+					if choice == app.ReviewInclude {
+						printInfo(cmd, "Only notes with a tag opted in, or opted in themselves, are reviewed now.")
+					}
+					continue
+				}
+				if err := a.SetNoteReview(ctx, arg, choice); err != nil {
+					return withCode(exitStorage, "Error updating note %s: %w", arg, err)
+				}
+				printInfo(cmd, fmt.Sprintf(done[choice], arg))
+			}
+			return nil
+		},
+	}
+
+	choiceCmd.Flags().Bool("tag", false, "Treat the arguments as tags")
+
+	return choiceCmd
+}
+
+// formatDue describes when card was due.
+func formatDue(card app.ReviewCard) string {
+	if card.New {
+		return "new"
+	}
+	return "due " + humanize.RelTime(card.State.Due, time.Now(), "ago", "from now")
+}
+
+// formatInterval describes when a note comes back after interval days.
+func formatInterval(days int) string {
+	if days == 1 {
+		return "tomorrow"
+	}
+	return fmt.Sprintf("in %d days", days)
+}
@@ -1,11 +1,14 @@
 package cmd
 
 import (
+	"errors"
+	"fmt"
 	"os"
 
 	"github.com/spf13/cobra"
 	"github.com/yagnikpt/flashback/internal/app"
 	"github.com/yagnikpt/flashback/internal/tui"
+	"github.com/yagnikpt/flashback/internal/utils"
 )
 
 func NewRootCmd(app *app.App) *cobra.Command {
@@ -15,25 +18,62 @@ func NewRootCmd(app *app.App) *cobra.Command {
 		Long: `Flashback is a command-line application for storing and retrieving personal notes using semantic search powered by embeddings.
 
 It supports adding notes from text or web URLs, generating metadata, and performing similarity-based searches to help you recall information efficiently.`,
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !utils.IsInteractive() {
+				return withCode(exitUsage, "The flashback TUI needs a terminal. Run `flashback --help` to see the commands available to scripts.")
+			}
 			tui.Run(app)
+			return nil
 		},
+		SilenceErrors: true,
+		SilenceUsage:  true,
 	}
 
+	cmd.PersistentFlags().BoolP("quiet", "q", false, "Suppress progress and informational output")
+	cmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return withCode(exitUsage, "%w\nRun '%s --help' for usage.", err, cmd.CommandPath())
+	})
+
 	cmd.AddCommand(NewAddCmd(app))
 	cmd.AddCommand(NewSearchCmd(app))
+	cmd.AddCommand(NewAskCmd(app))
 	cmd.AddCommand(NewListCmd(app))
 	cmd.AddCommand(NewRemoveCmd(app))
+	cmd.AddCommand(NewTrashCmd(app))
 	cmd.AddCommand(NewShowCmd(app))
+	cmd.AddCommand(NewRelatedCmd(app))
+	cmd.AddCommand(NewLinksCmd(app))
+	cmd.AddCommand(NewReviewCmd(app))
+	cmd.AddCommand(NewEditCmd(app))
+	cmd.AddCommand(NewHistoryCmd(app))
+	cmd.AddCommand(NewDiffCmd(app))
+	cmd.AddCommand(NewRestoreCmd(app))
+	cmd.AddCommand(NewTagsCmd(app))
+	cmd.AddCommand(NewCollectionCmd(app))
+	cmd.AddCommand(NewExportCmd(app))
+	cmd.AddCommand(NewImportCmd(app))
+	cmd.AddCommand(NewWorkerCmd(app))
+	cmd.AddCommand(NewJobsCmd(app))
+	cmd.AddCommand(NewReindexCmd(app))
+	cmd.AddCommand(NewDedupeCmd(app))
+	cmd.AddCommand(NewReadCmd(app))
 
 	return cmd
 }
 
-func Execute(app *app.App) {
+// Execute runs the command line and returns the process exit code.
+func Execute(app *app.App) int {
 	rootCmd := NewRootCmd(app)
+	usageErrors(rootCmd)
 
 	err := rootCmd.Execute()
 	if err != nil {
-		os.Exit(1)
+		var e *codedError
+		if errors.As(err, &e) {
+			fmt.Fprintln(os.Stderr, err)
+		} else {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+		}
 	}
+	return exitCode(err)
 }
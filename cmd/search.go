@@ -6,9 +6,11 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"os"
 	"strings"
 	"time"
 
+	"charm.land/lipgloss/v2"
 	"github.com/spf13/cobra"
 	"github.com/yagnikpt/flashback/internal/app"
 	"github.com/yagnikpt/flashback/internal/utils"
@@ -18,34 +20,99 @@ func NewSearchCmd(app *app.App) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:     "search",
 		Aliases: []string{"s"},
-		Short:   "Search notes using semantic similarity",
-		Long: `Search for notes in the flashback database using semantic similarity. Provide a query string, and the tool will find notes with similar meanings based on embeddings.
+		Short:   "Search notes using full-text and semantic similarity",
+		Long: `Search for notes in the flashback database. By default results from full-text search and semantic similarity are merged, so both exact tokens and notes with similar meanings are found.
 
 Usage:
   flashback search [query]
 
 Examples:
   flashback search "machine learning concepts"
-  flashback search "buy groceries"`,
-		Run: func(cmd *cobra.Command, args []string) {
-			if len(args) == 0 {
+  flashback search "buy groceries"
+  flashback search --mode lexical "kubectl rollout"
+  flashback search --tag k8s --since 7d "load balancer"
+  flashback search "tag:k8s type:url after:2025-01-01 load balancer"
+  flashback search --in homelab "reverse proxy"
+  flashback search -o json kubernetes`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 && cmd.Flags().NFlag() == 0 {
 				fmt.Println(cmd.Long)
-				return
+				return nil
 			}
 
 			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 			defer cancel()
 
-			words := strings.Join(args, " ")
-			embeddings, _ := app.GenerateEmbeddingForNote(ctx, words, "RETRIEVAL_QUERY")
-			flashbacks, err := app.RetrieveNotesBySimilarity(ctx, embeddings)
+			opts, err := searchOptionsFromFlags(cmd, strings.Join(args, " "))
 			if err != nil {
-				fmt.Println("Error retrieving notes:", err)
+				return withCode(exitUsage, "Error: %w", err)
 			}
-			output := utils.FormatMultipleNotesCompact(flashbacks)
-			fmt.Println(output)
+			format, err := outputFormatFlag(cmd)
+			if err != nil {
+				return withCode(exitUsage, "Error: %w", err)
+			}
+
+			result, err := app.SearchNotes(ctx, opts)
+			if err != nil {
+				return withCode(exitStorage, "Error retrieving notes: %w", err)
+			}
+			if format != utils.OutputTable {
+				if result.Fallback != nil {
+					fmt.Fprintf(os.Stderr, "Semantic search unavailable (%v), showing keyword matches only.\n", result.Fallback)
+				}
+				if result.Incomparable > 0 {
+					fmt.Fprintln(os.Stderr, incomparableWarning(result.Incomparable))
+				}
+				err := utils.WriteNotes(os.Stdout, format, scoredResults(result))
+				if err != nil {
+					return withCode(exitError, "Error writing notes: %w", err)
+				}
+				return nil
+			}
+			if result.Fallback != nil {
+				fmt.Printf("Semantic search unavailable (%v), showing keyword matches only.\n\n", result.Fallback)
+			}
+			if result.Incomparable > 0 {
+				fmt.Printf("%s\n\n", incomparableWarning(result.Incomparable))
+			}
+			output := utils.FormatSearchResultsCompact(scoredResults(result), opts.Query)
+			lipgloss.Println(output)
+			return nil
 		},
 	}
 
+	cmd.Flags().StringP("mode", "m", "hybrid", "Search mode: hybrid, lexical or semantic")
+	cmd.Flags().Float64("threshold", 0.6, "Minimum semantic similarity (0-1) for a note to match")
+	cmd.Flags().IntP("limit", "n", 20, "Maximum number of results")
+	addFilterFlags(cmd)
+	addOutputFlag(cmd)
+
 	return cmd
 }
+
+// searchOptionsFromFlags parses inline filters out of query and adds the
+// search flags set on cmd.
+func searchOptionsFromFlags(cmd *cobra.Command, query string) (app.SearchOptions, error) {
+	opts, err := app.ParseSearchQuery(query)
+	if err != nil {
+		return app.SearchOptions{}, err
+	}
+
+	mode, _ := cmd.Flags().GetString("mode")
+	opts.Mode, err = app.ParseSearchMode(mode)
+	if err != nil {
+		return app.SearchOptions{}, err
+	}
+	opts.MinSimilarity, _ = cmd.Flags().GetFloat64("threshold")
+	if opts.MinSimilarity <= 0 || opts.MinSimilarity > 1 {
+		return app.SearchOptions{}, fmt.Errorf("threshold must be between 0 and 1")
+	}
+	opts.Limit, _ = cmd.Flags().GetInt("limit")
+
+	err = applyFilterFlags(cmd, &opts.Filter)
+	return opts, err
+}
+
+func incomparableWarning(count int) string {
+	return fmt.Sprintf("Warning: %d notes have embeddings from another model and were left out of semantic search. Run flashback reindex --embeddings to include them.", count)
+}
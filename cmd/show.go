@@ -2,15 +2,17 @@ package cmd
 
 import (
 	"context"
-	"fmt"
+	"os"
 	"time"
 
+	"charm.land/lipgloss/v2"
 	"github.com/spf13/cobra"
 	"github.com/yagnikpt/flashback/internal/app"
+	"github.com/yagnikpt/flashback/internal/models"
 	"github.com/yagnikpt/flashback/internal/utils"
 )
 
-func NewShowCmd(app *app.App) *cobra.Command {
+func NewShowCmd(a *app.App) *cobra.Command {
 	showCmd := &cobra.Command{
 		Use:     "show",
 		Aliases: []string{"sh"},
@@ -18,24 +20,51 @@ func NewShowCmd(app *app.App) *cobra.Command {
 		Long: `This command shows the content, metadata, and other details of the specified note.
 
 Examples:
-  flashback show 3C5uPKK4yvGZ3qUMJoCcdv`,
-		Run: func(cmd *cobra.Command, args []string) {
+  flashback show 3C5uPKK4yvGZ3qUMJoCcdv
+  flashback show -o yaml 3C5uPKK4yvGZ3qUMJoCcdv`,
+		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) == 0 {
-				fmt.Println("Please provide the ID of the note to show.")
+				return withCode(exitUsage, "Please provide the ID of the note to show.")
 			}
+			format, err := outputFormatFlag(cmd)
+			if err != nil {
+				return withCode(exitUsage, "Error: %w", err)
+			}
+
 			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 			defer cancel()
 
 			noteID := args[0]
-			flashback, err := app.GetNoteByID(ctx, noteID)
+			flashback, err := a.GetNoteByID(ctx, noteID)
 			if err != nil {
-				fmt.Println("Error retrieving note:", err)
-				return
+				return withCode(exitStorage, "Error retrieving note: %w", err)
+			}
+			if format != utils.OutputTable {
+				err := utils.WriteNote(os.Stdout, format, models.ScoredFlashback{FlashbackWithMetadata: flashback})
+				if err != nil {
+					return withCode(exitError, "Error writing note: %w", err)
+				}
+				return nil
 			}
 			output := utils.FormatSingleNote(flashback)
-			fmt.Println(output)
+
+			links, err := a.GetLinkedNotes(ctx, noteID)
+			if err != nil {
+				return withCode(exitStorage, "Error retrieving linked notes: %w", err)
+			}
+			similar, err := a.GetRelatedNotes(ctx, noteID, app.RelatedLimit)
+			if err != nil {
+				return withCode(exitStorage, "Error retrieving related notes: %w", err)
+			}
+			if related := utils.FormatRelatedNotes(links, similar); related != "" {
+				output += "\n" + related
+			}
+			lipgloss.Println(output)
+			return nil
 		},
 	}
 
+	addOutputFlag(showCmd)
+
 	return showCmd
 }
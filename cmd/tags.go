@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/yagnikpt/flashback/internal/app"
+)
+
+func NewTagsCmd(app *app.App) *cobra.Command {
+	tagsCmd := &cobra.Command{
+		Use:     "tags",
+		Aliases: []string{"tag"},
+		Short:   "List and manage tags",
+		Long: `List all tags with the number of notes using them, or rename, merge and delete tags.
+
+Examples:
+  flashback tags
+  flashback tags rename kubernetes k8s
+  flashback tags merge k8s-infra kube k8s
+  flashback tags delete misc`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			tags, err := app.ListTags(ctx)
+			if err != nil {
+				return withCode(exitStorage, "Error retrieving tags: %w", err)
+			}
+			if len(tags) == 0 {
+				printInfo(cmd, "No tags yet.")
+				return nil
+			}
+
+			nameWidth := len("Tag")
+			for _, tag := range tags {
+				nameWidth = max(nameWidth, len(tag.Name))
+			}
+			fmt.Printf("%-*s  %s\n\n", nameWidth, "Tag", "Notes")
+			for _, tag := range tags {
+				fmt.Printf("%-*s  %d\n", nameWidth, tag.Name, tag.Count)
+			}
+			return nil
+		},
+	}
+
+	tagsCmd.AddCommand(newTagsRenameCmd(app))
+	tagsCmd.AddCommand(newTagsMergeCmd(app))
+	tagsCmd.AddCommand(newTagsDeleteCmd(app))
+
+	return tagsCmd
+}
+
+func newTagsRenameCmd(app *app.App) *cobra.Command {
+	return &cobra.Command{
+		Use:   "rename <old> <new>",
+		Short: "Rename a tag, merging it if the new name already exists",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			err := app.RenameTag(ctx, args[0], args[1])
+			if err != nil {
+				return withCode(exitStorage, "Error renaming tag: %w", err)
+			}
+			printInfo(cmd, fmt.Sprintf("Renamed %s to %s.", args[0], args[1]))
+			return nil
+		},
+	}
+}
+
+func newTagsMergeCmd(app *app.App) *cobra.Command {
+	return &cobra.Command{
+		Use:   "merge <tag>... <into>",
+		Short: "Merge one or more tags into another",
+		Args:  cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			sources, target := args[:len(args)-1], args[len(args)-1]
+			err := app.MergeTags(ctx, sources, target)
+			if err != nil {
+				return withCode(exitStorage, "Error merging tags: %w", err)
+			}
+			printInfo(cmd, fmt.Sprintf("Merged %s into %s.", strings.Join(sources, ", "), target))
+			return nil
+		},
+	}
+}
+
+func newTagsDeleteCmd(app *app.App) *cobra.Command {
+	return &cobra.Command{
+		Use:     "delete <tag>",
+		Aliases: []string{"rm", "remove"},
+		Short:   "Remove a tag from all notes",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			err := app.DeleteTag(ctx, args[0])
+			if err != nil {
+				return withCode(exitStorage, "Error deleting tag: %w", err)
+			}
+			printInfo(cmd, fmt.Sprintf("Deleted tag %s.", args[0]))
+			return nil
+		},
+	}
+}
@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+	"github.com/yagnikpt/flashback/internal/app"
+	"github.com/yagnikpt/flashback/internal/models"
+	"github.com/yagnikpt/flashback/internal/utils"
+)
+
+func NewTrashCmd(app *app.App) *cobra.Command {
+	trashCmd := &cobra.Command{
+		Use:   "trash",
+		Short: "List, restore and empty the notes in the trash",
+		Long: `Removed notes go to the trash, where they can be restored until they are deleted permanently. Notes are deleted after trash_retention_days (30 unless set in the config; 0 keeps them until the trash is emptied).
+
+Examples:
+  flashback trash list
+  flashback trash restore 3C5uPKK4yvGZ3qUMJoCcdv
+  flashback trash empty --yes`,
+		Args: cobra.NoArgs,
+	}
+
+	trashCmd.AddCommand(newTrashListCmd(app))
+	trashCmd.AddCommand(newTrashRestoreCmd(app))
+	trashCmd.AddCommand(newTrashEmptyCmd(app))
+
+	return trashCmd
+}
+
+func newTrashListCmd(app *app.App) *cobra.Command {
+	listCmd := &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List the notes in the trash, most recently removed first",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, err := outputFormatFlag(cmd)
+			if err != nil {
+				return withCode(exitUsage, "Error: %w", err)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			trashed, err := app.ListTrash(ctx)
+			if err != nil {
+				return withCode(exitStorage, "Error retrieving the trash: %w", err)
+			}
+			if format != utils.OutputTable {
+				notes := make([]models.FlashbackWithMetadata, len(trashed))
+				for i, note := range trashed {
+					notes[i] = note.FlashbackWithMetadata
+				}
+				if err := utils.WriteNotes(os.Stdout, format, unscored(notes)); err != nil {
+					return withCode(exitError, "Error writing notes: %w", err)
+				}
+				return nil
+			}
+			if len(trashed) == 0 {
+				printInfo(cmd, "The trash is empty.")
+				return nil
+			}
+
+			for _, note := range trashed {
+				fmt.Printf("%s  removed %s  %s\n", note.ID, humanize.Time(note.DeletedAt), utils.NoteTitle(note.FlashbackWithMetadata))
+			}
+			if days := app.TrashRetentionDays(); days > 0 {
+				printInfo(cmd, fmt.Sprintf("\nNotes are deleted permanently %d days after they were removed.", days))
+			}
+			return nil
+		},
+	}
+
+	addOutputFlag(listCmd)
+
+	return listCmd
+}
+
+func newTrashRestoreCmd(app *app.App) *cobra.Command {
+	return &cobra.Command{
+		Use:   "restore <id>...",
+		Short: "Take notes out of the trash",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			for _, id := range args {
+				if err := app.RestoreNote(ctx, id); err != nil {
+					return withCode(exitStorage, "Error restoring note %s: %w", id, err)
+				}
+				printInfo(cmd, fmt.Sprintf("Restored %s.", id))
+			}
+			return nil
+		},
+	}
+}
+
+func newTrashEmptyCmd(app *app.App) *cobra.Command {
+	emptyCmd := &cobra.Command{
+		Use:   "empty",
+		Short: "Delete every note in the trash permanently",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			yes, _ := cmd.Flags().GetBool("yes")
+			if !yes && !utils.IsInteractive() {
+				return withCode(exitUsage, "trash empty asks for confirmation and needs a terminal; use --yes.")
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+
+			trashed, err := app.ListTrash(ctx)
+			if err != nil {
+				return withCode(exitStorage, "Error retrieving the trash: %w", err)
+			}
+			if len(trashed) == 0 {
+				printInfo(cmd, "The trash is empty.")
+				return nil
+			}
+			if !yes {
+				answer, err := promptChoice(fmt.Sprintf("Delete %d notes permanently? [y]es or [n]o (n)", len(trashed)), "n")
+				if err != nil {
+					return withCode(exitUsage, "Error reading the answer: %w", err)
+				}
+				if answer != "y" {
+					return nil
+				}
+			}
+
+			n, err := app.EmptyTrash(ctx)
+			if err != nil {
+				return withCode(exitStorage, "Error emptying the trash: %w", err)
+			}
+			printInfo(cmd, fmt.Sprintf("Deleted %d notes permanently.", n))
+			return nil
+		},
+	}
+
+	emptyCmd.Flags().BoolP("yes", "y", false, "Empty the trash without asking")
+
+	return emptyCmd
+}
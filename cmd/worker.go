@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/spf13/cobra"
+	"github.com/yagnikpt/flashback/internal/app"
+	"github.com/yagnikpt/flashback/internal/worker"
+)
+
+func NewWorkerCmd(app *app.App) *cobra.Command {
+	workerCmd := &cobra.Command{
+		Use:   "worker",
+		Short: "Generate metadata and embeddings for queued notes",
+		Long: `Process the background jobs queued by flashback add and flashback import, such as generating the metadata and embedding of new notes. The TUI does the same while it is open.
+
+The worker keeps waiting for new jobs until interrupted. With --once it exits when no job is due, which suits cron.
+
+Examples:
+  flashback worker
+  flashback worker --once`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			once, _ := cmd.Flags().GetBool("once")
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+
+			var failed int
+			err := worker.Run(ctx, app, worker.Options{Once: once}, func(event worker.Event) {
+				if !event.Done {
+					return
+				}
+				if event.Err != nil {
+					failed++
+					fmt.Fprintf(os.Stderr, "Job %d (%s %s) failed: %v\n", event.Job.ID, event.Job.Kind, event.Job.FlashbackID, event.Err)
+					return
+				}
+				printInfo(cmd, fmt.Sprintf("Job %d (%s %s) done.", event.Job.ID, event.Job.Kind, event.Job.FlashbackID))
+			})
+			if err != nil && !errors.Is(err, context.Canceled) {
+				return withCode(exitStorage, "Error running jobs: %w", err)
+			}
+			if failed > 0 {
+				return withCode(exitError, "%d jobs failed, see flashback jobs.", failed)
+			}
+			return nil
+		},
+	}
+
+	workerCmd.Flags().Bool("once", false, "Exit when no job is due instead of waiting for more")
+
+	return workerCmd
+}
@@ -0,0 +1,50 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	_ "turso.tech/database/tursogo"
+
+	"github.com/yagnikpt/flashback/internal/migration"
+	"github.com/yagnikpt/flashback/internal/providers"
+)
+
+// newTestApp returns an App on a migrated database in a temporary
+// directory, with testProvider standing in for the AI provider.
+func newTestApp(t *testing.T) *App {
+	t.Helper()
+	db, err := sql.Open("turso", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := migration.Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+
+	provider := testProvider{model: "test/model"}
+	return &App{DB: db, Enricher: provider, Generator: provider, Embedder: provider}
+}
+
+// testProvider generates empty metadata and the same small embedding for
+// any text.
+type testProvider struct{ model string }
+
+func (p testProvider) GenerateJSON(context.Context, providers.GenerateRequest) (string, error) {
+	return `{}`, nil
+}
+
+func (p testProvider) GenerateText(context.Context, providers.GenerateRequest, func(string)) (string, error) {
+	return "", nil
+}
+
+func (p testProvider) Embed(context.Context, string, string) ([]float32, error) {
+	return []float32{1, 0, 0}, nil
+}
+
+func (p testProvider) GenerationModel() string  { return p.model }
+func (p testProvider) EmbeddingModel() string   { return p.model }
+func (p testProvider) EmbeddingDimensions() int { return 3 }
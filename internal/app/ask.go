@@ -0,0 +1,114 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/yagnikpt/flashback/internal/models"
+	"github.com/yagnikpt/flashback/internal/providers"
+	"github.com/yagnikpt/flashback/internal/utils"
+)
+
+const (
+	// askLimit is the number of notes given to the model to answer from.
+	askLimit = 8
+	// askContentLength bounds the content of each note in the prompt, so
+	// that long notes don't crowd out the others.
+	askContentLength = 2000
+)
+
+// ErrNothingRelevant is returned by Ask when no note is similar enough to the
+// question to answer from.
+var ErrNothingRelevant = errors.New("no notes are relevant to the question")
+
+// citationPattern matches the [id] citations AskPrompt asks for.
+var citationPattern = regexp.MustCompile(`\[([A-Za-z0-9]+)\]`)
+
+type Answer struct {
+	Question string `json:"question"`
+	Text     string `json:"answer"`
+	// Sources are the notes the answer was generated from, most similar
+	// first.
+	Sources []models.ScoredFlashback `json:"sources"`
+	// Citations are the IDs of the sources the answer cites, in the order
+	// they are first cited.
+	Citations []string `json:"citations"`
+}
+
+// Ask answers question from the notes most similar to it, passing the answer
+// to emit as it is generated. It returns ErrNothingRelevant without calling
+// the generator when no note is relevant. When generation fails midway, the
+// partial answer is returned along with the error.
+func (app *App) Ask(ctx context.Context, question string, emit func(string)) (Answer, error) {
+	answer := Answer{Question: question, Citations: []string{}}
+	vector, err := app.GenerateEmbeddingForNote(ctx, question, providers.TaskRetrievalQuery)
+	if err != nil {
+		return answer, err
+	}
+	answer.Sources, err = app.RetrieveNotesBySimilarity(ctx, vector, askLimit)
+	if err != nil {
+		return answer, err
+	}
+	if len(answer.Sources) == 0 {
+		return answer, ErrNothingRelevant
+	}
+
+	answer.Text, err = app.Generator.GenerateText(ctx, providers.GenerateRequest{
+		SystemPrompt: utils.AskPrompt,
+		Content:      askContent(question, answer.Sources),
+	}, emit)
+	answer.Citations = citations(answer.Text, answer.Sources)
+	return answer, err
+}
+
+// askContent lays out the question and the notes to answer it from.
+func askContent(question string, notes []models.ScoredFlashback) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Question: %s\n\n", question)
+	for _, note := range notes {
+		fmt.Fprintf(&b, "<note id=\"%s\" type=\"%s\" saved=\"%s\">\n", note.ID, note.Type, note.CreatedAt)
+		for _, key := range []string{"title", "tldr", "description"} {
+			if value := note.Metadata[key]; value != "" {
+				fmt.Fprintf(&b, "%s: %s\n", key, html.EscapeString(value))
+			}
+		}
+		if len(note.Tags) > 0 {
+			fmt.Fprintf(&b, "tags: %s\n", strings.Join(note.Tags, ", "))
+		}
+		fmt.Fprintf(&b, "content: %s\n", html.EscapeString(truncate(note.Content, askContentLength)))
+		if note.Snippet != "" {
+			fmt.Fprintf(&b, "page excerpt: %s\n", html.EscapeString(note.Snippet))
+		}
+		b.WriteString("</note>\n")
+	}
+	return b.String()
+}
+
+// citations returns the IDs of notes cited in text, in order of first
+// citation. Citations of unknown IDs are ignored.
+func citations(text string, notes []models.ScoredFlashback) []string {
+	known := make(map[string]bool, len(notes))
+	for _, note := range notes {
+		known[note.ID] = true
+	}
+	cited := []string{}
+	for _, match := range citationPattern.FindAllStringSubmatch(text, -1) {
+		if id := match[1]; known[id] {
+			known[id] = false
+			cited = append(cited, id)
+		}
+	}
+	return cited
+}
+
+func truncate(text string, length int) string {
+	runes := []rune(text)
+	if len(runes) <= length {
+		return text
+	}
+	return string(runes[:length]) + "…"
+}
@@ -0,0 +1,208 @@
+package app
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"sort"
+	"strings"
+)
+
+const (
+	// chunkWords is the length of a passage, in words.
+	chunkWords = 200
+	// chunkOverlap is the number of words a passage shares with the
+	// previous one, so sentences cut at a boundary are still found.
+	chunkOverlap = 40
+	// maxChunks bounds the embedding calls made for one page.
+	maxChunks = 40
+)
+
+// Chunk is a passage of a page with its embedding.
+type Chunk struct {
+	Text      string
+	Embedding []float32
+}
+
+// SplitChunks splits text into overlapping passages of about chunkWords
+// words, at most maxChunks of them. Whitespace is collapsed.
+func SplitChunks(text string) []string {
+	words := strings.Fields(text)
+	var chunks []string
+	for start := 0; start < len(words) && len(chunks) < maxChunks; start += chunkWords - chunkOverlap {
+		end := min(start+chunkWords, len(words))
+		chunks = append(chunks, strings.Join(words[start:end], " "))
+		if end == len(words) {
+			break
+		}
+	}
+	return chunks
+}
+
+// storeChunks replaces the passages of note id with chunks, split from
+// body.
+func (app *App) storeChunks(tx *sql.Tx, id, body string, chunks []Chunk) error {
+	_, err := tx.Exec(`DELETE FROM chunks WHERE flashback_id = ?`, id)
+	if err != nil {
+		return err
+	}
+	model := app.Embedder.EmbeddingModel()
+	hash := bodyHash(body)
+	for i, chunk := range chunks {
+		data, err := json.Marshal(chunk.Embedding)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(`
+        INSERT INTO chunks (flashback_id, position, text, vector, model, dimension, body_hash) VALUES (?, ?, ?, vector32(?), ?, ?, ?)
+        `, id, i, chunk.Text, string(data), model, len(chunk.Embedding), hash)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ChunksCurrent reports whether the stored passages of note id were split
+// from body and embedded by the configured embedder, so that embedding them
+// again would give the same vectors.
+func (app *App) ChunksCurrent(ctx context.Context, id, body string) (bool, error) {
+	var total, current int
+	err := app.DB.QueryRowContext(ctx, `
+    SELECT COUNT(*), COALESCE(SUM(
+        body_hash = ? AND model IS ? AND dimension = COALESCE(NULLIF(?, 0), dimension)
+    ), 0)
+    FROM chunks WHERE flashback_id = ?
+    `, bodyHash(body), app.Embedder.EmbeddingModel(), app.Embedder.EmbeddingDimensions(), id).Scan(&total, &current)
+	if err != nil {
+		return false, err
+	}
+	return total > 0 && current == total, nil
+}
+
+func bodyHash(body string) string {
+	sum := sha256.Sum256([]byte(body))
+	return hex.EncodeToString(sum[:])
+}
+
+// rankChunksBySimilarity returns the IDs of notes with the passages closest
+// to vector, along with the similarity and text of their best passage. Like
+// rankBySimilarity, it uses the vector index once there are enough
+// passages.
+func (app *App) rankChunksBySimilarity(ctx context.Context, vector []float32, filter NoteFilter, similarity float64, limit int) ([]string, map[string]float64, map[string]string, error) {
+	if app.vectorIndex.ready(ctx, app.DB, annChunks, len(vector)) {
+		ids, similarities, passages, err := app.rankChunksByVectorIndex(ctx, vector, filter, similarity, limit)
+		if err == nil && (filter.IsZero() || len(ids) >= limit) {
+			return ids, similarities, passages, nil
+		}
+		if err != nil {
+			log.Println("Passage index search failed, using full scans:", err)
+			app.vectorIndex.disable(annChunks, len(vector))
+		}
+	}
+	return app.scanChunksBySimilarity(ctx, vector, filter, similarity, limit)
+}
+
+// scanChunksBySimilarity is rankChunksBySimilarity comparing vector with
+// every passage.
+func (app *App) scanChunksBySimilarity(ctx context.Context, vector []float32, filter NoteFilter, similarity float64, limit int) ([]string, map[string]float64, map[string]string, error) {
+	embeddings, err := json.Marshal(vector)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	where, args := filter.where("f")
+	args = append([]any{string(embeddings), app.Embedder.EmbeddingModel(), len(vector), string(embeddings), 1 - similarity}, args...)
+	args = append(args, limit*annOversample)
+	rows, err := app.DB.QueryContext(ctx, `
+    SELECT c.flashback_id, c.text, 1 - vector_distance_cos(c.vector, vector32(?)) AS score
+    FROM chunks c
+    JOIN flashbacks f ON f.id = c.flashback_id
+    WHERE c.model = ? AND c.dimension = ?
+        AND vector_distance_cos(c.vector, vector32(?)) < ?`+where+`
+    ORDER BY score DESC LIMIT ?
+    `, args...)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	defer rows.Close()
+	return scanPassages(rows, limit)
+}
+
+// rankChunksByVectorIndex is rankChunksBySimilarity using the passage index
+// for the dimension of vector.
+func (app *App) rankChunksByVectorIndex(ctx context.Context, vector []float32, filter NoteFilter, similarity float64, limit int) ([]string, map[string]float64, map[string]string, error) {
+	embeddings, err := json.Marshal(vector)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	table := chunkAnnTable(len(vector))
+	where, args := filter.where("f")
+	args = append([]any{string(embeddings), string(embeddings), limit * annOversample, app.Embedder.EmbeddingModel(), string(embeddings), 1 - similarity}, args...)
+	args = append(args, limit*annOversample)
+	rows, err := app.DB.QueryContext(ctx, `
+    SELECT c.flashback_id, c.text, 1 - vector_distance_cos(a.vector, vector32(?)) AS score
+    FROM vector_top_k('`+table+`_idx', vector32(?), ?) AS t
+    JOIN `+table+` a ON a.rowid = t.id
+    JOIN chunks c ON c.id = a.chunk_id
+    JOIN flashbacks f ON f.id = c.flashback_id
+    WHERE a.model = ?
+        AND vector_distance_cos(a.vector, vector32(?)) < ?`+where+`
+    ORDER BY score DESC LIMIT ?
+    `, args...)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	defer rows.Close()
+	return scanPassages(rows, limit)
+}
+
+// scanPassages reads rows of note IDs, passages and similarities, best
+// first, keeping the best passage of up to limit notes.
+func scanPassages(rows *sql.Rows, limit int) ([]string, map[string]float64, map[string]string, error) {
+	var ids []string
+	similarities := make(map[string]float64)
+	passages := make(map[string]string)
+	for rows.Next() {
+		var id, text string
+		var score float64
+		if err := rows.Scan(&id, &text, &score); err != nil {
+			return nil, nil, nil, err
+		}
+		if _, seen := similarities[id]; seen || len(ids) == limit {
+			continue
+		}
+		ids = append(ids, id)
+		similarities[id] = score
+		passages[id] = text
+	}
+	return ids, similarities, passages, rows.Err()
+}
+
+// mergeSimilarities ranks the notes found by either similarity search by
+// their best similarity.
+func mergeSimilarities(a, b map[string]float64) ([]string, map[string]float64) {
+	merged := make(map[string]float64, len(a)+len(b))
+	for _, m := range []map[string]float64{a, b} {
+		for id, score := range m {
+			if best, ok := merged[id]; !ok || score > best {
+				merged[id] = score
+			}
+		}
+	}
+	ids := make([]string, 0, len(merged))
+	for id := range merged {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if merged[ids[i]] != merged[ids[j]] {
+			return merged[ids[i]] > merged[ids[j]]
+		}
+		return ids[i] < ids[j]
+	})
+	return ids, merged
+}
@@ -0,0 +1,42 @@
+package app
+
+import (
+	"context"
+	"testing"
+)
+
+func TestChunksCurrent(t *testing.T) {
+	ctx := context.Background()
+	a := newTestApp(t)
+	id, err := a.InsertImportedNote(ctx, ImportedNote{Content: "https://example.com/a", Type: "url"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	const body = "A page about vector indexes."
+	if err := a.StorePage(ctx, id, body, "", []Chunk{{Text: body, Embedding: []float32{1, 0, 0}}}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		body  string
+		model string
+		want  bool
+	}{
+		{"same page and model", body, "test/model", true},
+		{"changed page", body + " Updated.", "test/model", false},
+		{"other model", body, "test/other", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a.Embedder = testProvider{model: tt.model}
+			current, err := a.ChunksCurrent(ctx, id, tt.body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if current != tt.want {
+				t.Errorf("ChunksCurrent = %t, want %t", current, tt.want)
+			}
+		})
+	}
+}
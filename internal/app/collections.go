@@ -0,0 +1,155 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+type CollectionCount struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// CreateCollection creates an empty collection. Adding notes to a
+// collection that doesn't exist creates it too.
+func (app *App) CreateCollection(ctx context.Context, name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("collection name cannot be empty")
+	}
+	res, err := app.DB.ExecContext(ctx, `INSERT OR IGNORE INTO collections (name) VALUES (?)`, name)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("collection %q already exists", name)
+	}
+	return nil
+}
+
+// ListCollections returns every collection with the number of notes in it,
+// by name.
+func (app *App) ListCollections(ctx context.Context) ([]CollectionCount, error) {
+	collections := []CollectionCount{}
+	err := eachRow(ctx, app.DB, `
+    SELECT c.name, COUNT(cn.flashback_id)
+    FROM collections c
+    LEFT JOIN collection_notes cn ON cn.collection_id = c.id
+        AND cn.flashback_id IN (SELECT id FROM flashbacks WHERE deleted_at IS NULL)
+    GROUP BY c.id
+    ORDER BY c.name COLLATE NOCASE
+    `, nil, func(rows *sql.Rows) error {
+		var c CollectionCount
+		if err := rows.Scan(&c.Name, &c.Count); err != nil {
+			return err
+		}
+		collections = append(collections, c)
+		return nil
+	})
+	return collections, err
+}
+
+// AddToCollection adds notes to the collection name, creating it if needed.
+// It returns sql.ErrNoRows when one of the notes doesn't exist, without
+// adding any.
+func (app *App) AddToCollection(ctx context.Context, name string, ids []string) error {
+	tx, err := app.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, id := range ids {
+		var exists bool
+		err := tx.QueryRow(`SELECT COUNT(*) > 0 FROM flashbacks WHERE id = ? AND deleted_at IS NULL`, id).Scan(&exists)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("note %s: %w", id, sql.ErrNoRows)
+		}
+		if err := insertCollections(tx, id, []string{name}); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// AddNoteToCollections adds note id to each of the collections, creating
+// the ones that don't exist.
+func (app *App) AddNoteToCollections(ctx context.Context, id string, names []string) error {
+	tx, err := app.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := insertCollections(tx, id, names); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// insertCollections adds note id to collections, creating missing ones.
+// Blank names are skipped.
+func insertCollections(tx *sql.Tx, id string, names []string) error {
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if _, err := tx.Exec(`INSERT OR IGNORE INTO collections (name) VALUES (?)`, name); err != nil {
+			return err
+		}
+		_, err := tx.Exec(`
+        INSERT OR IGNORE INTO collection_notes (collection_id, flashback_id)
+        SELECT id, ? FROM collections WHERE name = ?
+        `, id, name)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RemoveFromCollection takes notes out of the collection name and returns
+// how many were in it. The notes themselves are kept. It returns
+// sql.ErrNoRows when the collection doesn't exist.
+func (app *App) RemoveFromCollection(ctx context.Context, name string, ids []string) (int, error) {
+	collectionID, err := app.collectionID(ctx, name)
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	for _, id := range ids {
+		res, err := app.DB.ExecContext(ctx, `DELETE FROM collection_notes WHERE collection_id = ? AND flashback_id = ?`, collectionID, id)
+		if err != nil {
+			return removed, err
+		}
+		if n, err := res.RowsAffected(); err == nil {
+			removed += int(n)
+		}
+	}
+	return removed, nil
+}
+
+// DeleteCollection deletes the collection name. Its notes are kept. It
+// returns sql.ErrNoRows when the collection doesn't exist.
+func (app *App) DeleteCollection(ctx context.Context, name string) error {
+	res, err := app.DB.ExecContext(ctx, `DELETE FROM collections WHERE name = ?`, name)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (app *App) collectionID(ctx context.Context, name string) (int64, error) {
+	var id int64
+	err := app.DB.QueryRowContext(ctx, `SELECT id FROM collections WHERE name = ?`, name).Scan(&id)
+	return id, err
+}
@@ -4,116 +4,160 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"strings"
 
 	"github.com/lithammer/shortuuid/v4"
 	"github.com/yagnikpt/flashback/internal/models"
+	"github.com/yagnikpt/flashback/internal/utils"
 )
 
-func (app *App) InsertNote(ctx context.Context, content, dataType string, metadata map[string]string, embeddings []float32) error {
+// InsertNote stores a new note and returns its ID. The generated "tags"
+// metadata value, if any, is stored as AI tags alongside the user's own tags.
+// Without embeddings, a job is queued to generate them, along with the
+// metadata when that is nil too.
+func (app *App) InsertNote(ctx context.Context, content, dataType string, metadata map[string]string, userTags []string, embeddings []float32) (string, error) {
 	id := shortuuid.New()
+	metadata, generatedTags := splitTags(metadata)
 	tx, err := app.DB.BeginTx(ctx, nil)
 	if err != nil {
-		return err
+		return "", err
 	}
 	defer tx.Rollback()
-	insertQuery := `INSERT INTO flashbacks (id, content, type) VALUES (?, ?, ?)`
-	_, err = tx.Exec(insertQuery, id, content, dataType)
+	insertQuery := `INSERT INTO flashbacks (id, content, type, url_key) VALUES (?, ?, ?, ?)`
+	_, err = tx.Exec(insertQuery, id, content, dataType, urlKey(content, dataType))
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	insertMetadataQuery := `INSERT INTO metadata (flashback_id, key, value) VALUES (?, ?, ?)`
 	for key, value := range metadata {
-		_, err := tx.Exec(insertMetadataQuery, id, key, value)
+		err := app.insertGeneratedMetadata(tx, id, key, value)
 		if err != nil {
-			return err
+			return "", err
 		}
 	}
 
-	embeddingsData, err := json.Marshal(embeddings)
+	err = insertTags(tx, id, userTags, TagSourceUser)
 	if err != nil {
-		return err
+		return "", err
 	}
-	insertEmbeddingQuery := `INSERT INTO embeddings (flashback_id, vector) VALUES (?, vector32(?))`
-	_, err = tx.Exec(insertEmbeddingQuery, id, string(embeddingsData))
+	err = insertTags(tx, id, generatedTags, TagSourceAI)
 	if err != nil {
-		return err
+		return "", err
+	}
+
+	if embeddings == nil {
+		kind := JobEmbed
+		if metadata == nil {
+			kind = JobEnrich
+		}
+		err = enqueueJob(tx, id, kind)
+		if err != nil {
+			return "", err
+		}
+	} else {
+		err = app.storeEmbedding(tx, id, embeddings)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	err = recordRevision(tx, id)
+	if err != nil {
+		return "", err
 	}
 
 	err = tx.Commit()
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	return nil
+	return id, nil
 }
 
-func (app *App) RetrieveNotesBySimilarity(ctx context.Context, vector []float32) ([]models.FlashbackWithMetadata, error) {
-	embeddings, err := json.Marshal(vector)
+// RetrieveNotesBySimilarity returns up to limit notes whose embedding or
+// page passages are similar to vector, most similar first. Snippet holds the
+// best passage of the note's page when it was found through one.
+func (app *App) RetrieveNotesBySimilarity(ctx context.Context, vector []float32, limit int) ([]models.ScoredFlashback, error) {
+	_, noteSimilarities, err := app.rankBySimilarity(ctx, vector, NoteFilter{}, minSimilarity, limit)
 	if err != nil {
 		return nil, err
 	}
-
-	query := `
-    SELECT f.id, f.content, f.type, f.created_at, m.key, m.value
-    FROM flashbacks f
-    JOIN embeddings e ON f.id = e.flashback_id
-    LEFT JOIN metadata m ON f.id = m.flashback_id
-    WHERE vector_distance_cos(e.vector, vector32(?)) < 0.40
-    ORDER BY vector_distance_cos(e.vector, vector32(?)) ASC LIMIT 20
-    `
-
-	rows, err := app.DB.QueryContext(ctx, query, string(embeddings), string(embeddings))
+	_, chunkSimilarities, passages, err := app.rankChunksBySimilarity(ctx, vector, NoteFilter{}, minSimilarity, limit)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
-
-	flashbacks := []models.FlashbackWithMetadata{}
-	idIndex := make(map[string]int)
-	for rows.Next() {
-		var id, content, dataType, createdAt string
-		var key, value sql.NullString
-		if err := rows.Scan(&id, &content, &dataType, &createdAt, &key, &value); err != nil {
-			return nil, err
-		}
-
-		idx, exists := idIndex[id]
-		if !exists {
-			idx = len(flashbacks)
-			idIndex[id] = idx
-			flashbacks = append(flashbacks, models.FlashbackWithMetadata{
-				Flashback: models.Flashback{
-					ID:        id,
-					Content:   content,
-					Type:      dataType,
-					CreatedAt: createdAt,
-				},
-				Metadata: make(map[string]string),
-			})
-		}
-		if key.Valid && key.String != "" {
-			flashbacks[idx].Metadata[key.String] = value.String
-		}
+	ids, similarities := mergeSimilarities(noteSimilarities, chunkSimilarities)
+	if len(ids) > limit {
+		ids = ids[:limit]
 	}
 
-	return flashbacks, nil
+	notes, err := app.getNotesByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	scored := make([]models.ScoredFlashback, len(notes))
+	for i, note := range notes {
+		similarity := similarities[note.ID]
+		scored[i] = models.ScoredFlashback{FlashbackWithMetadata: note, Similarity: &similarity, Snippet: passages[note.ID]}
+	}
+	return scored, nil
 }
 
 func (app *App) GetAllNotes(ctx context.Context) ([]models.FlashbackWithMetadata, error) {
+	return app.ListNotes(ctx, NoteFilter{})
+}
+
+// getNotesByIDs loads the given notes with their metadata, preserving the
+// order of ids. Unknown ids are skipped.
+func (app *App) getNotesByIDs(ctx context.Context, ids []string) ([]models.FlashbackWithMetadata, error) {
+	if len(ids) == 0 {
+		return []models.FlashbackWithMetadata{}, nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", ")
 	query := `
     SELECT f.id, f.content, f.type, f.created_at, m.key, m.value
     FROM flashbacks f
     LEFT JOIN metadata m ON f.id = m.flashback_id
-    ORDER BY f.created_at DESC
+    WHERE f.id IN (` + placeholders + `) AND f.deleted_at IS NULL
     `
 
-	rows, err := app.DB.QueryContext(ctx, query)
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+
+	rows, err := app.DB.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
+	flashbacks, err := scanFlashbacks(rows)
+	if err != nil {
+		return nil, err
+	}
+	err = app.attachTags(ctx, flashbacks)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]models.FlashbackWithMetadata, len(flashbacks))
+	for _, f := range flashbacks {
+		byID[f.ID] = f
+	}
+	ordered := make([]models.FlashbackWithMetadata, 0, len(flashbacks))
+	for _, id := range ids {
+		if f, ok := byID[id]; ok {
+			ordered = append(ordered, f)
+		}
+	}
+	return ordered, nil
+}
+
+// scanFlashbacks folds rows of (id, content, type, created_at, key, value)
+// into one entry per note, in the order the notes first appear.
+func scanFlashbacks(rows *sql.Rows) ([]models.FlashbackWithMetadata, error) {
 	flashbacks := []models.FlashbackWithMetadata{}
 	idIndex := make(map[string]int)
 	for rows.Next() {
@@ -142,7 +186,7 @@ func (app *App) GetAllNotes(ctx context.Context) ([]models.FlashbackWithMetadata
 		}
 	}
 
-	return flashbacks, nil
+	return flashbacks, rows.Err()
 }
 
 func (app *App) GetNoteByID(ctx context.Context, id string) (models.FlashbackWithMetadata, error) {
@@ -150,7 +194,7 @@ func (app *App) GetNoteByID(ctx context.Context, id string) (models.FlashbackWit
     SELECT f.id, f.content, f.type, f.created_at, m.key, m.value
     FROM flashbacks f
     LEFT JOIN metadata m ON f.id = m.flashback_id
-    WHERE f.id = ?
+    WHERE f.id = ? AND f.deleted_at IS NULL
     `
 
 	rows, err := app.DB.QueryContext(ctx, query, id)
@@ -178,14 +222,226 @@ func (app *App) GetNoteByID(ctx context.Context, id string) (models.FlashbackWit
 		return models.FlashbackWithMetadata{}, sql.ErrNoRows
 	}
 
-	return flashback, nil
+	notes := []models.FlashbackWithMetadata{flashback}
+	err = app.attachTags(ctx, notes)
+	if err != nil {
+		return models.FlashbackWithMetadata{}, err
+	}
+
+	return notes[0], nil
 }
 
+// DeleteNoteByID moves a note to the trash. It returns sql.ErrNoRows when the
+// note doesn't exist or is already in the trash.
 func (app *App) DeleteNoteByID(ctx context.Context, id string) error {
-	deleteQuery := `DELETE FROM flashbacks WHERE id = ?`
-	_, err := app.DB.ExecContext(ctx, deleteQuery, id)
+	deleteQuery := `UPDATE flashbacks SET deleted_at = CURRENT_TIMESTAMP WHERE id = ? AND deleted_at IS NULL`
+	res, err := app.DB.ExecContext(ctx, deleteQuery, id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// NoteUpdate describes the new state of an edited note. UserMetadata and
+// UserTags replace everything the user owns. Generated and GeneratedTags,
+// when non-nil, replace the generated values; Embeddings, when non-nil,
+// replaces the stored vector.
+//
+// Generated values are stored as produced by the configured model and
+// prompts only when Regenerated is set. Otherwise Generated holds the stored
+// values the edit left unchanged, which keep their provenance, and the
+// others are dropped. Imported values are kept unless the edit changed or
+// dropped them.
+type NoteUpdate struct {
+	Content       string
+	UserMetadata  map[string]string
+	Generated     map[string]string
+	Regenerated   bool
+	UserTags      []string
+	GeneratedTags []string
+	Embeddings    []float32
+}
+
+// UpdateNote changes a note while keeping its ID and created_at.
+func (app *App) UpdateNote(ctx context.Context, id string, update NoteUpdate) error {
+	tx, err := app.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = recordRevision(tx, id)
+	if err != nil {
+		return err
+	}
+
+	res, err := tx.Exec(`
+    UPDATE flashbacks SET content = ?, url_key = CASE WHEN type = 'url' THEN ? END WHERE id = ?
+    `, update.Content, utils.URLKey(update.Content), id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return sql.ErrNoRows
+	}
+
+	_, err = tx.Exec(`DELETE FROM metadata WHERE flashback_id = ? AND source = 'user'`, id)
+	if err != nil {
+		return err
+	}
+	for key, value := range update.UserMetadata {
+		_, err := tx.Exec(`INSERT INTO metadata (flashback_id, key, value, source) VALUES (?, ?, ?, 'user')`, id, key, value)
+		if err != nil {
+			return err
+		}
+	}
+
+	if update.Generated != nil {
+		err = dropEditedMetadata(tx, id, update)
+		if err != nil {
+			return err
+		}
+	}
+	if update.Regenerated {
+		_, err = tx.Exec(`DELETE FROM metadata WHERE flashback_id = ? AND source NOT IN ('user', 'import')`, id)
+		if err != nil {
+			return err
+		}
+		kept, err := keptMetadataKeys(tx, id)
+		if err != nil {
+			return err
+		}
+		for key, value := range update.Generated {
+			if kept[key] {
+				continue
+			}
+			err := app.insertGeneratedMetadata(tx, id, key, value)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	_, err = tx.Exec(`DELETE FROM flashback_tags WHERE flashback_id = ? AND source = 'user'`, id)
 	if err != nil {
 		return err
 	}
+	if update.GeneratedTags != nil {
+		_, err = tx.Exec(`DELETE FROM flashback_tags WHERE flashback_id = ?`, id)
+		if err != nil {
+			return err
+		}
+	}
+	err = insertTags(tx, id, update.UserTags, TagSourceUser)
+	if err != nil {
+		return err
+	}
+	err = insertTags(tx, id, update.GeneratedTags, TagSourceAI)
+	if err != nil {
+		return err
+	}
+
+	if update.Embeddings != nil {
+		err = app.storeEmbedding(tx, id, update.Embeddings)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = recordRevision(tx, id)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// dropEditedMetadata deletes the generated and imported values of note id
+// that the user took over or that the edit left out of update.Generated.
+func dropEditedMetadata(tx *sql.Tx, id string, update NoteUpdate) error {
+	rows, err := tx.Query(`SELECT id, key FROM metadata WHERE flashback_id = ? AND COALESCE(source, 'system') != 'user'`, id)
+	if err != nil {
+		return err
+	}
+	var dropped []int64
+	for rows.Next() {
+		var rowID int64
+		var key string
+		if err := rows.Scan(&rowID, &key); err != nil {
+			rows.Close()
+			return err
+		}
+		_, owned := update.UserMetadata[key]
+		_, kept := update.Generated[key]
+		if owned || !kept {
+			dropped = append(dropped, rowID)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, rowID := range dropped {
+		if _, err := tx.Exec(`DELETE FROM metadata WHERE id = ?`, rowID); err != nil {
+			return err
+		}
+	}
 	return nil
 }
+
+// GetUserMetadata returns the metadata of a note that was written by the user
+// rather than generated.
+func (app *App) GetUserMetadata(ctx context.Context, id string) (map[string]string, error) {
+	rows, err := app.DB.QueryContext(ctx, `SELECT key, value FROM metadata WHERE flashback_id = ? AND source = 'user'`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	metadata := make(map[string]string)
+	for rows.Next() {
+		var key string
+		var value sql.NullString
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, err
+		}
+		metadata[key] = value.String
+	}
+	return metadata, rows.Err()
+}
+
+// insertGeneratedMetadata stores a generated metadata value along with the
+// model and prompt version that produced it.
+func (app *App) insertGeneratedMetadata(tx *sql.Tx, id, key, value string) error {
+	_, err := tx.Exec(`
+    INSERT INTO metadata (flashback_id, key, value, model, prompt_version) VALUES (?, ?, ?, ?, ?)
+    `, id, key, value, app.Enricher.GenerationModel(), utils.PromptVersion)
+	return err
+}
+
+// storeEmbedding replaces the embedding of a note with one generated by the
+// configured embedder.
+func (app *App) storeEmbedding(tx *sql.Tx, id string, vector []float32) error {
+	return replaceEmbedding(tx, id, vector, app.Embedder.EmbeddingModel(), documentVersion)
+}
+
+// replaceEmbedding replaces the embedding of a note. An empty model and a
+// zero version are stored as unknown.
+func replaceEmbedding(tx *sql.Tx, id string, vector []float32, model string, version int) error {
+	data, err := json.Marshal(vector)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`DELETE FROM embeddings WHERE flashback_id = ?`, id)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`
+    INSERT INTO embeddings (flashback_id, vector, model, version, dimension) VALUES (?, vector32(?), ?, ?, ?)
+    `, id, string(data), sql.NullString{String: model, Valid: model != ""}, sql.NullInt64{Int64: int64(version), Valid: version != 0}, len(vector))
+	return err
+}
@@ -1,30 +1,33 @@
 package app
 
 import (
-	"context"
 	"database/sql"
 
 	"github.com/yagnikpt/flashback/internal/config"
-	"google.golang.org/genai"
+	"github.com/yagnikpt/flashback/internal/providers"
 )
 
 type App struct {
-	DB     *sql.DB
-	Gemini *genai.Client
-	Config config.Config
+	DB        *sql.DB
+	Enricher  providers.Enricher
+	Generator providers.Generator
+	Embedder  providers.Embedder
+	Config    config.Config
+
+	vectorIndex vectorIndex
 }
 
-func NewApp(db *sql.DB, config config.Config) *App {
-	client, err := genai.NewClient(context.Background(), &genai.ClientConfig{
-		APIKey: config.APIKey,
-	})
+func NewApp(db *sql.DB, config config.Config) (*App, error) {
+	provider, err := providers.New(config)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
 	return &App{
-		DB:     db,
-		Gemini: client,
-		Config: config,
-	}
+		DB:        db,
+		Enricher:  provider,
+		Generator: provider,
+		Embedder:  provider,
+		Config:    config,
+	}, nil
 }
@@ -0,0 +1,461 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/yagnikpt/flashback/internal/models"
+	"github.com/yagnikpt/flashback/internal/utils"
+)
+
+const (
+	// DuplicateSimilarity is the cosine similarity from which the
+	// embeddings of two notes are taken to mean the same thing.
+	DuplicateSimilarity = 0.95
+	// duplicateNeighbours is the number of neighbours of each note checked
+	// for duplicates.
+	duplicateNeighbours = 10
+)
+
+// Duplicate is a stored note that duplicates another one. Exact is set when
+// both have the same text or point to the same page; otherwise Similarity is
+// the similarity of their embeddings.
+type Duplicate struct {
+	Note       models.FlashbackWithMetadata
+	Exact      bool
+	Similarity float64
+}
+
+// Match describes how d duplicates the other note.
+func (d Duplicate) Match() string {
+	if d.Exact {
+		return "same URL or text"
+	}
+	return fmt.Sprintf("%.0f%% similar", d.Similarity*100)
+}
+
+// FindDuplicates returns the stored notes that a new note with content would
+// duplicate: notes with the same URL or text, then, for text notes, notes
+// whose embedding has at least threshold similarity with it. When content
+// can't be embedded only exact duplicates are returned.
+func (app *App) FindDuplicates(ctx context.Context, content, noteType string, threshold float64) ([]Duplicate, error) {
+	content = strings.TrimSpace(content)
+	var rows *sql.Rows
+	var err error
+	if noteType == "url" {
+		if err := app.fillURLKeys(ctx); err != nil {
+			return nil, err
+		}
+		rows, err = app.DB.QueryContext(ctx, `SELECT id FROM flashbacks WHERE url_key = ? AND deleted_at IS NULL ORDER BY created_at`, utils.URLKey(content))
+	} else {
+		rows, err = app.DB.QueryContext(ctx, `SELECT id FROM flashbacks WHERE content = ? AND deleted_at IS NULL ORDER BY created_at`, content)
+	}
+	if err != nil {
+		return nil, err
+	}
+	exact, err := scanStrings(rows)
+	rows.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	similarities := map[string]float64{}
+	ids := slices.Clone(exact)
+	if noteType != "url" {
+		embedCtx, cancel := context.WithTimeout(ctx, embeddingTimeout)
+		vector, err := app.GenerateDocumentEmbedding(embedCtx, content, nil, nil)
+		cancel()
+		if err != nil {
+			log.Println("Checking for exact duplicates only:", err)
+		} else {
+			var similar []string
+			similar, similarities, err = app.rankBySimilarity(ctx, vector, NoteFilter{}, threshold, duplicateNeighbours)
+			if err != nil {
+				return nil, err
+			}
+			for _, id := range similar {
+				if !slices.Contains(ids, id) {
+					ids = append(ids, id)
+				}
+			}
+		}
+	}
+
+	notes, err := app.getNotesByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	duplicates := make([]Duplicate, len(notes))
+	for i, note := range notes {
+		duplicates[i] = Duplicate{Note: note, Exact: slices.Contains(exact, note.ID), Similarity: similarities[note.ID]}
+		if duplicates[i].Exact {
+			duplicates[i].Similarity = 1
+		}
+	}
+	return duplicates, nil
+}
+
+// urlKey is the url_key column of a note with content of noteType: the
+// utils.URLKey of URL notes, and NULL for others.
+func urlKey(content, noteType string) sql.NullString {
+	return sql.NullString{String: utils.URLKey(content), Valid: noteType == "url"}
+}
+
+// fillURLKeys sets url_key for URL notes saved before it was stored.
+func (app *App) fillURLKeys(ctx context.Context) error {
+	type missing struct{ id, content string }
+	var notes []missing
+	err := eachRow(ctx, app.DB, `SELECT id, content FROM flashbacks WHERE url_key IS NULL AND type = 'url'`, nil, func(rows *sql.Rows) error {
+		var m missing
+		if err := rows.Scan(&m.id, &m.content); err != nil {
+			return err
+		}
+		notes = append(notes, m)
+		return nil
+	})
+	if err != nil || len(notes) == 0 {
+		return err
+	}
+
+	tx, err := app.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for _, m := range notes {
+		if _, err := tx.Exec(`UPDATE flashbacks SET url_key = ? WHERE id = ?`, utils.URLKey(m.content), m.id); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// DuplicateCluster is a group of notes that duplicate each other.
+type DuplicateCluster struct {
+	// Keep is the note the others are merged into: the first one that has
+	// generated metadata, oldest first.
+	Keep       models.FlashbackWithMetadata
+	Duplicates []Duplicate
+}
+
+// FindDuplicateClusters groups the stored notes that duplicate each other,
+// by URL and text and, when semantic is set, by embedding similarity of at
+// least threshold. Clusters are ordered by the creation time of the note
+// kept.
+func (app *App) FindDuplicateClusters(ctx context.Context, threshold float64, semantic bool, report func(string)) ([]DuplicateCluster, error) {
+	report("Comparing URLs and texts...")
+	clusters := newDisjointSet()
+	exact := map[string]bool{}
+	similarities := map[string]float64{}
+
+	firstByKey := map[string]string{}
+	err := eachRow(ctx, app.DB, `SELECT id, content, type FROM flashbacks WHERE deleted_at IS NULL ORDER BY created_at`, nil, func(rows *sql.Rows) error {
+		var id, content, noteType string
+		if err := rows.Scan(&id, &content, &noteType); err != nil {
+			return err
+		}
+		key := "text:" + strings.Join(strings.Fields(content), " ")
+		if noteType == "url" {
+			key = "url:" + utils.URLKey(content)
+		}
+		clusters.add(id)
+		if first, ok := firstByKey[key]; ok {
+			clusters.union(first, id)
+			exact[first], exact[id] = true, true
+		} else {
+			firstByKey[key] = id
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if semantic {
+		vectors := map[string][]float32{}
+		err := eachRow(ctx, app.DB, `
+        SELECT flashback_id, vector_extract(vector) FROM embeddings WHERE model = ?
+        `, []any{app.Embedder.EmbeddingModel()}, func(rows *sql.Rows) error {
+			var id, data string
+			if err := rows.Scan(&id, &data); err != nil {
+				return err
+			}
+			var vector []float32
+			if err := json.Unmarshal([]byte(data), &vector); err != nil {
+				return err
+			}
+			vectors[id] = vector
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		done := 0
+		for id, vector := range vectors {
+			done++
+			if done%100 == 1 {
+				report(fmt.Sprintf("Comparing embeddings (%d/%d)...", done, len(vectors)))
+			}
+			neighbours, scores, err := app.rankBySimilarity(ctx, vector, NoteFilter{}, threshold, duplicateNeighbours)
+			if err != nil {
+				return nil, err
+			}
+			for _, neighbour := range neighbours {
+				if neighbour == id {
+					continue
+				}
+				clusters.union(id, neighbour)
+				similarities[id] = max(similarities[id], scores[neighbour])
+				similarities[neighbour] = max(similarities[neighbour], scores[neighbour])
+			}
+		}
+	}
+
+	var result []DuplicateCluster
+	for _, ids := range clusters.groups() {
+		notes, err := app.getNotesByIDs(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		if len(notes) < 2 {
+			continue
+		}
+		sortByAge(notes)
+		keep := 0
+		for i, note := range notes {
+			if len(note.Metadata) > 0 {
+				keep = i
+				break
+			}
+		}
+
+		cluster := DuplicateCluster{Keep: notes[keep]}
+		for i, note := range notes {
+			if i == keep {
+				continue
+			}
+			duplicate := Duplicate{Note: note, Exact: exact[note.ID], Similarity: similarities[note.ID]}
+			if duplicate.Exact {
+				duplicate.Similarity = 1
+			}
+			cluster.Duplicates = append(cluster.Duplicates, duplicate)
+		}
+		result = append(result, cluster)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return olderThan(result[i].Keep.CreatedAt, result[j].Keep.CreatedAt)
+	})
+	return result, nil
+}
+
+// MergeNotes merges notes ids into note keep and deletes them. The merged
+// note keeps its content and gets the union of the tags, the collections,
+// the user's metadata it doesn't have yet, the links and the oldest creation
+// time of all of them. Saved pages, the review schedule and the choice to
+// include or exclude the note from review are moved over when keep has
+// none, from the first of ids that has them.
+func (app *App) MergeNotes(ctx context.Context, keep string, ids []string) error {
+	tx, err := app.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var createdAt string
+	err = tx.QueryRowContext(ctx, `SELECT created_at FROM flashbacks WHERE id = ?`, keep).Scan(&createdAt)
+	if err != nil {
+		return err
+	}
+	if err := recordRevision(tx, keep); err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		if id == keep {
+			continue
+		}
+		var otherCreatedAt string
+		err := tx.QueryRowContext(ctx, `SELECT created_at FROM flashbacks WHERE id = ?`, id).Scan(&otherCreatedAt)
+		if err != nil {
+			return err
+		}
+		if olderThan(otherCreatedAt, createdAt) {
+			createdAt = otherCreatedAt
+		}
+
+		statements := []struct {
+			query string
+			args  []any
+		}{
+			{`INSERT INTO flashback_tags (flashback_id, tag_id, source)
+            SELECT ?, tag_id, source FROM flashback_tags WHERE flashback_id = ?
+            ON CONFLICT (flashback_id, tag_id) DO UPDATE SET source = CASE
+                WHEN excluded.source = 'user' THEN 'user' ELSE flashback_tags.source
+            END`, []any{keep, id}},
+			{`INSERT INTO metadata (flashback_id, key, value, source)
+            SELECT ?, key, value, source FROM metadata
+            WHERE flashback_id = ? AND source = 'user'
+                AND key NOT IN (SELECT key FROM metadata WHERE flashback_id = ? AND source = 'user')`, []any{keep, id, keep}},
+			{`INSERT OR IGNORE INTO links (source_id, target_id, created_at)
+            SELECT min(?, other), max(?, other), created_at FROM (
+                SELECT CASE WHEN source_id = ? THEN target_id ELSE source_id END AS other, created_at
+                FROM links WHERE source_id = ? OR target_id = ?
+            ) WHERE other != ?`, []any{keep, keep, id, id, id, keep}},
+			{`UPDATE snapshots SET flashback_id = ?
+            WHERE flashback_id = ? AND NOT EXISTS (SELECT 1 FROM snapshots WHERE flashback_id = ?)`, []any{keep, id, keep}},
+			{`INSERT OR IGNORE INTO collection_notes (collection_id, flashback_id)
+            SELECT collection_id, ? FROM collection_notes WHERE flashback_id = ?`, []any{keep, id}},
+			{`INSERT OR IGNORE INTO reviews (flashback_id, ease, interval_days, repetitions, lapses, due_at, reviewed_at, first_reviewed_at)
+            SELECT ?, ease, interval_days, repetitions, lapses, due_at, reviewed_at, first_reviewed_at
+            FROM reviews WHERE flashback_id = ?`, []any{keep, id}},
+			{`INSERT OR IGNORE INTO review_notes (flashback_id, included)
+            SELECT ?, included FROM review_notes WHERE flashback_id = ?`, []any{keep, id}},
+			{`DELETE FROM flashbacks WHERE id = ?`, []any{id}},
+		}
+		for _, statement := range statements {
+			if _, err := tx.ExecContext(ctx, statement.query, statement.args...); err != nil {
+				return err
+			}
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE flashbacks SET created_at = ? WHERE id = ?`, createdAt, keep)
+	if err != nil {
+		return err
+	}
+	if err := recordRevision(tx, keep); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// AddNoteTags adds tags to a note as the user's own.
+func (app *App) AddNoteTags(ctx context.Context, id string, tags []string) error {
+	tx, err := app.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := recordRevision(tx, id); err != nil {
+		return err
+	}
+	if err := insertTags(tx, id, tags, TagSourceUser); err != nil {
+		return err
+	}
+	if err := recordRevision(tx, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ReplaceNoteContent changes the content of a note, adds tags to it and
+// queues a job to generate its metadata and embedding again.
+func (app *App) ReplaceNoteContent(ctx context.Context, id, content string, tags []string) error {
+	tx, err := app.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := recordRevision(tx, id); err != nil {
+		return err
+	}
+	res, err := tx.Exec(`
+    UPDATE flashbacks SET content = ?, url_key = CASE WHEN type = 'url' THEN ? END WHERE id = ?
+    `, content, utils.URLKey(content), id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return sql.ErrNoRows
+	}
+	if err := insertTags(tx, id, tags, TagSourceUser); err != nil {
+		return err
+	}
+	if err := enqueueJob(tx, id, JobEnrich); err != nil {
+		return err
+	}
+	if err := recordRevision(tx, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// olderThan compares two created_at values, which may be in any format
+// utils.ParseTimestamp accepts.
+func olderThan(a, b string) bool {
+	ta, errA := utils.ParseTimestamp(a)
+	tb, errB := utils.ParseTimestamp(b)
+	if errA != nil || errB != nil {
+		return a < b
+	}
+	return ta.Before(tb)
+}
+
+func sortByAge(notes []models.FlashbackWithMetadata) {
+	sort.SliceStable(notes, func(i, j int) bool {
+		return olderThan(notes[i].CreatedAt, notes[j].CreatedAt)
+	})
+}
+
+// disjointSet groups note IDs that were found to be duplicates of each
+// other, directly or through other notes.
+type disjointSet struct {
+	parent map[string]string
+	order  []string
+}
+
+func newDisjointSet() *disjointSet {
+	return &disjointSet{parent: map[string]string{}}
+}
+
+func (d *disjointSet) add(id string) {
+	if _, ok := d.parent[id]; !ok {
+		d.parent[id] = id
+		d.order = append(d.order, id)
+	}
+}
+
+func (d *disjointSet) find(id string) string {
+	d.add(id)
+	for d.parent[id] != id {
+		d.parent[id] = d.parent[d.parent[id]]
+		id = d.parent[id]
+	}
+	return id
+}
+
+func (d *disjointSet) union(a, b string) {
+	rootA, rootB := d.find(a), d.find(b)
+	if rootA != rootB {
+		d.parent[rootB] = rootA
+	}
+}
+
+// groups returns the groups with more than one ID, in the order their first
+// ID was added.
+func (d *disjointSet) groups() [][]string {
+	byRoot := map[string][]string{}
+	var roots []string
+	for _, id := range d.order {
+		root := d.find(id)
+		if _, ok := byRoot[root]; !ok {
+			roots = append(roots, root)
+		}
+		byRoot[root] = append(byRoot[root], id)
+	}
+	var groups [][]string
+	for _, root := range roots {
+		if len(byRoot[root]) > 1 {
+			groups = append(groups, byRoot[root])
+		}
+	}
+	return groups
+}
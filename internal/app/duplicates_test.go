@@ -0,0 +1,152 @@
+package app
+
+import (
+	"context"
+	"testing"
+)
+
+func TestFindDuplicates(t *testing.T) {
+	ctx := context.Background()
+	a := newTestApp(t)
+
+	insert := func(content, noteType string, embedding []float32) string {
+		t.Helper()
+		id, err := a.InsertImportedNote(ctx, ImportedNote{
+			Content:          content,
+			Type:             noteType,
+			Embedding:        embedding,
+			EmbeddingModel:   "test/model",
+			EmbeddingVersion: documentVersion,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return id
+	}
+	page := insert("https://example.com/a", "url", nil)
+	milk := insert("buy milk", "text", []float32{1, 0, 0})
+	groceries := insert("get groceries", "text", []float32{1, 0.1, 0})
+	insert("call the bank", "text", []float32{0, 1, 0})
+	// Notes saved before url_key was stored get theirs on the next check.
+	_, err := a.DB.ExecContext(ctx, `
+    INSERT INTO flashbacks (id, content, type, created_at) VALUES ('legacy', 'http://www.example.com/a/', 'url', '2020-01-01 00:00:00')
+    `)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type match struct {
+		id    string
+		exact bool
+	}
+	tests := []struct {
+		name     string
+		content  string
+		noteType string
+		want     []match
+	}{
+		{"same page", "https://example.com/a?utm_source=feed", "url", []match{{"legacy", true}, {page, true}}},
+		{"other page", "https://example.com/b", "url", nil},
+		{"same text and similar meaning", "buy milk", "text", []match{{milk, true}, {groceries, false}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			duplicates, err := a.FindDuplicates(ctx, tt.content, tt.noteType, DuplicateSimilarity)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []match
+			for _, d := range duplicates {
+				got = append(got, match{d.Note.ID, d.Exact})
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("duplicates = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("duplicates = %v, want %v", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestMergeNotes(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name            string
+		keepGrades      int
+		wantRepetitions int
+	}{
+		{"keep without a schedule", 0, 2},
+		{"keep with a schedule", 1, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t)
+			keep, err := a.InsertImportedNote(ctx, ImportedNote{Content: "buy milk", Type: "text"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			merged, err := a.InsertImportedNote(ctx, ImportedNote{Content: "buy milk", Type: "text"})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := a.AddNoteToCollections(ctx, keep, []string{"errands"}); err != nil {
+				t.Fatal(err)
+			}
+			if err := a.AddNoteToCollections(ctx, merged, []string{"errands", "home"}); err != nil {
+				t.Fatal(err)
+			}
+			for range tt.keepGrades {
+				if _, err := a.GradeNote(ctx, keep, GradeGood); err != nil {
+					t.Fatal(err)
+				}
+			}
+			for range 2 {
+				if _, err := a.GradeNote(ctx, merged, GradeGood); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := a.SetNoteReview(ctx, merged, ReviewExclude); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := a.MergeNotes(ctx, keep, []string{merged}); err != nil {
+				t.Fatal(err)
+			}
+
+			collections, err := a.ListCollections(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, c := range collections {
+				if c.Count != 1 {
+					t.Errorf("collection %s has %d notes, want the kept note only", c.Name, c.Count)
+				}
+			}
+			if len(collections) != 2 {
+				t.Errorf("collections = %+v, want errands and home", collections)
+			}
+
+			var repetitions, included int
+			err = a.DB.QueryRowContext(ctx, `SELECT repetitions FROM reviews WHERE flashback_id = ?`, keep).Scan(&repetitions)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if repetitions != tt.wantRepetitions {
+				t.Errorf("repetitions = %d, want %d", repetitions, tt.wantRepetitions)
+			}
+			err = a.DB.QueryRowContext(ctx, `SELECT included FROM review_notes WHERE flashback_id = ?`, keep).Scan(&included)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if included != 0 {
+				t.Errorf("included = %d, want the note kept out of review", included)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/yagnikpt/flashback/internal/models"
+)
+
+// ApplyNoteEdit saves an edited version of note. Metadata and tags the user
+// changed or added become user-sourced and are never overwritten by
+// generation. When regenerate is set, everything else is generated afresh,
+// except imported values the edit left alone. metadata and tags may be nil
+// to leave them as they are. The embedding is always rebuilt.
+func (app *App) ApplyNoteEdit(ctx context.Context, note models.FlashbackWithMetadata, content string, metadata map[string]string, tags []string, regenerate bool) error {
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return fmt.Errorf("note content cannot be empty")
+	}
+	if metadata == nil {
+		metadata = note.Metadata
+	}
+	if tags == nil {
+		tags = note.Tags
+	}
+
+	userMetadata, err := app.GetUserMetadata(ctx, note.ID)
+	if err != nil {
+		return err
+	}
+	keptMetadata, err := app.GetKeptMetadata(ctx, note.ID)
+	if err != nil {
+		return err
+	}
+	userTags, err := app.GetUserTags(ctx, note.ID)
+	if err != nil {
+		return err
+	}
+
+	owned := make(map[string]string)
+	generated := make(map[string]string)
+	for key, value := range metadata {
+		original, existed := note.Metadata[key]
+		if _, isUser := userMetadata[key]; isUser || !existed || original != value {
+			owned[key] = value
+		} else {
+			generated[key] = value
+		}
+	}
+
+	isUserTag := lowerSet(userTags)
+	isOriginalTag := lowerSet(note.Tags)
+	ownedTags := []string{}
+	generatedTags := []string{}
+	for _, tag := range tags {
+		lower := strings.ToLower(tag)
+		if isUserTag[lower] || !isOriginalTag[lower] {
+			ownedTags = append(ownedTags, tag)
+		} else {
+			generatedTags = append(generatedTags, tag)
+		}
+	}
+
+	if regenerate {
+		fresh, err := app.GenerateMetadata(ctx, content, note.Type)
+		if err != nil {
+			return err
+		}
+		fresh, freshTags := splitTags(fresh)
+
+		unchanged := generated
+		generated = make(map[string]string)
+		for key, value := range fresh {
+			if _, isUser := owned[key]; !isUser {
+				generated[key] = value
+			}
+		}
+		// Imported values the edit left alone are kept over fresh ones.
+		for key, value := range unchanged {
+			_, isUser := userMetadata[key]
+			if _, isKept := keptMetadata[key]; isKept && !isUser {
+				generated[key] = value
+			}
+		}
+
+		isOwnedTag := lowerSet(ownedTags)
+		generatedTags = []string{}
+		for _, tag := range freshTags {
+			if !isOwnedTag[strings.ToLower(tag)] {
+				generatedTags = append(generatedTags, tag)
+			}
+		}
+	}
+
+	merged := make(map[string]string, len(owned)+len(generated))
+	for key, value := range generated {
+		merged[key] = value
+	}
+	for key, value := range owned {
+		merged[key] = value
+	}
+
+	embeddings, err := app.GenerateDocumentEmbedding(ctx, content, merged, append(ownedTags, generatedTags...))
+	if err != nil {
+		return err
+	}
+
+	return app.UpdateNote(ctx, note.ID, NoteUpdate{
+		Content:       content,
+		UserMetadata:  owned,
+		Generated:     generated,
+		Regenerated:   regenerate,
+		UserTags:      ownedTags,
+		GeneratedTags: generatedTags,
+		Embeddings:    embeddings,
+	})
+}
+
+func lowerSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[strings.ToLower(v)] = true
+	}
+	return set
+}
@@ -0,0 +1,63 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/yagnikpt/flashback/internal/models"
+)
+
+func TestApplyNoteEditKeepsProvenance(t *testing.T) {
+	ctx := context.Background()
+	a := newTestApp(t)
+
+	id, err := a.InsertImportedNote(ctx, ImportedNote{
+		Content: "buy milk",
+		Type:    "text",
+		Metadata: []models.ExportedMetadata{
+			{Key: "tldr", Value: "Groceries", Source: "system", Model: "test/old", PromptVersion: 1},
+			{Key: "author", Value: "sam"},
+		},
+		Embedding:      []float32{1, 0, 0},
+		EmbeddingModel: "test/model",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	note, err := a.GetNoteByID(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := a.ApplyNoteEdit(ctx, note, "buy oat milk", nil, nil, false); err != nil {
+		t.Fatal(err)
+	}
+
+	candidates, err := a.NotesNeedingReindex(ctx, NoteFilter{}, ReindexOptions{Metadata: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(candidates) != 1 || candidates[0].ID != id || !candidates[0].NeedsMetadata {
+		t.Errorf("candidates = %+v, want the edited note with stale metadata", candidates)
+	}
+
+	tests := []struct {
+		key, value, source, model string
+	}{
+		{"tldr", "Groceries", "system", "test/old"},
+		{"author", "sam", MetadataSourceImport, ""},
+	}
+	for _, tt := range tests {
+		var value, source string
+		var model sql.NullString
+		err := a.DB.QueryRowContext(ctx, `SELECT value, source, model FROM metadata WHERE flashback_id = ? AND key = ?`, id, tt.key).
+			Scan(&value, &source, &model)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.key, err)
+		}
+		if value != tt.value || source != tt.source || model.String != tt.model {
+			t.Errorf("%s = %q from %s by %q, want %q from %s by %q", tt.key, value, source, model.String, tt.value, tt.source, tt.model)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strings"
+
+	"github.com/yagnikpt/flashback/internal/models"
+)
+
+const exportBatchSize = 500
+
+// ExportNotes returns the notes matching filter, oldest first, with the
+// source of every metadata value and tag. Embeddings are included when
+// withEmbeddings is set.
+func (app *App) ExportNotes(ctx context.Context, filter NoteFilter, withEmbeddings bool) ([]models.ExportedNote, error) {
+	where, args := filter.where("f")
+	rows, err := app.DB.QueryContext(ctx, `
+    SELECT f.id, f.content, f.type, f.created_at
+    FROM flashbacks f
+    WHERE 1 = 1`+where+`
+    ORDER BY f.created_at ASC, f.id
+    `, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notes := []models.ExportedNote{}
+	idIndex := map[string]int{}
+	for rows.Next() {
+		var note models.ExportedNote
+		err := rows.Scan(&note.ID, &note.Content, &note.Type, &note.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		note.Metadata = []models.ExportedMetadata{}
+		note.Tags = []models.ExportedTag{}
+		idIndex[note.ID] = len(notes)
+		notes = append(notes, note)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for start := 0; start < len(notes); start += exportBatchSize {
+		end := min(start+exportBatchSize, len(notes))
+		ids := make([]any, 0, end-start)
+		for _, note := range notes[start:end] {
+			ids = append(ids, note.ID)
+		}
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", ")
+
+		err := eachRow(ctx, app.DB, `
+        SELECT flashback_id, key, COALESCE(value, ''), COALESCE(source, 'system'),
+            COALESCE(model, ''), COALESCE(prompt_version, 0)
+        FROM metadata WHERE flashback_id IN (`+placeholders+`)
+        ORDER BY flashback_id, key
+        `, ids, func(rows *sql.Rows) error {
+			var id string
+			var m models.ExportedMetadata
+			if err := rows.Scan(&id, &m.Key, &m.Value, &m.Source, &m.Model, &m.PromptVersion); err != nil {
+				return err
+			}
+			note := &notes[idIndex[id]]
+			note.Metadata = append(note.Metadata, m)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		err = eachRow(ctx, app.DB, `
+        SELECT ft.flashback_id, t.name, ft.source
+        FROM flashback_tags ft
+        JOIN tags t ON t.id = ft.tag_id
+        WHERE ft.flashback_id IN (`+placeholders+`)
+        ORDER BY t.name COLLATE NOCASE
+        `, ids, func(rows *sql.Rows) error {
+			var id string
+			var t models.ExportedTag
+			if err := rows.Scan(&id, &t.Name, &t.Source); err != nil {
+				return err
+			}
+			note := &notes[idIndex[id]]
+			note.Tags = append(note.Tags, t)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if !withEmbeddings {
+			continue
+		}
+		err = eachRow(ctx, app.DB, `
+        SELECT flashback_id, vector_extract(vector), COALESCE(model, ''), COALESCE(version, 0)
+        FROM embeddings WHERE flashback_id IN (`+placeholders+`)
+        `, ids, func(rows *sql.Rows) error {
+			var id, vector string
+			var model string
+			var version int
+			if err := rows.Scan(&id, &vector, &model, &version); err != nil {
+				return err
+			}
+			note := &notes[idIndex[id]]
+			note.EmbeddingModel, note.EmbeddingVersion = model, version
+			return json.Unmarshal([]byte(vector), &note.Embedding)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return notes, nil
+}
+
+// eachRow runs query and calls fn for every row.
+func eachRow(ctx context.Context, db *sql.DB, query string, args []any, fn func(rows *sql.Rows) error) error {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := fn(rows); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,151 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yagnikpt/flashback/internal/models"
+)
+
+// NoteTypes are the values accepted by type filters.
+var NoteTypes = []string{"url", "text", "command"}
+
+// NoteFilter narrows the notes considered by listing, searching and
+// exporting. Zero values don't filter.
+type NoteFilter struct {
+	Types []string
+	// Tags must all be present on a note.
+	Tags  []string
+	Since time.Time
+	Until time.Time
+	// Collection is the name of a collection notes must belong to.
+	Collection string
+}
+
+func (f NoteFilter) IsZero() bool {
+	return len(f.Types) == 0 && len(f.Tags) == 0 && f.Since.IsZero() && f.Until.IsZero() && f.Collection == ""
+}
+
+// where renders the filter as SQL conditions on the flashbacks table aliased
+// as alias, starting with " AND ". Notes in the trash are always left out.
+func (f NoteFilter) where(alias string) (string, []any) {
+	clauses := []string{alias + ".deleted_at IS NULL"}
+	var args []any
+
+	if len(f.Types) > 0 {
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(f.Types)), ", ")
+		clauses = append(clauses, alias+".type IN ("+placeholders+")")
+		for _, t := range f.Types {
+			args = append(args, t)
+		}
+	}
+
+	for _, tag := range f.Tags {
+		clauses = append(clauses, alias+`.id IN (
+        SELECT ft.flashback_id FROM flashback_tags ft
+        JOIN tags t ON t.id = ft.tag_id WHERE t.name = ?)`)
+		args = append(args, tag)
+	}
+
+	if f.Collection != "" {
+		clauses = append(clauses, alias+`.id IN (
+        SELECT cn.flashback_id FROM collection_notes cn
+        JOIN collections c ON c.id = cn.collection_id WHERE c.name = ?)`)
+		args = append(args, f.Collection)
+	}
+
+	if !f.Since.IsZero() {
+		clauses = append(clauses, "datetime("+alias+".created_at) >= datetime(?)")
+		args = append(args, f.Since.UTC().Format(time.DateTime))
+	}
+	if !f.Until.IsZero() {
+		clauses = append(clauses, "datetime("+alias+".created_at) < datetime(?)")
+		args = append(args, f.Until.UTC().Format(time.DateTime))
+	}
+
+	return " AND " + strings.Join(clauses, " AND "), args
+}
+
+// ListNotes returns the notes matching filter, newest first.
+func (app *App) ListNotes(ctx context.Context, filter NoteFilter) ([]models.FlashbackWithMetadata, error) {
+	where, args := filter.where("f")
+	query := `
+    SELECT f.id, f.content, f.type, f.created_at, m.key, m.value
+    FROM flashbacks f
+    LEFT JOIN metadata m ON f.id = m.flashback_id
+    WHERE 1 = 1` + where + `
+    ORDER BY f.created_at DESC
+    `
+
+	rows, err := app.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	flashbacks, err := scanFlashbacks(rows)
+	if err != nil {
+		return nil, err
+	}
+	return flashbacks, app.attachTags(ctx, flashbacks)
+}
+
+func ParseNoteType(value string) (string, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "link" {
+		value = "url"
+	}
+	for _, t := range NoteTypes {
+		if t == value {
+			return value, nil
+		}
+	}
+	return "", fmt.Errorf("invalid type %q (expected %s)", value, strings.Join(NoteTypes, ", "))
+}
+
+// ParseTime accepts RFC 3339 timestamps, plain dates (2025-01-31), which
+// mean the start of the day, and durations relative to now such as 12h, 7d,
+// 2w, 3mo or 1y. A bare m is rejected as it could mean minutes or months.
+func ParseTime(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation(time.DateOnly, value, time.Local); err == nil {
+		return t, nil
+	}
+
+	unitStart := strings.IndexFunc(value, func(r rune) bool { return r < '0' || r > '9' })
+	if unitStart > 0 {
+		n, err := strconv.Atoi(value[:unitStart])
+		if err == nil {
+			switch value[unitStart:] {
+			case "h":
+				return now.Add(-time.Duration(n) * time.Hour), nil
+			case "d":
+				return now.AddDate(0, 0, -n), nil
+			case "w":
+				return now.AddDate(0, 0, -7*n), nil
+			case "mo":
+				return now.AddDate(0, -n, 0), nil
+			case "y":
+				return now.AddDate(-n, 0, 0), nil
+			}
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid date %q (expected YYYY-MM-DD or a relative value like 12h, 7d, 2w, 3mo or 1y)", value)
+}
+
+// ParseUntil is ParseTime for the end of a range, which filters exclude: a
+// plain date returns the start of the next day, so the day itself is
+// included.
+func ParseUntil(value string, now time.Time) (time.Time, error) {
+	if t, err := time.ParseInLocation(time.DateOnly, strings.TrimSpace(value), time.Local); err == nil {
+		return t.AddDate(0, 0, 1), nil
+	}
+	return ParseTime(value, now)
+}
@@ -0,0 +1,117 @@
+package app
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestParseTime(t *testing.T) {
+	now := time.Date(2025, 3, 15, 12, 0, 0, 0, time.Local)
+	tests := []struct {
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{value: "2025-01-31", want: time.Date(2025, 1, 31, 0, 0, 0, 0, time.Local)},
+		{value: "2025-01-31T08:30:00Z", want: time.Date(2025, 1, 31, 8, 30, 0, 0, time.UTC)},
+		{value: " 12h ", want: now.Add(-12 * time.Hour)},
+		{value: "7d", want: time.Date(2025, 3, 8, 12, 0, 0, 0, time.Local)},
+		{value: "2w", want: time.Date(2025, 3, 1, 12, 0, 0, 0, time.Local)},
+		{value: "3mo", want: time.Date(2024, 12, 15, 12, 0, 0, 0, time.Local)},
+		{value: "1y", want: time.Date(2024, 3, 15, 12, 0, 0, 0, time.Local)},
+		{value: "0d", want: now},
+		{value: "30m", wantErr: true},
+		{value: "d", wantErr: true},
+		{value: "-3d", wantErr: true},
+		{value: "yesterday", wantErr: true},
+		{value: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseTime(tt.value, now)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseTime(%q) = %s, want an error", tt.value, got)
+			}
+			continue
+		}
+		if err != nil || !got.Equal(tt.want) {
+			t.Errorf("ParseTime(%q) = %s, %v; want %s", tt.value, got, err, tt.want)
+		}
+	}
+}
+
+func TestParseUntil(t *testing.T) {
+	now := time.Date(2025, 3, 15, 12, 0, 0, 0, time.Local)
+	tests := []struct {
+		value string
+		want  time.Time
+	}{
+		{"2025-01-31", time.Date(2025, 2, 1, 0, 0, 0, 0, time.Local)},
+		{"2025-12-31", time.Date(2026, 1, 1, 0, 0, 0, 0, time.Local)},
+		{"2025-01-31T08:30:00Z", time.Date(2025, 1, 31, 8, 30, 0, 0, time.UTC)},
+		{"7d", time.Date(2025, 3, 8, 12, 0, 0, 0, time.Local)},
+	}
+	for _, tt := range tests {
+		got, err := ParseUntil(tt.value, now)
+		if err != nil || !got.Equal(tt.want) {
+			t.Errorf("ParseUntil(%q) = %s, %v; want %s", tt.value, got, err, tt.want)
+		}
+	}
+}
+
+func TestParseSearchQuery(t *testing.T) {
+	day := func(year int, month time.Month, d int) time.Time {
+		return time.Date(year, month, d, 0, 0, 0, 0, time.Local)
+	}
+	tests := []struct {
+		input     string
+		wantQuery string
+		wantTypes []string
+		wantTags  []string
+		wantIn    string
+		wantSince time.Time
+		wantUntil time.Time
+		wantErr   bool
+	}{
+		{input: "load balancer", wantQuery: "load balancer"},
+		{
+			input:     "tag:k8s type:url after:2025-01-01 load balancer",
+			wantQuery: "load balancer",
+			wantTypes: []string{"url"},
+			wantTags:  []string{"k8s"},
+			wantSince: day(2025, 1, 1),
+		},
+		{input: "type:link TYPE:command restart", wantQuery: "restart", wantTypes: []string{"url", "command"}},
+		{input: "tag:a tag:b in:homelab proxy", wantQuery: "proxy", wantTags: []string{"a", "b"}, wantIn: "homelab"},
+		{input: "since:2025-01-01 until:2025-01-31", wantSince: day(2025, 1, 1), wantUntil: day(2025, 2, 1)},
+		{input: "before:2025-01-31 notes", wantQuery: "notes", wantUntil: day(2025, 1, 31)},
+		{input: "http://example.com tag: note:", wantQuery: "http://example.com tag: note:"},
+		{input: "type:video", wantErr: true},
+		{input: "after:soon", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			opts, err := ParseSearchQuery(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseSearchQuery(%q) = %+v, want an error", tt.input, opts)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			f := opts.Filter
+			if opts.Query != tt.wantQuery {
+				t.Errorf("query = %q, want %q", opts.Query, tt.wantQuery)
+			}
+			if !slices.Equal(f.Types, tt.wantTypes) || !slices.Equal(f.Tags, tt.wantTags) || f.Collection != tt.wantIn {
+				t.Errorf("types %v, tags %v, in %q; want %v, %v, %q", f.Types, f.Tags, f.Collection, tt.wantTypes, tt.wantTags, tt.wantIn)
+			}
+			if !f.Since.Equal(tt.wantSince) || !f.Until.Equal(tt.wantUntil) {
+				t.Errorf("since %s, until %s; want %s, %s", f.Since, f.Until, tt.wantSince, tt.wantUntil)
+			}
+		})
+	}
+}
@@ -6,34 +6,71 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 
+	"github.com/yagnikpt/flashback/internal/contentloaders"
+	"github.com/yagnikpt/flashback/internal/providers"
 	"github.com/yagnikpt/flashback/internal/utils"
-	"google.golang.org/genai"
 )
 
 func (app *App) GenerateEmbeddingForNote(ctx context.Context, content, taskType string) ([]float32, error) {
-	contents := []*genai.Content{
-		genai.NewContentFromText(content, genai.RoleUser),
-	}
-	result, err := app.Gemini.Models.EmbedContent(ctx,
-		"gemini-embedding-2",
-		contents,
-		&genai.EmbedContentConfig{
-			TaskType:             taskType,
-			OutputDimensionality: genai.Ptr[int32](768),
-		},
-	)
+	vector, err := app.Embedder.Embed(ctx, content, taskType)
+	if err != nil {
+		return nil, err
+	}
+	if dimensions := app.Embedder.EmbeddingDimensions(); dimensions != 0 && len(vector) != dimensions {
+		return nil, &providers.Error{
+			Class: providers.ErrorInvalidInput,
+			Err:   fmt.Errorf("%s returned %d dimensions instead of %d, check embedding_dimensions", app.Embedder.EmbeddingModel(), len(vector), dimensions),
+		}
+	}
+	return vector, nil
+}
+
+// documentVersion identifies the text GenerateDocumentEmbedding embeds. Bump
+// it when changing the layout so that flashback reindex re-embeds old notes.
+const documentVersion = 1
+
+// GenerateDocumentEmbedding embeds a note for storage, combining the user's
+// content with its metadata and tags so all of them are searchable.
+func (app *App) GenerateDocumentEmbedding(ctx context.Context, content string, metadata map[string]string, tags []string) ([]float32, error) {
+	metadata, generatedTags := splitTags(metadata)
+	tags = utils.CleanTags(append(append([]string{}, tags...), generatedTags...))
+
+	var finalContent strings.Builder
+	userInput := fmt.Sprintf("USER INPUT:\n content: %s\n", content)
+	finalContent.WriteString(userInput)
+	finalContent.WriteString("METADATA:\n")
+	for key, value := range metadata {
+		metadataLine := fmt.Sprintf(" %s: %s\n", key, value)
+		finalContent.WriteString(metadataLine)
+	}
+	if len(tags) > 0 {
+		finalContent.WriteString(fmt.Sprintf(" tags: %s\n", strings.Join(tags, ", ")))
+	}
+	return app.GenerateEmbeddingForNote(ctx, finalContent.String(), providers.TaskRetrievalDocument)
+}
+
+// GenerateMetadata runs the metadata generation appropriate for noteType,
+// fetching the page first for URL notes.
+func (app *App) GenerateMetadata(ctx context.Context, content, noteType string) (map[string]string, error) {
+	if noteType != "url" && noteType != "link" {
+		return app.GenerateMetadataForSimpleNote(ctx, content)
+	}
+
+	pageContent, err := contentloaders.GetWebPage(ctx, content)
 	if err != nil {
 		return nil, err
 	}
-	return result.Embeddings[0].Values, nil
+	pageContentWithUrl := fmt.Sprintf("URL: %s\n\n%s", content, pageContent)
+	return app.GenerateMetadataForWebNote(ctx, pageContentWithUrl)
 }
 
 func (app *App) GenerateMetadataForSimpleNote(ctx context.Context, content string) (map[string]string, error) {
-	config := &genai.GenerateContentConfig{
-		SystemInstruction: genai.NewContentFromText(string(utils.SimpleTextExtractionPrompt), genai.RoleUser),
-		ResponseMIMEType:  "application/json",
-		ResponseJsonSchema: map[string]any{
+	req := providers.GenerateRequest{
+		SystemPrompt: utils.SimpleTextExtractionPrompt,
+		Content:      content,
+		Schema: map[string]any{
 			"type": "object",
 			"properties": map[string]any{
 				"tldr": map[string]any{
@@ -49,18 +86,13 @@ func (app *App) GenerateMetadataForSimpleNote(ctx context.Context, content strin
 		},
 	}
 
-	result, err := app.Gemini.Models.GenerateContent(
-		ctx,
-		"gemini-flash-latest",
-		genai.Text(content),
-		config,
-	)
+	result, err := app.Enricher.GenerateJSON(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 
 	res := map[string]string{}
-	err = json.Unmarshal([]byte(result.Text()), &res)
+	err = json.Unmarshal([]byte(result), &res)
 	if err != nil {
 		return nil, fmt.Errorf("error unmarshaling simple note metadata: %w", err)
 	}
@@ -69,10 +101,10 @@ func (app *App) GenerateMetadataForSimpleNote(ctx context.Context, content strin
 }
 
 func (app *App) GenerateMetadataForWebNote(ctx context.Context, content string) (map[string]string, error) {
-	config := &genai.GenerateContentConfig{
-		SystemInstruction: genai.NewContentFromText(string(utils.WebExtractionPrompt), genai.RoleUser),
-		ResponseMIMEType:  "application/json",
-		ResponseJsonSchema: map[string]any{
+	req := providers.GenerateRequest{
+		SystemPrompt: utils.WebExtractionPrompt,
+		Content:      content,
+		Schema: map[string]any{
 			"type": "object",
 			"properties": map[string]any{
 				"image": map[string]any{
@@ -100,27 +132,16 @@ func (app *App) GenerateMetadataForWebNote(ctx context.Context, content string)
 		},
 	}
 
-	result, err := app.Gemini.Models.GenerateContent(
-		ctx,
-		"gemini-flash-latest",
-		genai.Text(content),
-		config,
-	)
+	result, err := app.Enricher.GenerateJSON(ctx, req)
 	if err != nil {
-		errs := err.(genai.APIError)
-		return nil, genai.APIError{
-			Code:    errs.Code,
-			Message: errs.Message,
-			Status:  errs.Status,
-			Details: errs.Details,
-		}
+		return nil, err
 	}
-	if result.Text() == "" {
+	if result == "" {
 		return nil, fmt.Errorf("empty response received from metadata generation")
 	}
 
 	res := map[string]string{}
-	err = json.Unmarshal([]byte(result.Text()), &res)
+	err = json.Unmarshal([]byte(result), &res)
 	if err != nil {
 		return nil, fmt.Errorf("error unmarshaling metadata")
 	}
@@ -130,13 +151,7 @@ func (app *App) GenerateMetadataForWebNote(ctx context.Context, content string)
 	if ok && imageMainOk && imageMain == "true" {
 		imageMetadata, err := app.GenerateMetadataForImage(ctx, image)
 		if err != nil {
-			errs := err.(genai.APIError)
-			return nil, genai.APIError{
-				Code:    errs.Code,
-				Message: errs.Message,
-				Status:  errs.Status,
-				Details: errs.Details,
-			}
+			return nil, err
 		}
 		for k, v := range imageMetadata {
 			if k == "tags" {
@@ -179,10 +194,11 @@ func (app *App) GenerateMetadataForImage(ctx context.Context, imageUrl string) (
 		return nil, err
 	}
 
-	config := &genai.GenerateContentConfig{
-		SystemInstruction: genai.NewContentFromText(string(utils.ImageExtractionPrompt), genai.RoleUser),
-		ResponseMIMEType:  "application/json",
-		ResponseJsonSchema: map[string]any{
+	req := providers.GenerateRequest{
+		SystemPrompt:  utils.ImageExtractionPrompt,
+		Image:         data,
+		ImageMIMEType: "image/jpeg",
+		Schema: map[string]any{
 			"type": "object",
 			"properties": map[string]any{
 				"tldr": map[string]any{
@@ -197,16 +213,12 @@ func (app *App) GenerateMetadataForImage(ctx context.Context, imageUrl string) (
 			"additionalProperties": false,
 		},
 	}
-	parts := []*genai.Part{
-		{InlineData: &genai.Blob{Data: data, MIMEType: "image/jpeg"}},
-	}
-	contents := []*genai.Content{{Parts: parts}}
-	result, err := app.Gemini.Models.GenerateContent(ctx, "gemini-flash-latest", contents, config)
+	result, err := app.Enricher.GenerateJSON(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 	res := map[string]string{}
-	err = json.Unmarshal([]byte(result.Text()), &res)
+	err = json.Unmarshal([]byte(result), &res)
 	if err != nil {
 		return nil, fmt.Errorf("error unmarshaling image metadata: %w", err)
 	}
@@ -0,0 +1,156 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/lithammer/shortuuid/v4"
+	"github.com/yagnikpt/flashback/internal/models"
+)
+
+// MetadataSourceImport marks metadata that came from an import without its
+// original source.
+const MetadataSourceImport = "import"
+
+// ImportedNote is a note read from an export or another tool.
+type ImportedNote struct {
+	Content   string
+	Type      string
+	CreatedAt time.Time
+	Metadata  []models.ExportedMetadata
+	Tags      []models.ExportedTag
+	Embedding []float32
+	// EmbeddingModel and EmbeddingVersion describe how Embedding was made,
+	// when known.
+	EmbeddingModel   string
+	EmbeddingVersion int
+	// Job, when set, is the kind of job to queue for the note.
+	Job string
+}
+
+// ImportProgress records how far the import of a source got.
+type ImportProgress struct {
+	Source      string
+	Fingerprint string
+	Position    int
+	Total       int
+	Imported    int
+	Skipped     int
+	Failed      int
+}
+
+// FindNoteByContent returns the ID of a note with exactly this content, or
+// "" when there is none.
+func (app *App) FindNoteByContent(ctx context.Context, content string) (string, error) {
+	var id string
+	err := app.DB.QueryRowContext(ctx, `SELECT id FROM flashbacks WHERE content = ? AND deleted_at IS NULL LIMIT 1`, strings.TrimSpace(content)).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	return id, err
+}
+
+// InsertImportedNote stores note as-is, keeping its creation time and the
+// source of its metadata and tags. The embedding is optional.
+func (app *App) InsertImportedNote(ctx context.Context, note ImportedNote) (string, error) {
+	id := shortuuid.New()
+	tx, err := app.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	createdAt := note.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+	content := strings.TrimSpace(note.Content)
+	_, err = tx.Exec(`INSERT INTO flashbacks (id, content, type, created_at, url_key) VALUES (?, ?, ?, ?, ?)`,
+		id, content, note.Type, createdAt.UTC().Format(time.DateTime), urlKey(content, note.Type))
+	if err != nil {
+		return "", err
+	}
+
+	for _, m := range note.Metadata {
+		source := m.Source
+		if source == "" {
+			source = MetadataSourceImport
+		}
+		_, err := tx.Exec(`
+        INSERT INTO metadata (flashback_id, key, value, source, model, prompt_version) VALUES (?, ?, ?, ?, ?, ?)
+        `, id, m.Key, m.Value, source,
+			sql.NullString{String: m.Model, Valid: m.Model != ""},
+			sql.NullInt64{Int64: int64(m.PromptVersion), Valid: m.PromptVersion != 0})
+		if err != nil {
+			return "", err
+		}
+	}
+
+	tagsBySource := map[string][]string{}
+	for _, t := range note.Tags {
+		source := t.Source
+		if source != TagSourceAI {
+			source = TagSourceUser
+		}
+		tagsBySource[source] = append(tagsBySource[source], t.Name)
+	}
+	for _, source := range []string{TagSourceUser, TagSourceAI} {
+		if err := insertTags(tx, id, tagsBySource[source], source); err != nil {
+			return "", err
+		}
+	}
+
+	if len(note.Embedding) > 0 {
+		err := replaceEmbedding(tx, id, note.Embedding, note.EmbeddingModel, note.EmbeddingVersion)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if note.Job != "" {
+		if err := enqueueJob(tx, id, note.Job); err != nil {
+			return "", err
+		}
+	}
+
+	if err := recordRevision(tx, id); err != nil {
+		return "", err
+	}
+
+	return id, tx.Commit()
+}
+
+// GetImportProgress returns the saved progress for source. Progress saved
+// for different contents is discarded, so a changed file starts over.
+func (app *App) GetImportProgress(ctx context.Context, source, fingerprint string) (ImportProgress, error) {
+	progress := ImportProgress{Source: source, Fingerprint: fingerprint}
+	var savedFingerprint string
+	err := app.DB.QueryRowContext(ctx, `
+    SELECT fingerprint, position, total, imported, skipped, failed
+    FROM imports WHERE source = ?
+    `, source).Scan(&savedFingerprint, &progress.Position, &progress.Total, &progress.Imported, &progress.Skipped, &progress.Failed)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && savedFingerprint != fingerprint) {
+		return ImportProgress{Source: source, Fingerprint: fingerprint}, nil
+	}
+	return progress, err
+}
+
+func (app *App) SaveImportProgress(ctx context.Context, progress ImportProgress) error {
+	_, err := app.DB.ExecContext(ctx, `
+    INSERT INTO imports (source, fingerprint, position, total, imported, skipped, failed, updated_at)
+    VALUES (?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+    ON CONFLICT (source) DO UPDATE SET
+        fingerprint = excluded.fingerprint,
+        position = excluded.position,
+        total = excluded.total,
+        imported = excluded.imported,
+        skipped = excluded.skipped,
+        failed = excluded.failed,
+        updated_at = excluded.updated_at
+    `, progress.Source, progress.Fingerprint, progress.Position, progress.Total,
+		progress.Imported, progress.Skipped, progress.Failed)
+	return err
+}
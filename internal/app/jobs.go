@@ -0,0 +1,305 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// JobEnrich generates metadata and then the embedding of a note.
+	JobEnrich = "enrich"
+	// JobEmbed only generates the embedding of a note.
+	JobEmbed = "embed"
+)
+
+const (
+	JobPending = "pending"
+	JobRunning = "running"
+	JobFailed  = "failed"
+)
+
+const (
+	// MaxJobAttempts is how often a job runs before it is marked failed.
+	MaxJobAttempts = 5
+	jobRetryDelay  = 30 * time.Second
+	// Running jobs that haven't been updated for this long were abandoned by
+	// a worker that exited.
+	staleJobTimeout = 10 * time.Minute
+)
+
+type Job struct {
+	ID          int64  `json:"id"`
+	FlashbackID string `json:"flashback_id"`
+	Kind        string `json:"kind"`
+	Status      string `json:"status"`
+	Attempts    int    `json:"attempts"`
+	LastError   string `json:"last_error,omitempty"`
+	RunAfter    string `json:"run_after"`
+	CreatedAt   string `json:"created_at"`
+	// Content is the content of the note, for display.
+	Content string `json:"content"`
+}
+
+func enqueueJob(tx *sql.Tx, flashbackID, kind string) error {
+	_, err := tx.Exec(`INSERT INTO jobs (flashback_id, kind) VALUES (?, ?)`, flashbackID, kind)
+	return err
+}
+
+// EnqueueJob schedules kind to run on a note.
+func (app *App) EnqueueJob(ctx context.Context, flashbackID, kind string) error {
+	_, err := app.DB.ExecContext(ctx, `INSERT INTO jobs (flashback_id, kind) VALUES (?, ?)`, flashbackID, kind)
+	return err
+}
+
+// ClaimJob marks the oldest job that is due as running and returns it. ok is
+// false when no job is due.
+func (app *App) ClaimJob(ctx context.Context) (job Job, ok bool, err error) {
+	return app.claimJob(ctx, "", 0)
+}
+
+// ClaimNoteJob is ClaimJob for the jobs of note flashbackID enqueued after
+// job after, such as the job of a note just added (see LastJobID).
+func (app *App) ClaimNoteJob(ctx context.Context, flashbackID string, after int64) (job Job, ok bool, err error) {
+	return app.claimJob(ctx, flashbackID, after)
+}
+
+func (app *App) claimJob(ctx context.Context, flashbackID string, after int64) (job Job, ok bool, err error) {
+	tx, err := app.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return Job{}, false, err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(`
+    SELECT id, flashback_id, kind, attempts FROM jobs
+    WHERE status = 'pending' AND datetime(run_after) <= datetime('now')
+        AND (? = '' OR flashback_id = ?) AND id > ?
+    ORDER BY id LIMIT 1
+    `, flashbackID, flashbackID, after).Scan(&job.ID, &job.FlashbackID, &job.Kind, &job.Attempts)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Job{}, false, nil
+	}
+	if err != nil {
+		return Job{}, false, err
+	}
+
+	_, err = tx.Exec(`
+    UPDATE jobs SET status = 'running', attempts = attempts + 1, updated_at = CURRENT_TIMESTAMP
+    WHERE id = ?
+    `, job.ID)
+	if err != nil {
+		return Job{}, false, err
+	}
+	job.Status = JobRunning
+	job.Attempts++
+	return job, true, tx.Commit()
+}
+
+// LastJobID returns the ID of the newest job, or 0 when there is none. Jobs
+// enqueued later get greater IDs.
+func (app *App) LastJobID(ctx context.Context) (int64, error) {
+	var id int64
+	err := app.DB.QueryRowContext(ctx, `SELECT COALESCE(MAX(id), 0) FROM jobs`).Scan(&id)
+	return id, err
+}
+
+// RunningNoteJob reports whether a worker is running a job of note
+// flashbackID enqueued after job after.
+func (app *App) RunningNoteJob(ctx context.Context, flashbackID string, after int64) (bool, error) {
+	var running bool
+	err := app.DB.QueryRowContext(ctx, `
+    SELECT COUNT(*) > 0 FROM jobs WHERE flashback_id = ? AND id > ? AND status = 'running'
+    `, flashbackID, after).Scan(&running)
+	return running, err
+}
+
+// CompleteJob removes a job that succeeded.
+func (app *App) CompleteJob(ctx context.Context, id int64) error {
+	_, err := app.DB.ExecContext(ctx, `DELETE FROM jobs WHERE id = ?`, id)
+	return err
+}
+
+// FailJob records a failed attempt. The job is retried later with an
+// exponential backoff, or marked failed after MaxJobAttempts attempts or when
+// retry is false.
+func (app *App) FailJob(ctx context.Context, job Job, jobErr error, retry bool) error {
+	if !retry || job.Attempts >= MaxJobAttempts {
+		_, err := app.DB.ExecContext(ctx, `
+        UPDATE jobs SET status = 'failed', last_error = ?, updated_at = CURRENT_TIMESTAMP
+        WHERE id = ?
+        `, jobErr.Error(), job.ID)
+		return err
+	}
+
+	delay := jobRetryDelay << (job.Attempts - 1)
+	_, err := app.DB.ExecContext(ctx, `
+    UPDATE jobs SET status = 'pending', last_error = ?, run_after = datetime('now', ?), updated_at = CURRENT_TIMESTAMP
+    WHERE id = ?
+    `, jobErr.Error(), fmt.Sprintf("+%d seconds", int(delay.Seconds())), job.ID)
+	return err
+}
+
+// ReleaseStaleJobs makes jobs left running by a worker that exited pending
+// again.
+func (app *App) ReleaseStaleJobs(ctx context.Context) error {
+	_, err := app.DB.ExecContext(ctx, `
+    UPDATE jobs SET status = 'pending'
+    WHERE status = 'running' AND datetime(updated_at) <= datetime('now', ?)
+    `, fmt.Sprintf("-%d seconds", int(staleJobTimeout.Seconds())))
+	return err
+}
+
+// ListJobs returns the jobs with one of statuses, or all jobs when none are
+// given, oldest first.
+func (app *App) ListJobs(ctx context.Context, statuses ...string) ([]Job, error) {
+	query := `
+    SELECT j.id, j.flashback_id, j.kind, j.status, j.attempts, COALESCE(j.last_error, ''),
+        j.run_after, j.created_at, f.content
+    FROM jobs j
+    JOIN flashbacks f ON f.id = j.flashback_id`
+	var args []any
+	if len(statuses) > 0 {
+		query += ` WHERE j.status IN (` + strings.TrimSuffix(strings.Repeat("?, ", len(statuses)), ", ") + `)`
+		for _, s := range statuses {
+			args = append(args, s)
+		}
+	}
+	query += ` ORDER BY j.id`
+
+	rows, err := app.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	jobs := []Job{}
+	for rows.Next() {
+		var job Job
+		err := rows.Scan(&job.ID, &job.FlashbackID, &job.Kind, &job.Status, &job.Attempts, &job.LastError,
+			&job.RunAfter, &job.CreatedAt, &job.Content)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, rows.Err()
+}
+
+// RetryJobs makes failed jobs pending again with fresh attempts, and makes
+// pending jobs due now. With no ids, every failed and pending job is
+// retried. It returns the number of jobs changed.
+func (app *App) RetryJobs(ctx context.Context, ids ...int64) (int, error) {
+	query := `
+    UPDATE jobs SET status = 'pending', attempts = 0, run_after = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+    WHERE status IN ('pending', 'failed')`
+	var args []any
+	if len(ids) > 0 {
+		query += ` AND id IN (` + strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", ") + `)`
+		for _, id := range ids {
+			args = append(args, id)
+		}
+	}
+
+	res, err := app.DB.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+// CountPendingJobs returns the number of jobs waiting or running.
+func (app *App) CountPendingJobs(ctx context.Context) (int, error) {
+	var n int
+	err := app.DB.QueryRowContext(ctx, `SELECT COUNT(*) FROM jobs WHERE status IN ('pending', 'running')`).Scan(&n)
+	return n, err
+}
+
+// StoreEnrichment saves generated metadata and the embedding of a note.
+// Metadata from the user or an import is kept; other metadata and the AI
+// tags are replaced when generated is non-nil.
+func (app *App) StoreEnrichment(ctx context.Context, id string, generated map[string]string, embeddings []float32) error {
+	tx, err := app.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if generated != nil {
+		generated, generatedTags := splitTags(generated)
+		_, err = tx.Exec(`DELETE FROM metadata WHERE flashback_id = ? AND source NOT IN ('user', 'import')`, id)
+		if err != nil {
+			return err
+		}
+		kept, err := keptMetadataKeys(tx, id)
+		if err != nil {
+			return err
+		}
+		for key, value := range generated {
+			if kept[key] {
+				continue
+			}
+			if err := app.insertGeneratedMetadata(tx, id, key, value); err != nil {
+				return err
+			}
+		}
+		_, err = tx.Exec(`DELETE FROM flashback_tags WHERE flashback_id = ? AND source = 'ai'`, id)
+		if err != nil {
+			return err
+		}
+		if err := insertTags(tx, id, generatedTags, TagSourceAI); err != nil {
+			return err
+		}
+	}
+
+	if err := app.storeEmbedding(tx, id, embeddings); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetKeptMetadata returns the metadata of a note that enrichment keeps:
+// values from the user or an import.
+func (app *App) GetKeptMetadata(ctx context.Context, id string) (map[string]string, error) {
+	rows, err := app.DB.QueryContext(ctx, `
+    SELECT key, COALESCE(value, '') FROM metadata
+    WHERE flashback_id = ? AND source IN ('user', 'import')
+    `, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	metadata := make(map[string]string)
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, err
+		}
+		metadata[key] = value
+	}
+	return metadata, rows.Err()
+}
+
+func keptMetadataKeys(tx *sql.Tx, id string) (map[string]bool, error) {
+	rows, err := tx.Query(`SELECT key FROM metadata WHERE flashback_id = ? AND source IN ('user', 'import')`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := make(map[string]bool)
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, err
+		}
+		keys[key] = true
+	}
+	return keys, rows.Err()
+}
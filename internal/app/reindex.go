@@ -0,0 +1,82 @@
+package app
+
+import (
+	"context"
+
+	"github.com/yagnikpt/flashback/internal/utils"
+)
+
+type ReindexOptions struct {
+	// Metadata and Embeddings select what to regenerate.
+	Metadata   bool
+	Embeddings bool
+	// Force includes notes whose values are already current.
+	Force bool
+}
+
+// ReindexCandidate is a note with metadata or an embedding that wasn't
+// produced by the configured models and prompts.
+type ReindexCandidate struct {
+	ID             string
+	Content        string
+	Type           string
+	NeedsMetadata  bool
+	NeedsEmbedding bool
+}
+
+// NotesNeedingReindex returns the notes matching filter, oldest first, whose
+// generated metadata or embedding is missing or was produced by another
+// model, prompt version, document version or dimension. Page passages
+// count as part of the embedding. Regenerating metadata also
+// regenerates the embedding, which is built from it.
+func (app *App) NotesNeedingReindex(ctx context.Context, filter NoteFilter, opts ReindexOptions) ([]ReindexCandidate, error) {
+	where, args := filter.where("f")
+	args = append([]any{
+		app.Enricher.GenerationModel(), utils.PromptVersion,
+		app.Embedder.EmbeddingModel(), documentVersion, app.Embedder.EmbeddingDimensions(),
+		app.Embedder.EmbeddingModel(), app.Embedder.EmbeddingDimensions(),
+	}, args...)
+
+	rows, err := app.DB.QueryContext(ctx, `
+    SELECT f.id, f.content, f.type,
+        NOT EXISTS (
+            SELECT 1 FROM metadata m
+            WHERE m.flashback_id = f.id AND COALESCE(m.source, 'system') NOT IN ('user', 'import')
+        ) OR EXISTS (
+            SELECT 1 FROM metadata m
+            WHERE m.flashback_id = f.id AND COALESCE(m.source, 'system') NOT IN ('user', 'import')
+                AND (m.model IS NOT ? OR m.prompt_version IS NOT ?)
+        ),
+        NOT EXISTS (
+            SELECT 1 FROM embeddings e
+            WHERE e.flashback_id = f.id AND e.model IS ? AND e.version IS ?
+                AND e.dimension = COALESCE(NULLIF(?, 0), e.dimension)
+        ) OR EXISTS (
+            SELECT 1 FROM chunks c
+            WHERE c.flashback_id = f.id
+                AND (c.model IS NOT ? OR c.dimension != COALESCE(NULLIF(?, 0), c.dimension))
+        )
+    FROM flashbacks f
+    WHERE 1 = 1`+where+`
+    ORDER BY f.created_at ASC, f.id
+    `, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	candidates := []ReindexCandidate{}
+	for rows.Next() {
+		var c ReindexCandidate
+		var staleMetadata, staleEmbedding bool
+		if err := rows.Scan(&c.ID, &c.Content, &c.Type, &staleMetadata, &staleEmbedding); err != nil {
+			return nil, err
+		}
+		c.NeedsMetadata = opts.Metadata && (opts.Force || staleMetadata)
+		c.NeedsEmbedding = c.NeedsMetadata || opts.Embeddings && (opts.Force || staleEmbedding)
+		if c.NeedsMetadata || c.NeedsEmbedding {
+			candidates = append(candidates, c)
+		}
+	}
+	return candidates, rows.Err()
+}
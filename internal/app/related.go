@@ -0,0 +1,128 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"github.com/yagnikpt/flashback/internal/models"
+)
+
+// RelatedLimit is the number of similar notes shown with a note by default.
+const RelatedLimit = 5
+
+// ErrSelfLink is returned when linking a note to itself.
+var ErrSelfLink = errors.New("a note can't be linked to itself")
+
+// GetRelatedNotes returns up to k notes whose embedding is closest to the
+// embedding of note id, most similar first. It returns no notes when the note
+// has no embedding from the configured model yet, and sql.ErrNoRows when the
+// note doesn't exist.
+func (app *App) GetRelatedNotes(ctx context.Context, id string, k int) ([]models.ScoredFlashback, error) {
+	var exists bool
+	err := app.DB.QueryRowContext(ctx, `SELECT COUNT(*) > 0 FROM flashbacks WHERE id = ? AND deleted_at IS NULL`, id).Scan(&exists)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, sql.ErrNoRows
+	}
+
+	var data string
+	err = app.DB.QueryRowContext(ctx, `
+    SELECT vector_extract(vector) FROM embeddings WHERE flashback_id = ? AND model = ?
+    `, id, app.Embedder.EmbeddingModel()).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return []models.ScoredFlashback{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var vector []float32
+	if err := json.Unmarshal([]byte(data), &vector); err != nil {
+		return nil, err
+	}
+
+	ids, similarities, err := app.rankBySimilarity(ctx, vector, NoteFilter{}, minSimilarity, k+1)
+	if err != nil {
+		return nil, err
+	}
+	neighbours := make([]string, 0, k)
+	for _, neighbour := range ids {
+		if neighbour != id && len(neighbours) < k {
+			neighbours = append(neighbours, neighbour)
+		}
+	}
+
+	notes, err := app.getNotesByIDs(ctx, neighbours)
+	if err != nil {
+		return nil, err
+	}
+	scored := make([]models.ScoredFlashback, len(notes))
+	for i, note := range notes {
+		similarity := similarities[note.ID]
+		scored[i] = models.ScoredFlashback{FlashbackWithMetadata: note, Similarity: &similarity}
+	}
+	return scored, nil
+}
+
+// GetLinkedNotes returns the notes the user linked to note id, in the order
+// they were linked.
+func (app *App) GetLinkedNotes(ctx context.Context, id string) ([]models.FlashbackWithMetadata, error) {
+	rows, err := app.DB.QueryContext(ctx, `
+    SELECT CASE WHEN source_id = ? THEN target_id ELSE source_id END
+    FROM links WHERE source_id = ? OR target_id = ?
+    ORDER BY created_at, rowid
+    `, id, id, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	ids, err := scanStrings(rows)
+	if err != nil {
+		return nil, err
+	}
+	return app.getNotesByIDs(ctx, ids)
+}
+
+// LinkNotes links two notes. Linking notes that are already linked does
+// nothing. It returns sql.ErrNoRows when either note doesn't exist.
+func (app *App) LinkNotes(ctx context.Context, a, b string) error {
+	if a == b {
+		return ErrSelfLink
+	}
+	a, b = min(a, b), max(a, b)
+	res, err := app.DB.ExecContext(ctx, `
+    INSERT OR IGNORE INTO links (source_id, target_id)
+    SELECT ?, ? WHERE (SELECT COUNT(*) FROM flashbacks WHERE id IN (?, ?) AND deleted_at IS NULL) = 2
+    `, a, b, a, b)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		var linked bool
+		err := app.DB.QueryRowContext(ctx, `SELECT COUNT(*) > 0 FROM links WHERE source_id = ? AND target_id = ?`, a, b).Scan(&linked)
+		if err != nil {
+			return err
+		}
+		if !linked {
+			return sql.ErrNoRows
+		}
+	}
+	return nil
+}
+
+// UnlinkNotes removes the link between two notes. It returns sql.ErrNoRows
+// when they aren't linked.
+func (app *App) UnlinkNotes(ctx context.Context, a, b string) error {
+	a, b = min(a, b), max(a, b)
+	res, err := app.DB.ExecContext(ctx, `DELETE FROM links WHERE source_id = ? AND target_id = ?`, a, b)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
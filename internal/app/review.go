@@ -0,0 +1,362 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/yagnikpt/flashback/internal/models"
+	"github.com/yagnikpt/flashback/internal/utils"
+)
+
+// Grade is how well a note was remembered, on the SM-2 scale where grades
+// below GradeHard count as forgotten.
+type Grade int
+
+const (
+	GradeAgain Grade = 2
+	GradeHard  Grade = 3
+	GradeGood  Grade = 4
+	GradeEasy  Grade = 5
+)
+
+// ParseGrade accepts a grade by name or by its first letter.
+func ParseGrade(value string) (Grade, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "again", "a":
+		return GradeAgain, nil
+	case "hard", "h":
+		return GradeHard, nil
+	case "good", "g":
+		return GradeGood, nil
+	case "easy", "e":
+		return GradeEasy, nil
+	}
+	return 0, fmt.Errorf("invalid grade %q (expected again, hard, good or easy)", value)
+}
+
+const (
+	initialEase = 2.5
+	minEase     = 1.3
+	// defaultReviewNewPerDay is how many never reviewed notes are added to
+	// review each day unless review_new_per_day is set.
+	defaultReviewNewPerDay = 10
+)
+
+// ReviewState is the review schedule of a note. Notes that were never
+// reviewed have a zero ReviewedAt.
+type ReviewState struct {
+	Ease        float64   `json:"ease"`
+	Interval    int       `json:"interval_days"`
+	Repetitions int       `json:"repetitions"`
+	Lapses      int       `json:"lapses"`
+	Due         time.Time `json:"due_at"`
+	ReviewedAt  time.Time `json:"reviewed_at"`
+}
+
+func newReviewState() ReviewState {
+	return ReviewState{Ease: initialEase}
+}
+
+// Schedule returns the state after grading a review done at now, following
+// SM-2: a remembered note comes back after 1 day, then 6 days, then the
+// previous interval times its ease, and a forgotten note starts over. The
+// ease goes up for easy reviews and down for hard ones.
+func Schedule(state ReviewState, grade Grade, now time.Time) ReviewState {
+	if grade < GradeHard {
+		state.Repetitions = 0
+		state.Interval = 1
+		state.Lapses++
+	} else {
+		switch state.Repetitions {
+		case 0:
+			state.Interval = 1
+		case 1:
+			state.Interval = 6
+		default:
+			state.Interval = int(math.Round(float64(state.Interval) * state.Ease))
+		}
+		state.Repetitions++
+	}
+
+	q := float64(5 - grade)
+	state.Ease = max(minEase, state.Ease+0.1-q*(0.08+q*0.02))
+	state.ReviewedAt = now
+	state.Due = now.AddDate(0, 0, state.Interval)
+	return state
+}
+
+// ReviewCard is a note to review with its schedule. New is set for notes
+// that were never reviewed.
+type ReviewCard struct {
+	Note  models.FlashbackWithMetadata `json:"note"`
+	State ReviewState                  `json:"schedule"`
+	New   bool                         `json:"new"`
+}
+
+// ReviewCounts is how many notes are due for review and how many never
+// reviewed notes can still be started today.
+type ReviewCounts struct {
+	Due int `json:"due"`
+	New int `json:"new"`
+}
+
+// reviewable is an SQL condition on the flashbacks table aliased as f that
+// holds for notes in review, which are never in the trash. A note's own
+// choice wins over its tags', a tag opted out excludes its notes and, once
+// any tag is opted in, only notes with such a tag are reviewed.
+const reviewable = `
+    f.deleted_at IS NULL
+    AND f.id NOT IN (SELECT flashback_id FROM review_notes WHERE included = 0)
+    AND (
+        f.id IN (SELECT flashback_id FROM review_notes WHERE included = 1)
+        OR (
+            f.id NOT IN (
+                SELECT ft.flashback_id FROM flashback_tags ft
+                JOIN review_tags rt ON rt.tag_id = ft.tag_id WHERE rt.included = 0)
+            AND (
+                (SELECT COUNT(*) FROM review_tags WHERE included = 1) = 0
+                OR f.id IN (
+                    SELECT ft.flashback_id FROM flashback_tags ft
+                    JOIN review_tags rt ON rt.tag_id = ft.tag_id WHERE rt.included = 1)
+            )
+        )
+    )`
+
+// reviewNewPerDay is how many never reviewed notes may be started each day.
+func (app *App) reviewNewPerDay() int {
+	if app.Config.ReviewNewPerDay != nil {
+		return max(*app.Config.ReviewNewPerDay, 0)
+	}
+	return defaultReviewNewPerDay
+}
+
+// newLeftToday is how many never reviewed notes can still be started today,
+// in local time.
+func (app *App) newLeftToday(ctx context.Context) (int, error) {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	var started int
+	err := app.DB.QueryRowContext(ctx, `
+    SELECT COUNT(*) FROM reviews WHERE datetime(first_reviewed_at) >= datetime(?)
+    `, today.UTC().Format(time.DateTime)).Scan(&started)
+	if err != nil {
+		return 0, err
+	}
+	return max(app.reviewNewPerDay()-started, 0), nil
+}
+
+// GetReviewQueue returns up to limit notes to review now: notes that are due,
+// longest overdue first, followed by never reviewed notes, oldest first, up
+// to the daily limit. A limit of 0 returns every note due.
+func (app *App) GetReviewQueue(ctx context.Context, limit int) ([]ReviewCard, error) {
+	now := time.Now().UTC().Format(time.DateTime)
+	query := `
+    SELECT f.id, r.ease, r.interval_days, r.repetitions, r.lapses, r.due_at, r.reviewed_at
+    FROM flashbacks f
+    JOIN reviews r ON r.flashback_id = f.id
+    WHERE datetime(r.due_at) <= datetime(?) AND ` + reviewable + `
+    ORDER BY r.due_at, f.id`
+	args := []any{now}
+	if limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, limit)
+	}
+
+	var ids []string
+	states := map[string]ReviewState{}
+	err := eachRow(ctx, app.DB, query, args, func(rows *sql.Rows) error {
+		var id, due, reviewed string
+		state := ReviewState{}
+		if err := rows.Scan(&id, &state.Ease, &state.Interval, &state.Repetitions, &state.Lapses, &due, &reviewed); err != nil {
+			return err
+		}
+		state.Due, _ = utils.ParseTimestamp(due)
+		state.ReviewedAt, _ = utils.ParseTimestamp(reviewed)
+		ids = append(ids, id)
+		states[id] = state
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	newLimit, err := app.newLeftToday(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if limit > 0 {
+		newLimit = min(newLimit, limit-len(ids))
+	}
+	if newLimit > 0 {
+		rows, err := app.DB.QueryContext(ctx, `
+        SELECT f.id FROM flashbacks f
+        WHERE f.id NOT IN (SELECT flashback_id FROM reviews) AND `+reviewable+`
+        ORDER BY f.created_at, f.id
+        LIMIT ?
+        `, newLimit)
+		if err != nil {
+			return nil, err
+		}
+		newIDs, err := scanStrings(rows)
+		rows.Close()
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range newIDs {
+			ids = append(ids, id)
+			states[id] = newReviewState()
+		}
+	}
+
+	notes, err := app.getNotesByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	cards := make([]ReviewCard, len(notes))
+	for i, note := range notes {
+		state := states[note.ID]
+		cards[i] = ReviewCard{Note: note, State: state, New: state.ReviewedAt.IsZero()}
+	}
+	return cards, nil
+}
+
+// GetReviewCounts counts the notes due for review and the never reviewed
+// notes that can still be started today.
+func (app *App) GetReviewCounts(ctx context.Context) (ReviewCounts, error) {
+	var counts ReviewCounts
+	err := app.DB.QueryRowContext(ctx, `
+    SELECT COUNT(*) FROM flashbacks f
+    JOIN reviews r ON r.flashback_id = f.id
+    WHERE datetime(r.due_at) <= datetime(?) AND `+reviewable,
+		time.Now().UTC().Format(time.DateTime)).Scan(&counts.Due)
+	if err != nil {
+		return ReviewCounts{}, err
+	}
+
+	var unreviewed int
+	err = app.DB.QueryRowContext(ctx, `
+    SELECT COUNT(*) FROM flashbacks f
+    WHERE f.id NOT IN (SELECT flashback_id FROM reviews) AND `+reviewable).Scan(&unreviewed)
+	if err != nil {
+		return ReviewCounts{}, err
+	}
+	newLeft, err := app.newLeftToday(ctx)
+	if err != nil {
+		return ReviewCounts{}, err
+	}
+	counts.New = min(unreviewed, newLeft)
+	return counts, nil
+}
+
+// GradeNote records a review of note id and reschedules it. It returns
+// sql.ErrNoRows when the note doesn't exist.
+func (app *App) GradeNote(ctx context.Context, id string, grade Grade) (ReviewState, error) {
+	tx, err := app.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return ReviewState{}, err
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	err = tx.QueryRowContext(ctx, `SELECT COUNT(*) > 0 FROM flashbacks WHERE id = ? AND deleted_at IS NULL`, id).Scan(&exists)
+	if err != nil {
+		return ReviewState{}, err
+	}
+	if !exists {
+		return ReviewState{}, sql.ErrNoRows
+	}
+
+	state := newReviewState()
+	err = tx.QueryRowContext(ctx, `
+    SELECT ease, interval_days, repetitions, lapses FROM reviews WHERE flashback_id = ?
+    `, id).Scan(&state.Ease, &state.Interval, &state.Repetitions, &state.Lapses)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return ReviewState{}, err
+	}
+
+	now := time.Now().UTC()
+	state = Schedule(state, grade, now)
+	_, err = tx.ExecContext(ctx, `
+    INSERT INTO reviews (flashback_id, ease, interval_days, repetitions, lapses, due_at, reviewed_at, first_reviewed_at)
+    VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+    ON CONFLICT (flashback_id) DO UPDATE SET
+        ease = excluded.ease,
+        interval_days = excluded.interval_days,
+        repetitions = excluded.repetitions,
+        lapses = excluded.lapses,
+        due_at = excluded.due_at,
+        reviewed_at = excluded.reviewed_at
+    `, id, state.Ease, state.Interval, state.Repetitions, state.Lapses,
+		state.Due.Format(time.DateTime), now.Format(time.DateTime), now.Format(time.DateTime))
+	if err != nil {
+		return ReviewState{}, err
+	}
+	return state, tx.Commit()
+}
+
+// ReviewChoice is whether a note or tag is opted in to or out of review.
+type ReviewChoice int
+
+const (
+	// ReviewDefault drops an earlier choice: notes follow their tags, and
+	// tags the rest of the notes.
+	ReviewDefault ReviewChoice = iota
+	ReviewInclude
+	ReviewExclude
+)
+
+// included is the value stored for the choice.
+func (c ReviewChoice) included() int {
+	if c == ReviewInclude {
+		return 1
+	}
+	return 0
+}
+
+// SetNoteReview opts note id in to or out of review. It returns
+// sql.ErrNoRows when the note doesn't exist.
+func (app *App) SetNoteReview(ctx context.Context, id string, choice ReviewChoice) error {
+	var exists bool
+	err := app.DB.QueryRowContext(ctx, `SELECT COUNT(*) > 0 FROM flashbacks WHERE id = ? AND deleted_at IS NULL`, id).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return sql.ErrNoRows
+	}
+
+	if choice == ReviewDefault {
+		_, err = app.DB.ExecContext(ctx, `DELETE FROM review_notes WHERE flashback_id = ?`, id)
+		return err
+	}
+	_, err = app.DB.ExecContext(ctx, `
+    INSERT INTO review_notes (flashback_id, included) VALUES (?, ?)
+    ON CONFLICT (flashback_id) DO UPDATE SET included = excluded.included
+    `, id, choice.included())
+	return err
+}
+
+// SetTagReview opts the notes tagged name in to or out of review. It
+// returns sql.ErrNoRows when the tag doesn't exist.
+func (app *App) SetTagReview(ctx context.Context, name string, choice ReviewChoice) error {
+	var tagID int64
+	err := app.DB.QueryRowContext(ctx, `SELECT id FROM tags WHERE name = ?`, name).Scan(&tagID)
+	if err != nil {
+		return err
+	}
+
+	if choice == ReviewDefault {
+		_, err = app.DB.ExecContext(ctx, `DELETE FROM review_tags WHERE tag_id = ?`, tagID)
+		return err
+	}
+	_, err = app.DB.ExecContext(ctx, `
+    INSERT INTO review_tags (tag_id, included) VALUES (?, ?)
+    ON CONFLICT (tag_id) DO UPDATE SET included = excluded.included
+    `, tagID, choice.included())
+	return err
+}
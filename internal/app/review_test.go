@@ -0,0 +1,111 @@
+package app
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestParseGrade(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    Grade
+		wantErr bool
+	}{
+		{value: "again", want: GradeAgain},
+		{value: "a", want: GradeAgain},
+		{value: "Hard", want: GradeHard},
+		{value: " g ", want: GradeGood},
+		{value: "EASY", want: GradeEasy},
+		{value: "e", want: GradeEasy},
+		{value: "", wantErr: true},
+		{value: "ok", wantErr: true},
+		{value: "4", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseGrade(tt.value)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseGrade(%q) = %d, want an error", tt.value, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("ParseGrade(%q) = %d, %v; want %d", tt.value, got, err, tt.want)
+		}
+	}
+}
+
+func TestSchedule(t *testing.T) {
+	now := time.Date(2025, 3, 15, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		state ReviewState
+		grade Grade
+		want  ReviewState
+	}{
+		{
+			name:  "first review",
+			state: newReviewState(),
+			grade: GradeGood,
+			want:  ReviewState{Ease: 2.5, Interval: 1, Repetitions: 1},
+		},
+		{
+			name:  "second review",
+			state: ReviewState{Ease: 2.5, Interval: 1, Repetitions: 1},
+			grade: GradeGood,
+			want:  ReviewState{Ease: 2.5, Interval: 6, Repetitions: 2},
+		},
+		{
+			name:  "interval grows by the ease",
+			state: ReviewState{Ease: 2.5, Interval: 6, Repetitions: 2},
+			grade: GradeGood,
+			want:  ReviewState{Ease: 2.5, Interval: 15, Repetitions: 3},
+		},
+		{
+			name:  "easy raises the ease",
+			state: newReviewState(),
+			grade: GradeEasy,
+			want:  ReviewState{Ease: 2.6, Interval: 1, Repetitions: 1},
+		},
+		{
+			name:  "hard is remembered but lowers the ease",
+			state: ReviewState{Ease: 2.5, Interval: 6, Repetitions: 2},
+			grade: GradeHard,
+			want:  ReviewState{Ease: 2.36, Interval: 15, Repetitions: 3},
+		},
+		{
+			name:  "again starts over",
+			state: ReviewState{Ease: 2.5, Interval: 15, Repetitions: 3, Lapses: 1},
+			grade: GradeAgain,
+			want:  ReviewState{Ease: 2.18, Interval: 1, Repetitions: 0, Lapses: 2},
+		},
+		{
+			name:  "ease never drops below the floor",
+			state: ReviewState{Ease: 1.4, Interval: 6, Repetitions: 2},
+			grade: GradeAgain,
+			want:  ReviewState{Ease: minEase, Interval: 1, Repetitions: 0, Lapses: 1},
+		},
+		{
+			name:  "hard at the floor stays there",
+			state: ReviewState{Ease: minEase, Interval: 10, Repetitions: 4},
+			grade: GradeHard,
+			want:  ReviewState{Ease: minEase, Interval: 13, Repetitions: 5},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Schedule(tt.state, tt.grade, now)
+			if math.Abs(got.Ease-tt.want.Ease) > 1e-9 || got.Interval != tt.want.Interval ||
+				got.Repetitions != tt.want.Repetitions || got.Lapses != tt.want.Lapses {
+				t.Errorf("Schedule(%+v, %d) = %+v, want %+v", tt.state, tt.grade, got, tt.want)
+			}
+			if !got.ReviewedAt.Equal(now) {
+				t.Errorf("ReviewedAt = %s, want %s", got.ReviewedAt, now)
+			}
+			if want := now.AddDate(0, 0, tt.want.Interval); !got.Due.Equal(want) {
+				t.Errorf("Due = %s, want %s", got.Due, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,235 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/yagnikpt/flashback/internal/utils"
+)
+
+// Revision is a version of a note's content and of the metadata and tags
+// the user owns. Generated values aren't part of revisions.
+type Revision struct {
+	Number    int               `json:"revision"`
+	Content   string            `json:"content"`
+	Metadata  map[string]string `json:"metadata"`
+	Tags      []string          `json:"tags"`
+	CreatedAt string            `json:"created_at"`
+}
+
+// recordRevision saves the current content, user metadata and user tags of
+// note id as its next revision, unless they didn't change since the latest
+// one. Changes call it before and after writing, so notes changed before
+// revisions were kept still get their earlier version recorded.
+func recordRevision(tx *sql.Tx, id string) error {
+	var current Revision
+	err := tx.QueryRow(`SELECT content FROM flashbacks WHERE id = ?`, id).Scan(&current.Content)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	current.Metadata = map[string]string{}
+	rows, err := tx.Query(`SELECT key, value FROM metadata WHERE flashback_id = ? AND source = 'user'`, id)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var key string
+		var value sql.NullString
+		if err := rows.Scan(&key, &value); err != nil {
+			rows.Close()
+			return err
+		}
+		current.Metadata[key] = value.String
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	rows, err = tx.Query(`
+    SELECT t.name FROM flashback_tags ft JOIN tags t ON t.id = ft.tag_id
+    WHERE ft.flashback_id = ? AND ft.source = 'user'
+    `, id)
+	if err != nil {
+		return err
+	}
+	current.Tags, err = scanStrings(rows)
+	rows.Close()
+	if err != nil {
+		return err
+	}
+	sortTags(current.Tags)
+
+	var latest Revision
+	var metadata, tags string
+	err = tx.QueryRow(`
+    SELECT content, metadata, tags FROM revisions WHERE flashback_id = ?
+    ORDER BY revision DESC LIMIT 1
+    `, id).Scan(&latest.Content, &metadata, &tags)
+	if err == nil {
+		if err := latest.decode(metadata, tags); err != nil {
+			return err
+		}
+		if latest.Content == current.Content && maps.Equal(latest.Metadata, current.Metadata) && slices.Equal(latest.Tags, current.Tags) {
+			return nil
+		}
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	metadataJSON, err := json.Marshal(current.Metadata)
+	if err != nil {
+		return err
+	}
+	tagsJSON, err := json.Marshal(current.Tags)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`
+    INSERT INTO revisions (flashback_id, revision, content, metadata, tags)
+    SELECT ?, COALESCE(MAX(revision), 0) + 1, ?, ?, ? FROM revisions WHERE flashback_id = ?
+    `, id, current.Content, string(metadataJSON), string(tagsJSON), id)
+	return err
+}
+
+// decode fills in Metadata and Tags from their stored JSON.
+func (r *Revision) decode(metadata, tags string) error {
+	r.Metadata = map[string]string{}
+	if err := json.Unmarshal([]byte(metadata), &r.Metadata); err != nil {
+		return err
+	}
+	r.Tags = []string{}
+	if err := json.Unmarshal([]byte(tags), &r.Tags); err != nil {
+		return err
+	}
+	sortTags(r.Tags)
+	return nil
+}
+
+func sortTags(tags []string) {
+	sort.Slice(tags, func(i, j int) bool {
+		return strings.ToLower(tags[i]) < strings.ToLower(tags[j])
+	})
+}
+
+// ListRevisions returns the revisions of note id, oldest first. It returns
+// sql.ErrNoRows when the note doesn't exist.
+func (app *App) ListRevisions(ctx context.Context, id string) ([]Revision, error) {
+	var exists bool
+	err := app.DB.QueryRowContext(ctx, `SELECT COUNT(*) > 0 FROM flashbacks WHERE id = ? AND deleted_at IS NULL`, id).Scan(&exists)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, sql.ErrNoRows
+	}
+
+	revisions := []Revision{}
+	err = eachRow(ctx, app.DB, `
+    SELECT revision, content, metadata, tags, created_at FROM revisions
+    WHERE flashback_id = ? ORDER BY revision
+    `, []any{id}, func(rows *sql.Rows) error {
+		var r Revision
+		var metadata, tags string
+		var createdAt sql.NullString
+		if err := rows.Scan(&r.Number, &r.Content, &metadata, &tags, &createdAt); err != nil {
+			return err
+		}
+		if err := r.decode(metadata, tags); err != nil {
+			return err
+		}
+		r.CreatedAt = createdAt.String
+		revisions = append(revisions, r)
+		return nil
+	})
+	return revisions, err
+}
+
+// GetRevision returns revision number of note id. It returns sql.ErrNoRows
+// when either doesn't exist.
+func (app *App) GetRevision(ctx context.Context, id string, number int) (Revision, error) {
+	revisions, err := app.ListRevisions(ctx, id)
+	if err != nil {
+		return Revision{}, err
+	}
+	for _, r := range revisions {
+		if r.Number == number {
+			return r, nil
+		}
+	}
+	return Revision{}, sql.ErrNoRows
+}
+
+// RestoreRevision brings back the content, user metadata and user tags of
+// revision number of note id, as a new revision. Generated metadata is
+// regenerated in the background when the content changes; otherwise only
+// the embedding is.
+func (app *App) RestoreRevision(ctx context.Context, id string, number int) error {
+	revision, err := app.GetRevision(ctx, id, number)
+	if err != nil {
+		return err
+	}
+
+	tx, err := app.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := recordRevision(tx, id); err != nil {
+		return err
+	}
+	var content string
+	if err := tx.QueryRow(`SELECT content FROM flashbacks WHERE id = ?`, id).Scan(&content); err != nil {
+		return err
+	}
+	_, err = tx.Exec(`
+    UPDATE flashbacks SET content = ?, url_key = CASE WHEN type = 'url' THEN ? END WHERE id = ?
+    `, revision.Content, utils.URLKey(revision.Content), id)
+	if err != nil {
+		return err
+	}
+
+	// Generated values for the restored keys are dropped, as ApplyNoteEdit
+	// does, so each key is stored once.
+	if _, err := tx.Exec(`DELETE FROM metadata WHERE flashback_id = ? AND source = 'user'`, id); err != nil {
+		return err
+	}
+	for key, value := range revision.Metadata {
+		if _, err := tx.Exec(`DELETE FROM metadata WHERE flashback_id = ? AND key = ?`, id, key); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`INSERT INTO metadata (flashback_id, key, value, source) VALUES (?, ?, ?, 'user')`, id, key, value); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec(`DELETE FROM flashback_tags WHERE flashback_id = ? AND source = 'user'`, id); err != nil {
+		return err
+	}
+	if err := insertTags(tx, id, revision.Tags, TagSourceUser); err != nil {
+		return err
+	}
+
+	kind := JobEmbed
+	if content != revision.Content {
+		kind = JobEnrich
+	}
+	if err := enqueueJob(tx, id, kind); err != nil {
+		return err
+	}
+	if err := recordRevision(tx, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
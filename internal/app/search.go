@@ -0,0 +1,369 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yagnikpt/flashback/internal/models"
+	"github.com/yagnikpt/flashback/internal/providers"
+	"github.com/yagnikpt/flashback/internal/utils"
+)
+
+type SearchMode string
+
+const (
+	SearchModeHybrid   SearchMode = "hybrid"
+	SearchModeLexical  SearchMode = "lexical"
+	SearchModeSemantic SearchMode = "semantic"
+)
+
+const (
+	searchLimit = 20
+	// minSimilarity is the default cosine similarity a note needs to be
+	// returned by semantic search.
+	minSimilarity = 0.60
+	// rrfK dampens the weight of top ranks in reciprocal rank fusion. 60 is
+	// the value from the original RRF paper and works well without tuning.
+	rrfK = 60
+	// embeddingTimeout bounds how long a search waits on the provider before
+	// falling back to lexical results.
+	embeddingTimeout = 5 * time.Second
+	// snippetLength is the length of the passages shown with results.
+	snippetLength = 240
+)
+
+func ParseSearchMode(mode string) (SearchMode, error) {
+	switch SearchMode(mode) {
+	case SearchModeHybrid, SearchModeLexical, SearchModeSemantic:
+		return SearchMode(mode), nil
+	default:
+		return "", fmt.Errorf("invalid search mode %q (expected hybrid, lexical or semantic)", mode)
+	}
+}
+
+type SearchOptions struct {
+	Query  string
+	Mode   SearchMode
+	Filter NoteFilter
+	// MinSimilarity is the cosine similarity (0-1) semantic matches need.
+	MinSimilarity float64
+	Limit         int
+}
+
+type SearchResult struct {
+	Notes []models.FlashbackWithMetadata
+	// Mode is the mode that actually produced Notes. It differs from the
+	// requested mode when the embedding provider couldn't be reached.
+	Mode SearchMode
+	// Fallback holds the embedding error that caused a fallback to lexical
+	// search, if any.
+	Fallback error
+	// Scores holds the fused ranking score of each note by ID.
+	Scores map[string]float64
+	// Similarities holds the cosine similarity to the query of each note
+	// found by semantic search, by ID.
+	Similarities map[string]float64
+	// Snippets holds, by ID, the passage of the page behind a note that
+	// best matched the query, when it was found through one.
+	Snippets map[string]string
+	// Incomparable counts the notes semantic search skipped because their
+	// embedding comes from another model or has another dimension.
+	Incomparable int
+}
+
+// SearchNotes finds notes matching opts. Hybrid mode merges BM25 full-text
+// results with embedding similarity results using reciprocal rank fusion.
+// When the query can't be embedded, hybrid and semantic searches degrade to
+// lexical search instead of failing; the next search tries the provider again.
+// A query with only filters lists the matching notes, newest first.
+func (app *App) SearchNotes(ctx context.Context, opts SearchOptions) (SearchResult, error) {
+	if opts.Mode == "" {
+		opts.Mode = SearchModeHybrid
+	}
+	if opts.Limit <= 0 {
+		opts.Limit = searchLimit
+	}
+	if opts.MinSimilarity == 0 {
+		opts.MinSimilarity = minSimilarity
+	}
+	result := SearchResult{
+		Mode:         opts.Mode,
+		Scores:       map[string]float64{},
+		Similarities: map[string]float64{},
+		Snippets:     map[string]string{},
+	}
+
+	if strings.TrimSpace(opts.Query) == "" {
+		notes, err := app.ListNotes(ctx, opts.Filter)
+		if err != nil {
+			return SearchResult{}, err
+		}
+		if len(notes) > opts.Limit {
+			notes = notes[:opts.Limit]
+		}
+		result.Notes = notes
+		return result, nil
+	}
+
+	var vector []float32
+	if opts.Mode != SearchModeLexical {
+		embedCtx, cancel := context.WithTimeout(ctx, embeddingTimeout)
+		defer cancel()
+		var err error
+		vector, err = app.GenerateEmbeddingForNote(embedCtx, opts.Query, providers.TaskRetrievalQuery)
+		if err != nil {
+			log.Println("Falling back to lexical search:", err)
+			result.Mode = SearchModeLexical
+			result.Fallback = err
+		}
+	}
+
+	var rankings [][]string
+	if result.Mode != SearchModeSemantic {
+		ids, err := app.rankByKeyword(ctx, opts.Query, opts.Filter, opts.Limit)
+		if err != nil {
+			return SearchResult{}, err
+		}
+		rankings = append(rankings, ids)
+	}
+
+	if result.Mode != SearchModeLexical {
+		_, noteSimilarities, err := app.rankBySimilarity(ctx, vector, opts.Filter, opts.MinSimilarity, opts.Limit)
+		if err != nil {
+			return SearchResult{}, err
+		}
+		_, chunkSimilarities, passages, err := app.rankChunksBySimilarity(ctx, vector, opts.Filter, opts.MinSimilarity, opts.Limit)
+		if err != nil {
+			return SearchResult{}, err
+		}
+		ids, similarities := mergeSimilarities(noteSimilarities, chunkSimilarities)
+		if len(ids) > opts.Limit {
+			ids = ids[:opts.Limit]
+		}
+		rankings = append(rankings, ids)
+		result.Similarities = similarities
+		for id, passage := range passages {
+			result.Snippets[id] = utils.Snippet(passage, opts.Query, snippetLength)
+		}
+
+		result.Incomparable, err = app.countIncomparableEmbeddings(ctx, len(vector))
+		if err != nil {
+			return SearchResult{}, err
+		}
+	}
+
+	ids, scores := fuseRankings(rankings...)
+	result.Scores = scores
+	if len(ids) > opts.Limit {
+		ids = ids[:opts.Limit]
+	}
+	notes, err := app.getNotesByIDs(ctx, ids)
+	if err != nil {
+		return SearchResult{}, err
+	}
+	result.Notes = notes
+	return result, nil
+}
+
+// ParseSearchQuery splits inline filters out of a search query, e.g.
+// "tag:k8s type:url after:2025-01-01 load balancer". Supported filters are
+// tag:, type:, in:, after:/since:, before: and until:, which includes the
+// day it names. Everything else is the query.
+func ParseSearchQuery(input string) (SearchOptions, error) {
+	var opts SearchOptions
+	var words []string
+	now := time.Now()
+
+	for _, field := range strings.Fields(input) {
+		key, value, found := strings.Cut(field, ":")
+		if !found || value == "" {
+			words = append(words, field)
+			continue
+		}
+
+		switch strings.ToLower(key) {
+		case "tag":
+			opts.Filter.Tags = append(opts.Filter.Tags, value)
+		case "type":
+			t, err := ParseNoteType(value)
+			if err != nil {
+				return SearchOptions{}, err
+			}
+			opts.Filter.Types = append(opts.Filter.Types, t)
+		case "in":
+			opts.Filter.Collection = value
+		case "after", "since":
+			t, err := ParseTime(value, now)
+			if err != nil {
+				return SearchOptions{}, err
+			}
+			opts.Filter.Since = t
+		case "before", "until":
+			parse := ParseTime
+			if strings.ToLower(key) == "until" {
+				parse = ParseUntil
+			}
+			t, err := parse(value, now)
+			if err != nil {
+				return SearchOptions{}, err
+			}
+			opts.Filter.Until = t
+		default:
+			words = append(words, field)
+		}
+	}
+
+	opts.Query = strings.Join(words, " ")
+	return opts, nil
+}
+
+func (app *App) RetrieveNotesByKeyword(ctx context.Context, query string) ([]models.FlashbackWithMetadata, error) {
+	ids, err := app.rankByKeyword(ctx, query, NoteFilter{}, searchLimit)
+	if err != nil {
+		return nil, err
+	}
+	return app.getNotesByIDs(ctx, ids)
+}
+
+func (app *App) rankByKeyword(ctx context.Context, query string, filter NoteFilter, limit int) ([]string, error) {
+	match := ftsQuery(query)
+	if match == "" {
+		return nil, nil
+	}
+
+	where, args := filter.where("f")
+	args = append([]any{match}, args...)
+	args = append(args, limit)
+	rows, err := app.DB.QueryContext(ctx, `
+    SELECT flashbacks_fts.flashback_id FROM flashbacks_fts
+    JOIN flashbacks f ON f.id = flashbacks_fts.flashback_id
+    WHERE flashbacks_fts MATCH ?`+where+`
+    ORDER BY bm25(flashbacks_fts) LIMIT ?
+    `, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanStrings(rows)
+}
+
+// rankBySimilarity returns the IDs of notes closest to vector along with
+// their cosine similarity. Only embeddings from the configured model with the
+// same dimension as vector are compared. Large stores are searched with a
+// vector index when the database supports it; filtered searches that the
+// index can't fill fall back to a full scan.
+func (app *App) rankBySimilarity(ctx context.Context, vector []float32, filter NoteFilter, similarity float64, limit int) ([]string, map[string]float64, error) {
+	if app.vectorIndex.ready(ctx, app.DB, annEmbeddings, len(vector)) {
+		ids, similarities, err := app.rankByVectorIndex(ctx, vector, filter, similarity, limit)
+		if err == nil && (filter.IsZero() || len(ids) >= limit) {
+			return ids, similarities, nil
+		}
+		if err != nil {
+			log.Println("Vector index search failed, using full scans:", err)
+			app.vectorIndex.disable(annEmbeddings, len(vector))
+		}
+	}
+	return app.scanBySimilarity(ctx, vector, filter, similarity, limit)
+}
+
+// scanBySimilarity is rankBySimilarity comparing vector with every
+// embedding.
+func (app *App) scanBySimilarity(ctx context.Context, vector []float32, filter NoteFilter, similarity float64, limit int) ([]string, map[string]float64, error) {
+	embeddings, err := json.Marshal(vector)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	where, args := filter.where("f")
+	args = append([]any{string(embeddings), app.Embedder.EmbeddingModel(), len(vector), string(embeddings), 1 - similarity}, args...)
+	args = append(args, string(embeddings), limit)
+	rows, err := app.DB.QueryContext(ctx, `
+    SELECT e.flashback_id, 1 - vector_distance_cos(e.vector, vector32(?)) FROM embeddings e
+    JOIN flashbacks f ON f.id = e.flashback_id
+    WHERE e.model = ? AND e.dimension = ?
+        AND vector_distance_cos(e.vector, vector32(?)) < ?`+where+`
+    ORDER BY vector_distance_cos(e.vector, vector32(?)) ASC LIMIT ?
+    `, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+	return scanSimilarities(rows)
+}
+
+// scanSimilarities reads rows of note IDs and similarities, best first.
+func scanSimilarities(rows *sql.Rows) ([]string, map[string]float64, error) {
+	var ids []string
+	similarities := make(map[string]float64)
+	for rows.Next() {
+		var id string
+		var score float64
+		if err := rows.Scan(&id, &score); err != nil {
+			return nil, nil, err
+		}
+		ids = append(ids, id)
+		similarities[id] = score
+	}
+	return ids, similarities, rows.Err()
+}
+
+// countIncomparableEmbeddings counts the embeddings that can't be compared
+// with a query vector of the given dimension from the configured model.
+func (app *App) countIncomparableEmbeddings(ctx context.Context, dimension int) (int, error) {
+	var count int
+	err := app.DB.QueryRowContext(ctx, `
+    SELECT COUNT(*) FROM embeddings WHERE model IS NOT ? OR dimension != ?
+    `, app.Embedder.EmbeddingModel(), dimension).Scan(&count)
+	return count, err
+}
+
+func scanStrings(rows *sql.Rows) ([]string, error) {
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// ftsQuery turns free text into an FTS5 expression. Every term is quoted so
+// punctuation in commands or error strings isn't parsed as FTS5 syntax, and
+// terms are OR-ed so BM25 can rank partial matches.
+func ftsQuery(query string) string {
+	terms := strings.Fields(query)
+	quoted := make([]string, 0, len(terms))
+	for _, term := range terms {
+		quoted = append(quoted, `"`+strings.ReplaceAll(term, `"`, `""`)+`"`)
+	}
+	return strings.Join(quoted, " OR ")
+}
+
+// fuseRankings merges ranked id lists with reciprocal rank fusion: each id
+// scores the sum of 1/(k+rank) over the lists it appears in.
+func fuseRankings(rankings ...[]string) ([]string, map[string]float64) {
+	scores := make(map[string]float64)
+	var ids []string
+	for _, ranking := range rankings {
+		for rank, id := range ranking {
+			if _, seen := scores[id]; !seen {
+				ids = append(ids, id)
+			}
+			scores[id] += 1.0 / float64(rrfK+rank+1)
+		}
+	}
+
+	sort.SliceStable(ids, func(i, j int) bool {
+		return scores[ids[i]] > scores[ids[j]]
+	})
+	return ids, scores
+}
@@ -0,0 +1,178 @@
+package app
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+)
+
+const (
+	snapshotEncodingGzip = "gzip"
+	snapshotEncodingNone = "none"
+)
+
+// Snapshot is a saved copy of the page behind a note. HTML is only set when
+// raw pages are kept (snapshot_html in the config).
+type Snapshot struct {
+	ID        int64
+	NoteID    string
+	Markdown  string
+	HTML      string
+	CreatedAt string
+	// Version numbers the snapshots of a note from 1, oldest first.
+	Version int
+}
+
+// StorePage saves the page behind a note as a new snapshot, unless it is the
+// same as the latest one, and replaces the note's passages unless chunks is
+// nil. html may be empty.
+func (app *App) StorePage(ctx context.Context, id, markdown, html string, chunks []Chunk) error {
+	tx, err := app.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	latest, err := latestSnapshot(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	if latest == nil || latest.Markdown != markdown {
+		compressedMarkdown, err := compress(markdown)
+		if err != nil {
+			return err
+		}
+		var compressedHTML []byte
+		if html != "" {
+			compressedHTML, err = compress(html)
+			if err != nil {
+				return err
+			}
+		}
+		_, err = tx.Exec(`
+        INSERT INTO snapshots (flashback_id, markdown, html, encoding) VALUES (?, ?, ?, ?)
+        `, id, compressedMarkdown, compressedHTML, snapshotEncodingGzip)
+		if err != nil {
+			return err
+		}
+	}
+
+	if chunks != nil {
+		if err := app.storeChunks(tx, id, markdown, chunks); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetPageText returns the text of the latest snapshot of a note, or "" when
+// there is none.
+func (app *App) GetPageText(ctx context.Context, id string) (string, error) {
+	snapshot, err := app.GetSnapshot(ctx, id, 0)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return snapshot.Markdown, nil
+}
+
+// GetSnapshot returns a version of the page behind a note, or the latest one
+// when version is 0. It returns sql.ErrNoRows when there is no such snapshot.
+func (app *App) GetSnapshot(ctx context.Context, id string, version int) (Snapshot, error) {
+	snapshots, err := app.ListSnapshots(ctx, id)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	if version == 0 {
+		version = len(snapshots)
+	}
+	if version < 1 || version > len(snapshots) {
+		return Snapshot{}, sql.ErrNoRows
+	}
+	snapshot := snapshots[version-1]
+
+	var markdown, html []byte
+	var encoding string
+	err = app.DB.QueryRowContext(ctx, `
+    SELECT markdown, html, encoding FROM snapshots WHERE id = ?
+    `, snapshot.ID).Scan(&markdown, &html, &encoding)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	if snapshot.Markdown, err = decode(markdown, encoding); err != nil {
+		return Snapshot{}, err
+	}
+	if snapshot.HTML, err = decode(html, encoding); err != nil {
+		return Snapshot{}, err
+	}
+	return snapshot, nil
+}
+
+// ListSnapshots returns the snapshots of a note, oldest first, without their
+// contents.
+func (app *App) ListSnapshots(ctx context.Context, id string) ([]Snapshot, error) {
+	rows, err := app.DB.QueryContext(ctx, `
+    SELECT id, created_at FROM snapshots WHERE flashback_id = ? ORDER BY id
+    `, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var snapshots []Snapshot
+	for rows.Next() {
+		snapshot := Snapshot{NoteID: id, Version: len(snapshots) + 1}
+		if err := rows.Scan(&snapshot.ID, &snapshot.CreatedAt); err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	return snapshots, rows.Err()
+}
+
+func latestSnapshot(ctx context.Context, tx *sql.Tx, id string) (*Snapshot, error) {
+	var snapshot Snapshot
+	var markdown []byte
+	var encoding string
+	err := tx.QueryRowContext(ctx, `
+    SELECT id, markdown, encoding FROM snapshots WHERE flashback_id = ? ORDER BY id DESC LIMIT 1
+    `, id).Scan(&snapshot.ID, &markdown, &encoding)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	snapshot.Markdown, err = decode(markdown, encoding)
+	return &snapshot, err
+}
+
+func compress(text string) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := io.WriteString(w, text); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decode(data []byte, encoding string) (string, error) {
+	if len(data) == 0 || encoding == snapshotEncodingNone {
+		return string(data), nil
+	}
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+	text, err := io.ReadAll(r)
+	return string(text), err
+}
@@ -0,0 +1,271 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/yagnikpt/flashback/internal/models"
+	"github.com/yagnikpt/flashback/internal/utils"
+)
+
+const (
+	TagSourceUser = "user"
+	TagSourceAI   = "ai"
+)
+
+type TagCount struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// splitTags moves the generated "tags" metadata value out of metadata and
+// returns it as a list. Unparseable values are dropped rather than failing
+// the whole note.
+func splitTags(metadata map[string]string) (map[string]string, []string) {
+	value, ok := metadata["tags"]
+	if !ok {
+		return metadata, nil
+	}
+
+	rest := make(map[string]string, len(metadata))
+	for key, v := range metadata {
+		if key != "tags" {
+			rest[key] = v
+		}
+	}
+
+	tags, err := utils.ParseTags(value)
+	if err != nil {
+		log.Println("Error parsing generated tags:", err, value)
+		return rest, nil
+	}
+	return rest, tags
+}
+
+// insertTags links tags to a note, creating missing tags. A tag the note
+// already has keeps its source, except that "user" always wins over "ai".
+func insertTags(tx *sql.Tx, id string, tags []string, source string) error {
+	for _, name := range utils.CleanTags(tags) {
+		_, err := tx.Exec(`INSERT OR IGNORE INTO tags (name) VALUES (?)`, name)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(`
+        INSERT INTO flashback_tags (flashback_id, tag_id, source)
+        SELECT ?, id, ? FROM tags WHERE name = ?
+        ON CONFLICT (flashback_id, tag_id) DO UPDATE SET source = CASE
+            WHEN excluded.source = 'user' THEN 'user' ELSE flashback_tags.source
+        END
+        `, id, source, name)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// attachTags fills in Tags for each note.
+func (app *App) attachTags(ctx context.Context, notes []models.FlashbackWithMetadata) error {
+	const batchSize = 500
+
+	idIndex := make(map[string]int, len(notes))
+	for i := range notes {
+		idIndex[notes[i].ID] = i
+		notes[i].Tags = []string{}
+	}
+
+	for start := 0; start < len(notes); start += batchSize {
+		end := min(start+batchSize, len(notes))
+		args := make([]any, 0, end-start)
+		for _, note := range notes[start:end] {
+			args = append(args, note.ID)
+		}
+
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(args)), ", ")
+		rows, err := app.DB.QueryContext(ctx, `
+        SELECT ft.flashback_id, t.name
+        FROM flashback_tags ft
+        JOIN tags t ON t.id = ft.tag_id
+        WHERE ft.flashback_id IN (`+placeholders+`)
+        ORDER BY t.name COLLATE NOCASE
+        `, args...)
+		if err != nil {
+			return err
+		}
+
+		for rows.Next() {
+			var id, name string
+			if err := rows.Scan(&id, &name); err != nil {
+				rows.Close()
+				return err
+			}
+			if idx, ok := idIndex[id]; ok {
+				notes[idx].Tags = append(notes[idx].Tags, name)
+			}
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetUserTags returns the tags of a note that were added by the user.
+func (app *App) GetUserTags(ctx context.Context, id string) ([]string, error) {
+	rows, err := app.DB.QueryContext(ctx, `
+    SELECT t.name FROM flashback_tags ft
+    JOIN tags t ON t.id = ft.tag_id
+    WHERE ft.flashback_id = ? AND ft.source = 'user'
+    `, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanStrings(rows)
+}
+
+func (app *App) ListTags(ctx context.Context) ([]TagCount, error) {
+	rows, err := app.DB.QueryContext(ctx, `
+    SELECT t.name, COUNT(ft.flashback_id)
+    FROM tags t
+    LEFT JOIN flashback_tags ft ON ft.tag_id = t.id
+        AND ft.flashback_id IN (SELECT id FROM flashbacks WHERE deleted_at IS NULL)
+    GROUP BY t.id
+    ORDER BY COUNT(ft.flashback_id) DESC, t.name COLLATE NOCASE
+    `)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := []TagCount{}
+	for rows.Next() {
+		var tag TagCount
+		if err := rows.Scan(&tag.Name, &tag.Count); err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	return tags, rows.Err()
+}
+
+// RenameTag renames a tag. Renaming onto an existing tag merges the two.
+func (app *App) RenameTag(ctx context.Context, oldName, newName string) error {
+	newName = strings.TrimSpace(newName)
+	if newName == "" {
+		return fmt.Errorf("tag name cannot be empty")
+	}
+
+	var targetID int64
+	err := app.DB.QueryRowContext(ctx, `SELECT id FROM tags WHERE name = ?`, newName).Scan(&targetID)
+	if err == nil && !strings.EqualFold(oldName, newName) {
+		return app.MergeTags(ctx, []string{oldName}, newName)
+	}
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+
+	res, err := app.DB.ExecContext(ctx, `UPDATE tags SET name = ? WHERE name = ?`, newName, oldName)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("tag %q not found", oldName)
+	}
+	return nil
+}
+
+// MergeTags moves every note tagged with one of sources onto target, creating
+// target if needed, and removes the source tags.
+func (app *App) MergeTags(ctx context.Context, sources []string, target string) error {
+	target = strings.TrimSpace(target)
+	if target == "" {
+		return fmt.Errorf("tag name cannot be empty")
+	}
+
+	tx, err := app.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`INSERT OR IGNORE INTO tags (name) VALUES (?)`, target)
+	if err != nil {
+		return err
+	}
+	var targetID int64
+	err = tx.QueryRow(`SELECT id FROM tags WHERE name = ?`, target).Scan(&targetID)
+	if err != nil {
+		return err
+	}
+
+	for _, source := range sources {
+		var sourceID int64
+		err := tx.QueryRow(`SELECT id FROM tags WHERE name = ?`, source).Scan(&sourceID)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("tag %q not found", source)
+		}
+		if err != nil {
+			return err
+		}
+		if sourceID == targetID {
+			continue
+		}
+
+		_, err = tx.Exec(`
+        INSERT INTO flashback_tags (flashback_id, tag_id, source)
+        SELECT flashback_id, ?, source FROM flashback_tags WHERE tag_id = ?
+        ON CONFLICT (flashback_id, tag_id) DO UPDATE SET source = CASE
+            WHEN excluded.source = 'user' THEN 'user' ELSE flashback_tags.source
+        END
+        `, targetID, sourceID)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(`DELETE FROM flashback_tags WHERE tag_id = ?`, sourceID)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(`DELETE FROM tags WHERE id = ?`, sourceID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (app *App) DeleteTag(ctx context.Context, name string) error {
+	tx, err := app.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var id int64
+	err = tx.QueryRow(`SELECT id FROM tags WHERE name = ?`, name).Scan(&id)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("tag %q not found", name)
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`DELETE FROM flashback_tags WHERE tag_id = ?`, id)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`DELETE FROM tags WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
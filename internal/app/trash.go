@@ -0,0 +1,143 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yagnikpt/flashback/internal/models"
+	"github.com/yagnikpt/flashback/internal/utils"
+)
+
+// defaultTrashRetentionDays is how long notes stay in the trash unless
+// trash_retention_days is set.
+const defaultTrashRetentionDays = 30
+
+// TrashedNote is a note in the trash with when it was removed.
+type TrashedNote struct {
+	models.FlashbackWithMetadata
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+// TrashRetentionDays is how many days notes stay in the trash before they
+// are deleted permanently; 0 keeps them until the trash is emptied.
+func (app *App) TrashRetentionDays() int {
+	if app.Config.TrashRetentionDays != nil {
+		return max(*app.Config.TrashRetentionDays, 0)
+	}
+	return defaultTrashRetentionDays
+}
+
+// ListTrash returns the notes in the trash, most recently removed first.
+func (app *App) ListTrash(ctx context.Context) ([]TrashedNote, error) {
+	var ids []string
+	deletedAt := map[string]time.Time{}
+	err := eachRow(ctx, app.DB, `
+    SELECT id, deleted_at FROM flashbacks WHERE deleted_at IS NOT NULL
+    ORDER BY deleted_at DESC, id
+    `, nil, func(rows *sql.Rows) error {
+		var id, deleted string
+		if err := rows.Scan(&id, &deleted); err != nil {
+			return err
+		}
+		ids = append(ids, id)
+		deletedAt[id], _ = utils.ParseTimestamp(deleted)
+		return nil
+	})
+	if err != nil || len(ids) == 0 {
+		return []TrashedNote{}, err
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", ")
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	rows, err := app.DB.QueryContext(ctx, `
+    SELECT f.id, f.content, f.type, f.created_at, m.key, m.value
+    FROM flashbacks f
+    LEFT JOIN metadata m ON f.id = m.flashback_id
+    WHERE f.id IN (`+placeholders+`)
+    `, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	notes, err := scanFlashbacks(rows)
+	if err != nil {
+		return nil, err
+	}
+	if err := app.attachTags(ctx, notes); err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]models.FlashbackWithMetadata, len(notes))
+	for _, note := range notes {
+		byID[note.ID] = note
+	}
+	trashed := make([]TrashedNote, 0, len(ids))
+	for _, id := range ids {
+		if note, ok := byID[id]; ok {
+			trashed = append(trashed, TrashedNote{FlashbackWithMetadata: note, DeletedAt: deletedAt[id]})
+		}
+	}
+	return trashed, nil
+}
+
+// RestoreNote takes a note out of the trash. It returns sql.ErrNoRows when
+// the note isn't in the trash.
+func (app *App) RestoreNote(ctx context.Context, id string) error {
+	res, err := app.DB.ExecContext(ctx, `UPDATE flashbacks SET deleted_at = NULL WHERE id = ? AND deleted_at IS NOT NULL`, id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// PurgeNote deletes a note permanently, whether it is in the trash or not.
+// It returns sql.ErrNoRows when the note doesn't exist.
+func (app *App) PurgeNote(ctx context.Context, id string) error {
+	res, err := app.DB.ExecContext(ctx, `DELETE FROM flashbacks WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// EmptyTrash deletes every note in the trash permanently and returns how
+// many were deleted.
+func (app *App) EmptyTrash(ctx context.Context) (int, error) {
+	res, err := app.DB.ExecContext(ctx, `DELETE FROM flashbacks WHERE deleted_at IS NOT NULL`)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+// PurgeExpiredTrash permanently deletes the notes that have been in the
+// trash for longer than the retention period and returns how many were
+// deleted.
+func (app *App) PurgeExpiredTrash(ctx context.Context) (int, error) {
+	days := app.TrashRetentionDays()
+	if days == 0 {
+		return 0, nil
+	}
+	res, err := app.DB.ExecContext(ctx, `
+    DELETE FROM flashbacks
+    WHERE deleted_at IS NOT NULL AND datetime(deleted_at) <= datetime('now', ?)
+    `, fmt.Sprintf("-%d days", days))
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
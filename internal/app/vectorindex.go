@@ -0,0 +1,212 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+)
+
+const (
+	// annMinEmbeddings is the number of embeddings of one dimension from
+	// which similarity search builds and uses a vector index. Below it a
+	// full scan is fast enough and inserts stay cheap.
+	annMinEmbeddings = 1000
+	// annOversample is how many more neighbours than requested are fetched
+	// from the index, as filters and the similarity threshold are applied
+	// afterwards.
+	annOversample = 10
+)
+
+// vectorIndex tracks the approximate nearest-neighbour indexes built with
+// libSQL's libsql_vector_idx, over note embeddings and page passages. An
+// index needs a fixed dimension, so each dimension gets its own table,
+// embeddings_ann_<dimension> or chunks_ann_<dimension>, which triggers keep
+// in sync with its source. Where the database doesn't support vector
+// indexes, similarity search keeps scanning.
+type vectorIndex struct {
+	mu sync.Mutex
+	// available holds, by source and dimension, whether the index exists
+	// (true) or can't be used (false). Missing entries haven't been checked
+	// yet or are too small to index.
+	available map[annKey]bool
+}
+
+// annSource is the table a vector index covers.
+type annSource string
+
+const (
+	annEmbeddings annSource = "embeddings"
+	annChunks     annSource = "chunks"
+)
+
+type annKey struct {
+	source    annSource
+	dimension int
+}
+
+func annTable(dimension int) string {
+	return fmt.Sprintf("embeddings_ann_%d", dimension)
+}
+
+func chunkAnnTable(dimension int) string {
+	return fmt.Sprintf("chunks_ann_%d", dimension)
+}
+
+// ready reports whether the index over source for dimension can be used,
+// creating it once source has enough vectors of that dimension.
+func (v *vectorIndex) ready(ctx context.Context, db *sql.DB, source annSource, dimension int) bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	key := annKey{source, dimension}
+	if ok, checked := v.available[key]; checked {
+		return ok
+	}
+
+	table, create := annTable(dimension), createVectorIndex
+	if source == annChunks {
+		table, create = chunkAnnTable(dimension), createChunkIndex
+	}
+	var exists bool
+	err := db.QueryRowContext(ctx, `
+    SELECT COUNT(*) > 0 FROM sqlite_master WHERE type = 'table' AND name = ?
+    `, table).Scan(&exists)
+	if err != nil {
+		log.Println("Error checking vector index:", err)
+		return false
+	}
+	if !exists {
+		var count int
+		err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM `+string(source)+` WHERE dimension = ?`, dimension).Scan(&count)
+		if err != nil {
+			log.Println("Error counting vectors:", err)
+			return false
+		}
+		if count < annMinEmbeddings {
+			return false
+		}
+		if err := create(ctx, db, dimension); err != nil {
+			log.Println("Vector index unavailable, using full scans:", err)
+			v.set(key, false)
+			return false
+		}
+	}
+	v.set(key, true)
+	return true
+}
+
+func (v *vectorIndex) set(key annKey, ok bool) {
+	if v.available == nil {
+		v.available = make(map[annKey]bool)
+	}
+	v.available[key] = ok
+}
+
+// disable stops using the index over source for dimension until the next
+// start.
+func (v *vectorIndex) disable(source annSource, dimension int) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.set(annKey{source, dimension}, false)
+}
+
+// createVectorIndex creates the index table for dimension, fills it with the
+// existing embeddings of that dimension and adds the triggers that keep it
+// up to date.
+func createVectorIndex(ctx context.Context, db *sql.DB, dimension int) error {
+	table := annTable(dimension)
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	statements := []string{
+		fmt.Sprintf(`CREATE TABLE %[1]s (
+            flashback_id TEXT PRIMARY KEY,
+            model TEXT,
+            vector F32_BLOB(%[2]d) NOT NULL
+        )`, table, dimension),
+		fmt.Sprintf(`CREATE INDEX %[1]s_idx ON %[1]s (libsql_vector_idx(vector, 'metric=cosine'))`, table),
+		fmt.Sprintf(`INSERT INTO %[1]s (flashback_id, model, vector)
+            SELECT flashback_id, model, vector FROM embeddings WHERE dimension = %[2]d`, table, dimension),
+		fmt.Sprintf(`CREATE TRIGGER %[1]s_insert AFTER INSERT ON embeddings WHEN new.dimension = %[2]d BEGIN
+            INSERT OR REPLACE INTO %[1]s (flashback_id, model, vector) VALUES (new.flashback_id, new.model, new.vector);
+        END`, table, dimension),
+		fmt.Sprintf(`CREATE TRIGGER %[1]s_delete AFTER DELETE ON embeddings WHEN old.dimension = %[2]d BEGIN
+            DELETE FROM %[1]s WHERE flashback_id = old.flashback_id;
+        END`, table, dimension),
+	}
+	for _, statement := range statements {
+		if _, err := tx.ExecContext(ctx, statement); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// createChunkIndex creates the index table over the page passages of
+// dimension, as createVectorIndex does for embeddings. Rows are keyed by the
+// passage's ID.
+func createChunkIndex(ctx context.Context, db *sql.DB, dimension int) error {
+	table := chunkAnnTable(dimension)
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	statements := []string{
+		fmt.Sprintf(`CREATE TABLE %[1]s (
+            chunk_id INTEGER PRIMARY KEY,
+            model TEXT,
+            vector F32_BLOB(%[2]d) NOT NULL
+        )`, table, dimension),
+		fmt.Sprintf(`CREATE INDEX %[1]s_idx ON %[1]s (libsql_vector_idx(vector, 'metric=cosine'))`, table),
+		fmt.Sprintf(`INSERT INTO %[1]s (chunk_id, model, vector)
+            SELECT id, model, vector FROM chunks WHERE dimension = %[2]d`, table, dimension),
+		fmt.Sprintf(`CREATE TRIGGER %[1]s_insert AFTER INSERT ON chunks WHEN new.dimension = %[2]d BEGIN
+            INSERT OR REPLACE INTO %[1]s (chunk_id, model, vector) VALUES (new.id, new.model, new.vector);
+        END`, table, dimension),
+		fmt.Sprintf(`CREATE TRIGGER %[1]s_delete AFTER DELETE ON chunks WHEN old.dimension = %[2]d BEGIN
+            DELETE FROM %[1]s WHERE chunk_id = old.id;
+        END`, table, dimension),
+	}
+	for _, statement := range statements {
+		if _, err := tx.ExecContext(ctx, statement); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// rankByVectorIndex is rankBySimilarity using the vector index for the
+// dimension of vector. Filters are applied to the approximate neighbours, so
+// fewer than limit notes may be returned even if more match.
+func (app *App) rankByVectorIndex(ctx context.Context, vector []float32, filter NoteFilter, similarity float64, limit int) ([]string, map[string]float64, error) {
+	embeddings, err := json.Marshal(vector)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	table := annTable(len(vector))
+	where, args := filter.where("f")
+	args = append([]any{string(embeddings), string(embeddings), limit * annOversample, app.Embedder.EmbeddingModel(), string(embeddings), 1 - similarity}, args...)
+	args = append(args, limit)
+	rows, err := app.DB.QueryContext(ctx, `
+    SELECT a.flashback_id, 1 - vector_distance_cos(a.vector, vector32(?)) AS score
+    FROM vector_top_k('`+table+`_idx', vector32(?), ?) AS t
+    JOIN `+table+` a ON a.rowid = t.id
+    JOIN flashbacks f ON f.id = a.flashback_id
+    WHERE a.model = ?
+        AND vector_distance_cos(a.vector, vector32(?)) < ?`+where+`
+    ORDER BY score DESC LIMIT ?
+    `, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+	return scanSimilarities(rows)
+}
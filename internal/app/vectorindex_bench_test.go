@@ -0,0 +1,168 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	_ "turso.tech/database/tursogo"
+
+	"github.com/yagnikpt/flashback/internal/migration"
+)
+
+const (
+	benchModel     = "bench/synthetic"
+	benchDimension = 768
+	benchLimit     = 20
+	// benchRecallQueries is the number of queries the recall of the vector
+	// index is measured on.
+	benchRecallQueries = 20
+	// benchQueries is the number of query vectors generated up front and
+	// cycled through, so the timed loops only search.
+	benchQueries = 64
+)
+
+// benchEmbedder stands in for the provider, as the benchmark only stores
+// and compares synthetic vectors.
+type benchEmbedder struct{ dimension int }
+
+func (b benchEmbedder) Embed(context.Context, string, string) ([]float32, error) {
+	return nil, errors.New("the benchmark embedder can't embed text")
+}
+func (b benchEmbedder) EmbeddingModel() string   { return benchModel }
+func (b benchEmbedder) EmbeddingDimensions() int { return b.dimension }
+
+// BenchmarkSimilaritySearch times similarity search by full scan and by
+// vector index on stores of synthetic notes with random embeddings:
+//
+//	go test -run '^$' -bench SimilaritySearch ./internal/app
+func BenchmarkSimilaritySearch(b *testing.B) {
+	ctx := context.Background()
+	db, err := sql.Open("turso", filepath.Join(b.TempDir(), "bench.db"))
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer db.Close()
+	if err := migration.Migrate(db); err != nil {
+		b.Fatal(err)
+	}
+
+	bench := &App{DB: db, Embedder: benchEmbedder{benchDimension}}
+	rng := rand.New(rand.NewPCG(1, benchDimension))
+	queries := make([][]float32, benchQueries)
+	for i := range queries {
+		queries[i] = randomVector(rng, benchDimension)
+	}
+
+	var indexErr error
+	indexed := false
+	notes := 0
+	for _, size := range []int{10_000, 100_000} {
+		if err := insertBenchNotes(ctx, db, rng, notes, size, benchDimension); err != nil {
+			b.Fatal(err)
+		}
+		notes = size
+
+		b.Run(fmt.Sprintf("scan/%d", size), func(b *testing.B) {
+			i := 0
+			for b.Loop() {
+				if _, _, err := bench.scanBySimilarity(ctx, queries[i%len(queries)], NoteFilter{}, -1, benchLimit); err != nil {
+					b.Fatal(err)
+				}
+				i++
+			}
+		})
+
+		if !indexed && indexErr == nil {
+			indexErr = createVectorIndex(ctx, db, benchDimension)
+			indexed = indexErr == nil
+		}
+		if !indexed {
+			b.Logf("vector index unavailable, only full scans were timed: %v", indexErr)
+			continue
+		}
+
+		b.Run(fmt.Sprintf("index/%d", size), func(b *testing.B) {
+			recall, err := indexRecall(ctx, bench, rng)
+			if err != nil {
+				b.Fatal(err)
+			}
+			i := 0
+			for b.Loop() {
+				if _, _, err := bench.rankByVectorIndex(ctx, queries[i%len(queries)], NoteFilter{}, -1, benchLimit); err != nil {
+					b.Fatal(err)
+				}
+				i++
+			}
+			b.ReportMetric(recall, "recall")
+		})
+	}
+}
+
+// indexRecall returns the share of the exact nearest neighbours the vector
+// index finds for random queries.
+func indexRecall(ctx context.Context, bench *App, rng *rand.Rand) (float64, error) {
+	var found, expected int
+	for range benchRecallQueries {
+		query := randomVector(rng, benchDimension)
+		exact, _, err := bench.scanBySimilarity(ctx, query, NoteFilter{}, -1, benchLimit)
+		if err != nil {
+			return 0, err
+		}
+		approximate, _, err := bench.rankByVectorIndex(ctx, query, NoteFilter{}, -1, benchLimit)
+		if err != nil {
+			return 0, err
+		}
+		for _, id := range approximate {
+			if slices.Contains(exact, id) {
+				found++
+			}
+		}
+		expected += len(exact)
+	}
+	if expected == 0 {
+		return 0, nil
+	}
+	return float64(found) / float64(expected), nil
+}
+
+// insertBenchNotes adds notes with random embeddings numbered from up to
+// but excluding to.
+func insertBenchNotes(ctx context.Context, db *sql.DB, rng *rand.Rand, from, to, dimension int) error {
+	const batchSize = 1000
+	for batch := from; batch < to; batch += batchSize {
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		for i := batch; i < min(batch+batchSize, to); i++ {
+			id := fmt.Sprintf("bench-%07d", i)
+			_, err := tx.ExecContext(ctx, `INSERT INTO flashbacks (id, content, type) VALUES (?, ?, 'text')`, id, fmt.Sprintf("Synthetic note %d", i))
+			if err != nil {
+				tx.Rollback()
+				return err
+			}
+			if err := replaceEmbedding(tx, id, randomVector(rng, dimension), benchModel, documentVersion); err != nil {
+				tx.Rollback()
+				return err
+			}
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func randomVector(rng *rand.Rand, dimension int) []float32 {
+	vector := make([]float32, dimension)
+	for i := range vector {
+		vector[i] = rng.Float32()*2 - 1
+	}
+	return vector
+}
@@ -0,0 +1,71 @@
+package asknotes
+
+import (
+	"context"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/yagnikpt/flashback/internal/app"
+	"github.com/yagnikpt/flashback/internal/utils"
+)
+
+// answerStream carries the answer to one question from the goroutine
+// generating it.
+type answerStream struct {
+	parts chan string
+	done  chan answerMsg
+}
+
+type answerPartMsg struct {
+	stream *answerStream
+	part   string
+}
+type answerMsg struct {
+	stream *answerStream
+	answer app.Answer
+	err    error
+}
+type dimensionsMsg struct {
+	width  int
+	height int
+}
+
+// askCmd starts answering question and waits for the first part of the
+// answer. Cancelling ctx stops the answer.
+func askCmd(ctx context.Context, m Model, question string) (*answerStream, tea.Cmd) {
+	stream := &answerStream{
+		parts: make(chan string),
+		done:  make(chan answerMsg, 1),
+	}
+	go func() {
+		answer, err := m.app.Ask(ctx, question, func(part string) {
+			select {
+			case stream.parts <- part:
+			case <-ctx.Done():
+			}
+		})
+		close(stream.parts)
+		stream.done <- answerMsg{stream: stream, answer: answer, err: err}
+	}()
+	return stream, waitForAnswerCmd(stream)
+}
+
+// waitForAnswerCmd returns the next part of the answer, or the answer once
+// it is complete.
+func waitForAnswerCmd(stream *answerStream) tea.Cmd {
+	return func() tea.Msg {
+		if part, ok := <-stream.parts; ok {
+			return answerPartMsg{stream: stream, part: part}
+		}
+		return <-stream.done
+	}
+}
+
+func getDimensionsCmd() tea.Cmd {
+	return func() tea.Msg {
+		width, height := utils.TerminalSize()
+		return dimensionsMsg{
+			width:  width,
+			height: height,
+		}
+	}
+}
@@ -0,0 +1,204 @@
+// Package asknotes answers questions from the notes, streaming the answer
+// as it is written.
+package asknotes
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"charm.land/bubbles/v2/viewport"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/yagnikpt/flashback/internal/app"
+	"github.com/yagnikpt/flashback/internal/components/spinner"
+	"github.com/yagnikpt/flashback/internal/components/textarea"
+	"github.com/yagnikpt/flashback/internal/utils"
+)
+
+type Model struct {
+	app      *app.App
+	textarea textarea.Model
+	spinner  spinner.Model
+	viewport viewport.Model
+	// stream is the answer being generated, if any, and cancel stops it.
+	stream      *answerStream
+	cancel      context.CancelFunc
+	answer      string
+	sources     string
+	feedbackMsg string
+	isLoading   bool
+	showAnswer  bool
+}
+
+func NewModel(app *app.App) Model {
+	t := textarea.NewModel()
+	t.SetHeight(3)
+	t.SetPlaceholder("Ask your notes a question...")
+	s := spinner.NewModel(nil)
+	s.SetDisplayText("Reading the notes...")
+	v := viewport.New()
+	v.SoftWrap = true
+
+	return Model{
+		app:      app,
+		textarea: t,
+		spinner:  s,
+		viewport: v,
+	}
+}
+
+func (m *Model) ResetView() {
+	m.stop()
+	m.answer = ""
+	m.sources = ""
+	m.feedbackMsg = ""
+	m.showAnswer = false
+	m.textarea.Focus()
+}
+
+// stop cancels the answer being generated, if any.
+func (m *Model) stop() {
+	if m.cancel != nil {
+		m.cancel()
+	}
+	m.stream = nil
+	m.cancel = nil
+	m.isLoading = false
+}
+
+func (m Model) Init() tea.Cmd {
+	return tea.Batch(m.textarea.Init(), m.spinner.Init(), getDimensionsCmd())
+}
+
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	var cmds []tea.Cmd
+
+	switch msg := msg.(type) {
+	case answerPartMsg:
+		if msg.stream != m.stream {
+			return m, nil
+		}
+		m.isLoading = false
+		m.answer += msg.part
+		m.render()
+		return m, waitForAnswerCmd(msg.stream)
+
+	case answerMsg:
+		if msg.stream != m.stream {
+			return m, nil
+		}
+		m.stop()
+		switch {
+		case errors.Is(msg.err, app.ErrNothingRelevant):
+			m.feedbackMsg = "Nothing in your notes seems related to that question."
+		case errors.Is(msg.err, context.Canceled):
+			m.feedbackMsg = "Stopped."
+		case msg.err != nil:
+			m.feedbackMsg = "Error answering the question: " + msg.err.Error()
+		case len(msg.answer.Citations) > 0:
+			m.sources = utils.FormatCitations(msg.answer.Sources, msg.answer.Citations)
+		}
+		m.render()
+		return m, nil
+
+	case dimensionsMsg:
+		m.setSize(msg.width, msg.height)
+
+	case tea.WindowSizeMsg:
+		m.setSize(msg.Width, msg.Height)
+
+	case tea.KeyPressMsg:
+		switch msg.String() {
+		case "esc":
+			if m.stream != nil {
+				m.stop()
+				m.feedbackMsg = "Stopped."
+				m.render()
+			} else if m.showAnswer {
+				m.showAnswer = false
+				m.textarea.Focus()
+			}
+			return m, nil
+		case "enter":
+			question := strings.TrimSpace(m.textarea.Value())
+			if m.textarea.Focused() && question != "" {
+				m.stop()
+				m.answer = ""
+				m.sources = ""
+				m.feedbackMsg = ""
+				m.render()
+				m.viewport.GotoTop()
+
+				ctx, cancel := context.WithCancel(context.Background())
+				m.cancel = cancel
+				m.stream, cmd = askCmd(ctx, m, question)
+				m.isLoading = true
+				m.showAnswer = true
+				m.textarea.Blur()
+				return m, tea.Batch(cmd, m.spinner.Init())
+			}
+		}
+	}
+
+	if m.isLoading {
+		newSpinner, cmd := m.spinner.Update(msg)
+		m.spinner = newSpinner.(spinner.Model)
+		cmds = append(cmds, cmd)
+	}
+	newTextarea, cmd := m.textarea.Update(msg)
+	m.textarea = newTextarea.(textarea.Model)
+	cmds = append(cmds, cmd)
+	if m.showAnswer && !m.textarea.Focused() {
+		m.viewport, cmd = m.viewport.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+	return m, tea.Batch(cmds...)
+}
+
+func (m *Model) setSize(width, height int) {
+	m.viewport.SetWidth(width - 2)
+	m.viewport.SetHeight(height - 10)
+	m.spinner.SetWidth(width)
+	m.render()
+}
+
+var (
+	docStyles      = lipgloss.NewStyle().Margin(1, 1).Render
+	feedbackStyles = lipgloss.NewStyle().Foreground(lipgloss.Color("#525252")).Render
+)
+
+// render lays out the answer so far, keeping the view at the end while it
+// grows unless the user scrolled up.
+func (m *Model) render() {
+	following := m.viewport.AtBottom()
+	var b strings.Builder
+	b.WriteString(strings.TrimSpace(m.answer))
+	if m.sources != "" {
+		b.WriteString("\n\n" + m.sources)
+	}
+	if m.feedbackMsg != "" {
+		if b.Len() > 0 {
+			b.WriteString("\n\n")
+		}
+		b.WriteString(feedbackStyles(m.feedbackMsg))
+	}
+	m.viewport.SetContent(b.String())
+	if following {
+		m.viewport.GotoBottom()
+	}
+}
+
+func (m Model) View() tea.View {
+	var builder strings.Builder
+	if m.isLoading {
+		builder.WriteString(m.spinner.View().Content)
+	} else {
+		builder.WriteString(m.textarea.View().Content)
+	}
+	if m.showAnswer {
+		builder.WriteString("\n\n" + m.viewport.View())
+	}
+	return tea.NewView(docStyles(builder.String()))
+}
@@ -2,96 +2,68 @@ package insertnote
 
 import (
 	"context"
-	"fmt"
-	"os"
-	"strings"
+	"errors"
 	"time"
 
 	tea "charm.land/bubbletea/v2"
-	"github.com/yagnikpt/flashback/internal/contentloaders"
-	"golang.org/x/term"
+	"github.com/yagnikpt/flashback/internal/ingest"
+	"github.com/yagnikpt/flashback/internal/utils"
 )
 
 type heightMsg int
 type addNoteMsg struct {
 	success bool
 	err     error
+	// duplicate is the note when it duplicates a saved one and the user
+	// has to choose what to do.
+	duplicate *ingest.Note
+	// mergedInto and updated are the ID of the saved note the new one
+	// duplicated, depending on what was done.
+	mergedInto string
+	updated    string
 }
 
-func addNoteCmd(m Model, content string) tea.Cmd {
+func addNoteCmd(m Model, note *ingest.Note) tea.Cmd {
 	return func() tea.Msg {
 		ctx, cancel := context.WithTimeout(context.Background(), time.Second*15)
 		defer cancel()
 
-		var metadata map[string]string
-		var noteType string
-		if strings.HasPrefix(content, "http") {
-			m.statusChan <- "Fetching webpage content..."
-			noteType = "link"
-			pageContent, err := contentloaders.GetWebPage(ctx, content)
-			if err != nil {
-				return addNoteMsg{
-					success: false,
-					err:     err,
-				}
-			}
-			pageContentWithUrl := fmt.Sprintf("URL: %s\n\n%s", content, pageContent)
-			m.statusChan <- "Generating metadata for webpage..."
-			metadata, err = m.app.GenerateMetadataForWebNote(ctx, pageContentWithUrl)
-			if err != nil {
-				return addNoteMsg{
-					success: false,
-					err:     err,
-				}
-			}
-		} else {
-			m.statusChan <- "Generating metadata for note..."
-			_metadata, err := m.app.GenerateMetadataForSimpleNote(ctx, content)
-			if err != nil {
-				return addNoteMsg{
-					success: false,
-					err:     err,
-				}
-			}
-			metadata = _metadata
-			noteType = "text"
+		events := make(chan ingest.Event)
+		done := make(chan error, 1)
+		go func() {
+			done <- ingest.New(m.app).Run(ctx, note, events)
+		}()
+		for event := range events {
+			m.statusChan <- event.Message
 		}
-		var finalContent strings.Builder
-		userInput := fmt.Sprintf("USER INPUT:\n content: %s\n", content)
-		finalContent.WriteString(userInput)
-		finalContent.WriteString("METADATA:\n")
-		for key, value := range metadata {
-			metadataLine := fmt.Sprintf(" %s: %s\n", key, value)
-			finalContent.WriteString(metadataLine)
-		}
-		m.statusChan <- "Saving the note..."
-		embeddings, err := m.app.GenerateEmbeddingForNote(ctx, finalContent.String(), "RETRIEVAL_DOCUMENT")
-		if err != nil {
-			return addNoteMsg{
-				success: false,
-				err:     err,
-			}
+		err := <-done
+		if errors.Is(err, ingest.ErrDuplicate) {
+			return addNoteMsg{duplicate: note}
 		}
-		err = m.app.InsertNote(ctx, content, noteType, metadata, embeddings)
 		if err != nil {
 			return addNoteMsg{
 				success: false,
 				err:     err,
 			}
 		}
-		return addNoteMsg{
+		msg := addNoteMsg{
 			success: true,
 			err:     nil,
 		}
+		switch {
+		case note.Duplicate == nil:
+		case note.OnDuplicate == ingest.DuplicateMerge:
+			msg.mergedInto = note.ID
+		case note.OnDuplicate == ingest.DuplicateUpdate:
+			msg.updated = note.ID
+		}
+		return msg
 	}
 }
 
 func getHeightCmd() tea.Cmd {
 	return func() tea.Msg {
-		_, height, err := term.GetSize(int(os.Stdout.Fd()))
-		if err != nil {
-			panic(err)
-		}
+		_, height := utils.TerminalSize()
 		return heightMsg(height)
 	}
 }
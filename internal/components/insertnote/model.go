@@ -8,6 +8,8 @@ import (
 	"github.com/yagnikpt/flashback/internal/app"
 	"github.com/yagnikpt/flashback/internal/components/spinner"
 	"github.com/yagnikpt/flashback/internal/components/textarea"
+	"github.com/yagnikpt/flashback/internal/ingest"
+	"github.com/yagnikpt/flashback/internal/utils"
 )
 
 type Model struct {
@@ -18,12 +20,38 @@ type Model struct {
 	feedbackMsg  string
 	isLoading    bool
 	statusChan   chan string
+	// collection is where new notes go; empty uses the configured default.
+	collection string
+	// duplicate is a note that duplicates a saved one, waiting for the
+	// user to choose whether to merge, update or keep it.
+	duplicate *ingest.Note
 }
 
 func (m *Model) ResetView() {
 	m.showFeedback = false
 	m.isLoading = false
 	m.feedbackMsg = ""
+	m.cancelDuplicate()
+}
+
+// cancelDuplicate drops the note waiting on a duplicate choice and puts its
+// text back in the editor.
+func (m *Model) cancelDuplicate() {
+	if m.duplicate != nil {
+		m.textarea.SetValue(m.duplicate.Content)
+		m.duplicate = nil
+	}
+}
+
+// duplicateActions maps keys to what to do with a duplicate note.
+var duplicateActions = map[string]ingest.DuplicateAction{
+	"m": ingest.DuplicateMerge,
+	"u": ingest.DuplicateUpdate,
+	"k": ingest.DuplicateKeep,
+}
+
+func (m *Model) SetCollection(name string) {
+	m.collection = name
 }
 
 func NewModel(app *app.App) Model {
@@ -54,10 +82,19 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case addNoteMsg:
 		res := addNoteMsg(msg)
+		if res.duplicate != nil {
+			m.duplicate = res.duplicate
+			m.isLoading = false
+			return m, nil
+		}
 		if !res.success {
 			m.feedbackMsg = res.err.Error()
+		} else if res.mergedInto != "" {
+			m.feedbackMsg = "Already saved as " + res.mergedInto + "; the note was merged into it."
+		} else if res.updated != "" {
+			m.feedbackMsg = "Updated note " + res.updated + ". Its metadata is being generated again."
 		} else {
-			m.feedbackMsg = "Note successfully added."
+			m.feedbackMsg = "Note saved. Its metadata is being generated in the background."
 		}
 		m.isLoading = false
 		m.showFeedback = true
@@ -78,13 +115,32 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.textarea.SetHeight(height - 4)
 		}
 	case tea.KeyPressMsg:
+		if m.duplicate != nil {
+			if msg.String() == "esc" {
+				m.cancelDuplicate()
+				return m, nil
+			}
+			action, ok := duplicateActions[msg.String()]
+			if !ok {
+				return m, nil
+			}
+			note := m.duplicate
+			note.OnDuplicate = action
+			m.duplicate = nil
+			m.isLoading = true
+			return m, tea.Batch(addNoteCmd(m, note), m.spinner.Init())
+		}
 		switch msg.String() {
 		case "enter":
 			m.showFeedback = false
 			noteContent := m.textarea.Value()
 			if noteContent != "" {
 				m.isLoading = true
-				cmds = append(cmds, addNoteCmd(m, noteContent))
+				note := &ingest.Note{Content: noteContent}
+				if m.collection != "" {
+					note.Collections = []string{m.collection}
+				}
+				cmds = append(cmds, addNoteCmd(m, note))
 				m.textarea.SetValue("")
 				cmds = append(cmds, m.spinner.Init())
 			}
@@ -103,11 +159,19 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 var (
-	docStyles = lipgloss.NewStyle().Margin(1, 1).Render
+	docStyles      = lipgloss.NewStyle().Margin(1, 1).Render
+	feedbackStyles = lipgloss.NewStyle().Foreground(lipgloss.Color("#525252")).Render
 )
 
 func (m Model) View() tea.View {
 	var builder strings.Builder
+	if m.duplicate != nil {
+		saved := m.duplicate.Duplicate.Note
+		builder.WriteString("This looks like " + saved.ID + ", which you already saved (" + m.duplicate.Duplicate.Match() + "):\n\n")
+		builder.WriteString("  " + utils.NoteTitle(saved) + "\n\n")
+		builder.WriteString(feedbackStyles("m merge tags into it • u update it • k keep both • esc cancel"))
+		return tea.NewView(docStyles(builder.String()))
+	}
 	if m.isLoading {
 		builder.WriteString(m.spinner.View().Content)
 	} else {
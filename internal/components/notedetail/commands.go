@@ -0,0 +1,75 @@
+package notedetail
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/yagnikpt/flashback/internal/app"
+	"github.com/yagnikpt/flashback/internal/models"
+)
+
+type snapshotMsg struct {
+	noteID   string
+	snapshot *app.Snapshot
+}
+
+type historyMsg struct {
+	noteID    string
+	revisions []app.Revision
+	err       error
+}
+
+type relatedMsg struct {
+	noteID  string
+	links   []models.FlashbackWithMetadata
+	similar []models.ScoredFlashback
+}
+
+func loadRelatedCmd(m Model, noteID string) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		msg := relatedMsg{noteID: noteID}
+		var err error
+		msg.links, err = m.app.GetLinkedNotes(ctx, noteID)
+		if err != nil {
+			log.Println("Error loading linked notes:", err)
+		}
+		msg.similar, err = m.app.GetRelatedNotes(ctx, noteID, app.RelatedLimit)
+		if err != nil {
+			log.Println("Error loading related notes:", err)
+		}
+		return msg
+	}
+}
+
+func loadSnapshotCmd(m Model, noteID string) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		snapshot, err := m.app.GetSnapshot(ctx, noteID, 0)
+		if err != nil {
+			if !errors.Is(err, sql.ErrNoRows) {
+				log.Println("Error loading saved page:", err)
+			}
+			return snapshotMsg{noteID: noteID}
+		}
+		return snapshotMsg{noteID: noteID, snapshot: &snapshot}
+	}
+}
+
+func loadHistoryCmd(m Model, noteID string) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		revisions, err := m.app.ListRevisions(ctx, noteID)
+		return historyMsg{noteID: noteID, revisions: revisions, err: err}
+	}
+}
@@ -0,0 +1,182 @@
+// Package notedetail shows a note with its related notes and saved page in a
+// scrollable view, or the history of its revisions.
+package notedetail
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"charm.land/bubbles/v2/viewport"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/dustin/go-humanize"
+	"github.com/yagnikpt/flashback/internal/app"
+	"github.com/yagnikpt/flashback/internal/models"
+	"github.com/yagnikpt/flashback/internal/utils"
+)
+
+type Model struct {
+	app      *app.App
+	note     models.FlashbackWithMetadata
+	snapshot *app.Snapshot
+	related  string
+	// history is the rendered list of revisions, empty until loaded.
+	history     string
+	showHistory bool
+	viewport    viewport.Model
+}
+
+func NewModel(app *app.App) Model {
+	v := viewport.New()
+	v.SoftWrap = true
+	return Model{app: app, viewport: v}
+}
+
+// Show displays note and starts loading its saved page.
+func (m *Model) Show(note models.FlashbackWithMetadata) tea.Cmd {
+	m.note = note
+	m.snapshot = nil
+	m.related = ""
+	m.history = ""
+	m.showHistory = false
+	m.render()
+	m.viewport.GotoTop()
+	if note.ID == "" {
+		return nil
+	}
+	return tea.Batch(loadRelatedCmd(*m, note.ID), loadSnapshotCmd(*m, note.ID))
+}
+
+func (m *Model) SetSize(width, height int) {
+	m.viewport.SetWidth(width)
+	m.viewport.SetHeight(height)
+	m.render()
+}
+
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case relatedMsg:
+		if msg.noteID == m.note.ID {
+			m.related = utils.FormatRelatedNotes(msg.links, msg.similar)
+			m.render()
+		}
+		return m, nil
+	case snapshotMsg:
+		if msg.noteID == m.note.ID {
+			m.snapshot = msg.snapshot
+			m.render()
+		}
+		return m, nil
+	case historyMsg:
+		if msg.noteID == m.note.ID {
+			if msg.err != nil {
+				m.history = "Error loading the history: " + msg.err.Error() + "\n"
+			} else {
+				m.history = formatHistory(msg.noteID, msg.revisions)
+			}
+			m.render()
+		}
+		return m, nil
+	case tea.KeyPressMsg:
+		if msg.String() == "h" && m.note.ID != "" {
+			m.showHistory = !m.showHistory
+			m.render()
+			m.viewport.GotoTop()
+			if m.showHistory && m.history == "" {
+				return m, loadHistoryCmd(m, m.note.ID)
+			}
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.viewport, cmd = m.viewport.Update(msg)
+	return m, cmd
+}
+
+var (
+	headingStyles = lipgloss.NewStyle().Bold(true).Render
+	faintStyles   = lipgloss.NewStyle().Foreground(lipgloss.Color("#525252")).Render
+)
+
+func (m *Model) render() {
+	var b strings.Builder
+	if m.showHistory {
+		b.WriteString(headingStyles("History") + " " + faintStyles("(h to go back to the note)") + "\n\n")
+		if m.history == "" {
+			b.WriteString(faintStyles("Loading..."))
+		}
+		b.WriteString(m.history)
+		m.viewport.SetContent(b.String())
+		return
+	}
+
+	b.WriteString(utils.FormatSingleNoteForTUI(m.note))
+	if m.related != "" {
+		b.WriteString("\n" + m.related)
+	}
+	if m.snapshot != nil {
+		saved := m.snapshot.CreatedAt
+		if t, err := utils.ParseTimestamp(saved); err == nil {
+			saved = humanize.RelTime(t, time.Now(), "ago", "from now")
+		}
+		b.WriteString("\n" + headingStyles("Saved page") + " " + faintStyles("("+saved+", flashback read "+m.note.ID+")") + "\n\n")
+		b.WriteString(strings.TrimSpace(m.snapshot.Markdown) + "\n")
+	}
+	if m.note.ID != "" {
+		b.WriteString("\n" + faintStyles("Press h for the history of this note.") + "\n")
+	}
+	m.viewport.SetContent(b.String())
+}
+
+// formatHistory lists revisions newest first, each with the diff from the
+// revision before it.
+func formatHistory(noteID string, revisions []app.Revision) string {
+	if len(revisions) == 0 {
+		return "No revisions yet.\n"
+	}
+
+	var b strings.Builder
+	for i := len(revisions) - 1; i >= 0; i-- {
+		r := revisions[i]
+		saved := r.CreatedAt
+		if t, err := utils.ParseTimestamp(saved); err == nil {
+			saved = humanize.Time(t)
+		}
+		b.WriteString(headingStyles(fmt.Sprintf("Revision %d", r.Number)) + " " + faintStyles("("+saved+", flashback restore "+noteID+" "+strconv.Itoa(r.Number)+")") + "\n")
+		if i == 0 {
+			b.WriteString("Created.\n\n")
+			continue
+		}
+		previous, err := revisionText(revisions[i-1])
+		if err != nil {
+			b.WriteString("Error rendering the revision: " + err.Error() + "\n\n")
+			continue
+		}
+		current, err := revisionText(r)
+		if err != nil {
+			b.WriteString("Error rendering the revision: " + err.Error() + "\n\n")
+			continue
+		}
+		diff := utils.FormatDiff(fmt.Sprintf("revision %d", revisions[i-1].Number), fmt.Sprintf("revision %d", r.Number), previous, current)
+		if diff == "" {
+			diff = "No changes.\n"
+		}
+		b.WriteString(diff + "\n")
+	}
+	return b.String()
+}
+
+func revisionText(r app.Revision) (string, error) {
+	return utils.RenderRevision(r.Content, r.Metadata, r.Tags)
+}
+
+func (m Model) View() tea.View {
+	return tea.NewView(m.viewport.View())
+}
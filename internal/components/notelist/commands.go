@@ -7,15 +7,37 @@ import (
 	"time"
 
 	tea "charm.land/bubbletea/v2"
+	"github.com/yagnikpt/flashback/internal/app"
 	"github.com/yagnikpt/flashback/internal/models"
-	"golang.org/x/term"
+	"github.com/yagnikpt/flashback/internal/utils"
 )
 
 type getAllNotesMsg []models.FlashbackWithMetadata
-type deleteNoteMsg bool
+type deleteNoteMsg struct {
+	id  string
+	err error
+}
+type restoreNoteMsg struct {
+	id  string
+	err error
+}
 type chosenNoteMsg models.FlashbackWithMetadata
 type relayChooseMsg string
 type relayDeleteMsg string
+type relayEditMsg string
+type editPreparedMsg struct {
+	note models.FlashbackWithMetadata
+	path string
+	err  error
+}
+type editorClosedMsg struct {
+	note models.FlashbackWithMetadata
+	path string
+	err  error
+}
+type noteEditedMsg struct {
+	err error
+}
 type dimensionsMsg struct {
 	width  int
 	height int
@@ -26,7 +48,13 @@ func getAllNotesCmd(m Model) tea.Cmd {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 
-		notes, err := m.app.GetAllNotes(ctx)
+		var notes []models.FlashbackWithMetadata
+		var err error
+		if m.collection != "" {
+			notes, err = m.app.ListNotes(ctx, app.NoteFilter{Collection: m.collection})
+		} else {
+			notes, err = m.app.GetAllNotes(ctx)
+		}
 		if err != nil {
 			log.Fatal(err)
 		}
@@ -56,9 +84,21 @@ func deleteNoteCmd(m Model, noteID string) tea.Cmd {
 		err := m.app.DeleteNoteByID(ctx, noteID)
 		if err != nil {
 			log.Println("Error deleting note:", err)
-			return deleteNoteMsg(false)
 		}
-		return deleteNoteMsg(true)
+		return deleteNoteMsg{id: noteID, err: err}
+	}
+}
+
+func restoreNoteCmd(m Model, noteID string) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		err := m.app.RestoreNote(ctx, noteID)
+		if err != nil {
+			log.Println("Error restoring note:", err)
+		}
+		return restoreNoteMsg{id: noteID, err: err}
 	}
 }
 
@@ -74,12 +114,55 @@ func relayDeleteCmd(id string) tea.Cmd {
 	}
 }
 
-func getDimensionsCmd() tea.Cmd {
+func relayEditCmd(id string) tea.Cmd {
 	return func() tea.Msg {
-		width, height, err := term.GetSize(int(os.Stdout.Fd()))
+		return relayEditMsg(id)
+	}
+}
+
+func prepareEditCmd(m Model, noteID string) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		note, err := m.app.GetNoteByID(ctx, noteID)
+		if err != nil {
+			return editPreparedMsg{err: err}
+		}
+		path, err := utils.WriteTempNoteFile(note.Content, note.Metadata, note.Tags)
+		return editPreparedMsg{note: note, path: path, err: err}
+	}
+}
+
+func openEditorCmd(note models.FlashbackWithMetadata, path string) tea.Cmd {
+	return tea.ExecProcess(utils.EditorCommand(path), func(err error) tea.Msg {
+		return editorClosedMsg{note: note, path: path, err: err}
+	})
+}
+
+func saveEditCmd(m Model, note models.FlashbackWithMetadata, path string) tea.Cmd {
+	return func() tea.Msg {
+		defer os.Remove(path)
+
+		content, metadata, tags, err := utils.ReadNoteFile(path)
 		if err != nil {
-			panic(err)
+			return noteEditedMsg{err: err}
 		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancel()
+
+		err = m.app.ApplyNoteEdit(ctx, note, content, metadata, tags, false)
+		if err != nil {
+			log.Println("Error updating note:", err)
+		}
+		return noteEditedMsg{err: err}
+	}
+}
+
+func getDimensionsCmd() tea.Cmd {
+	return func() tea.Msg {
+		width, height := utils.TerminalSize()
 		return dimensionsMsg{
 			width:  width,
 			height: height,
@@ -29,6 +29,9 @@ func newDelegate(keys *delegateKeyMap) list.DefaultDelegate {
 			case key.Matches(msg, keys.choose):
 				return relayChooseCmd(id)
 
+			case key.Matches(msg, keys.edit):
+				return relayEditCmd(id)
+
 			case key.Matches(msg, keys.remove):
 				index := m.Index()
 				m.RemoveItem(index)
@@ -42,7 +45,7 @@ func newDelegate(keys *delegateKeyMap) list.DefaultDelegate {
 		return nil
 	}
 
-	help := []key.Binding{keys.choose, keys.remove}
+	help := []key.Binding{keys.choose, keys.edit, keys.remove, keys.undo}
 
 	d.ShortHelpFunc = func() []key.Binding {
 		return help
@@ -57,7 +60,11 @@ func newDelegate(keys *delegateKeyMap) list.DefaultDelegate {
 
 type delegateKeyMap struct {
 	choose key.Binding
+	edit   key.Binding
 	remove key.Binding
+	// undo restores the last note removed; the list model handles it since
+	// it works without a selected item.
+	undo key.Binding
 }
 
 // Additional short help entries. This satisfies the help.KeyMap interface and
@@ -65,7 +72,9 @@ type delegateKeyMap struct {
 func (d delegateKeyMap) ShortHelp() []key.Binding {
 	return []key.Binding{
 		d.choose,
+		d.edit,
 		d.remove,
+		d.undo,
 	}
 }
 
@@ -75,7 +84,9 @@ func (d delegateKeyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
 		{
 			d.choose,
+			d.edit,
 			d.remove,
+			d.undo,
 		},
 	}
 }
@@ -86,9 +97,17 @@ func newDelegateKeyMap() *delegateKeyMap {
 			key.WithKeys("enter"),
 			key.WithHelp("enter", "choose"),
 		),
+		edit: key.NewBinding(
+			key.WithKeys("e"),
+			key.WithHelp("e", "edit"),
+		),
 		remove: key.NewBinding(
 			key.WithKeys("d", "backspace"),
 			key.WithHelp("d", "delete"),
 		),
+		undo: key.NewBinding(
+			key.WithKeys("u"),
+			key.WithHelp("u", "undo delete"),
+		),
 	}
 }
@@ -1,15 +1,17 @@
 package notelist
 
 import (
+	"os"
 	"time"
 
+	"charm.land/bubbles/v2/key"
 	"charm.land/bubbles/v2/list"
 	tea "charm.land/bubbletea/v2"
 	"charm.land/lipgloss/v2"
 	"github.com/dustin/go-humanize"
 	"github.com/yagnikpt/flashback/internal/app"
+	"github.com/yagnikpt/flashback/internal/components/notedetail"
 	"github.com/yagnikpt/flashback/internal/models"
-	"github.com/yagnikpt/flashback/internal/utils"
 )
 
 type Model struct {
@@ -17,6 +19,13 @@ type Model struct {
 	list        list.Model
 	showingNote bool
 	activeNote  models.FlashbackWithMetadata
+	detail      notedetail.Model
+	keys        *delegateKeyMap
+	// trashed holds the notes moved to the trash, most recent last, so they
+	// can be restored with undo.
+	trashed []string
+	// collection limits the list to a collection; empty lists every note.
+	collection string
 }
 
 func (m *Model) ResetView() {
@@ -24,6 +33,10 @@ func (m *Model) ResetView() {
 	m.showingNote = false
 }
 
+func (m *Model) SetCollection(name string) {
+	m.collection = name
+}
+
 type item struct {
 	id, title, desc string
 }
@@ -34,7 +47,9 @@ func (i item) FilterValue() string { return i.title }
 
 func NewModel(app *app.App) Model {
 	items := make([]list.Item, 0)
-	d := newDelegate(newDelegateKeyMap())
+	keys := newDelegateKeyMap()
+	keys.undo.SetEnabled(false)
+	d := newDelegate(keys)
 	l := list.New(items, d, 0, 0)
 	l.SetShowTitle(false)
 
@@ -43,6 +58,8 @@ func NewModel(app *app.App) Model {
 		list:        l,
 		showingNote: false,
 		activeNote:  models.FlashbackWithMetadata{},
+		detail:      notedetail.NewModel(app),
+		keys:        keys,
 	}
 }
 
@@ -64,25 +81,59 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 		m.list.SetItems(items)
+		m.keys.remove.SetEnabled(len(items) > 0)
 
 	case chosenNoteMsg:
 		note := models.FlashbackWithMetadata(msg)
 		m.activeNote = note
 		m.showingNote = true
+		return m, m.detail.Show(note)
 
-	// case deleteNoteMsg:
+	case deleteNoteMsg:
+		if msg.err != nil {
+			return m, tea.Batch(m.list.NewStatusMessage("Error removing note: "+msg.err.Error()), getAllNotesCmd(m))
+		}
+		m.trashed = append(m.trashed, msg.id)
+		m.keys.undo.SetEnabled(true)
+		return m, m.list.NewStatusMessage("Moved to the trash. Press u to undo.")
+	case restoreNoteMsg:
+		if msg.err != nil {
+			return m, m.list.NewStatusMessage("Error restoring note: " + msg.err.Error())
+		}
+		return m, tea.Batch(m.list.NewStatusMessage("Note restored."), getAllNotesCmd(m))
 
 	case relayChooseMsg:
 		return m, chooseNoteCmd(m, string(msg))
 	case relayDeleteMsg:
 		return m, deleteNoteCmd(m, string(msg))
 
+	case relayEditMsg:
+		return m, prepareEditCmd(m, string(msg))
+	case editPreparedMsg:
+		if msg.err != nil {
+			return m, m.list.NewStatusMessage("Error opening note: " + msg.err.Error())
+		}
+		return m, openEditorCmd(msg.note, msg.path)
+	case editorClosedMsg:
+		if msg.err != nil {
+			os.Remove(msg.path)
+			return m, m.list.NewStatusMessage("Error running editor: " + msg.err.Error())
+		}
+		return m, tea.Batch(m.list.NewStatusMessage("Saving the note..."), saveEditCmd(m, msg.note, msg.path))
+	case noteEditedMsg:
+		if msg.err != nil {
+			return m, m.list.NewStatusMessage("Error updating note: " + msg.err.Error())
+		}
+		return m, tea.Batch(m.list.NewStatusMessage("Note updated."), getAllNotesCmd(m))
+
 	case dimensionsMsg:
 		dims := dimensionsMsg(msg)
 		m.list.SetSize(dims.width, dims.height-3)
+		m.detail.SetSize(dims.width-4, dims.height-3)
 
 	case tea.WindowSizeMsg:
 		m.list.SetSize(msg.Width, msg.Height-3)
+		m.detail.SetSize(msg.Width-4, msg.Height-3)
 
 	case tea.KeyPressMsg:
 		switch msg.String() {
@@ -93,9 +144,20 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, nil
 			}
 		}
+		if !m.showingNote && m.list.FilterState() != list.Filtering && key.Matches(msg, m.keys.undo) {
+			id := m.trashed[len(m.trashed)-1]
+			m.trashed = m.trashed[:len(m.trashed)-1]
+			m.keys.undo.SetEnabled(len(m.trashed) > 0)
+			return m, restoreNoteCmd(m, id)
+		}
 	}
 
 	var cmd tea.Cmd
+	if m.showingNote {
+		newDetail, cmd := m.detail.Update(msg)
+		m.detail = newDetail.(notedetail.Model)
+		return m, cmd
+	}
 	m.list, cmd = m.list.Update(msg)
 
 	return m, cmd
@@ -105,7 +167,7 @@ var docStyles = lipgloss.NewStyle().Margin(0, 2).Render
 
 func (m Model) View() tea.View {
 	if m.showingNote {
-		return tea.NewView(docStyles(utils.FormatSingleNoteForTUI(m.activeNote)))
+		return tea.NewView(docStyles(m.detail.View().Content))
 	}
 	return tea.NewView(m.list.View())
 }
@@ -0,0 +1,68 @@
+package reviewnotes
+
+import (
+	"context"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/yagnikpt/flashback/internal/app"
+	"github.com/yagnikpt/flashback/internal/utils"
+)
+
+type queueMsg struct {
+	cards []app.ReviewCard
+	err   error
+}
+type gradedMsg struct {
+	noteID string
+	state  app.ReviewState
+	err    error
+}
+type excludedMsg struct {
+	noteID string
+	err    error
+}
+type dimensionsMsg struct {
+	width  int
+	height int
+}
+
+func loadQueueCmd(m Model) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		cards, err := m.app.GetReviewQueue(ctx, 0)
+		return queueMsg{cards: cards, err: err}
+	}
+}
+
+func gradeCmd(m Model, noteID string, grade app.Grade) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		state, err := m.app.GradeNote(ctx, noteID, grade)
+		return gradedMsg{noteID: noteID, state: state, err: err}
+	}
+}
+
+func excludeCmd(m Model, noteID string) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		err := m.app.SetNoteReview(ctx, noteID, app.ReviewExclude)
+		return excludedMsg{noteID: noteID, err: err}
+	}
+}
+
+func getDimensionsCmd() tea.Cmd {
+	return func() tea.Msg {
+		width, height := utils.TerminalSize()
+		return dimensionsMsg{
+			width:  width,
+			height: height,
+		}
+	}
+}
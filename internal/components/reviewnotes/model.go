@@ -0,0 +1,211 @@
+// Package reviewnotes shows the notes due for review one at a time, with
+// their summary hidden until revealed, and reschedules them by grade.
+package reviewnotes
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"charm.land/bubbles/v2/viewport"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/dustin/go-humanize"
+	"github.com/yagnikpt/flashback/internal/app"
+	"github.com/yagnikpt/flashback/internal/utils"
+)
+
+type Model struct {
+	app      *app.App
+	viewport viewport.Model
+	cards    []app.ReviewCard
+	// index is the card shown; it equals len(cards) once all were seen.
+	index       int
+	reviewed    int
+	revealed    bool
+	isLoading   bool
+	feedbackMsg string
+}
+
+func NewModel(app *app.App) Model {
+	v := viewport.New()
+	v.SoftWrap = true
+	return Model{app: app, viewport: v}
+}
+
+func (m *Model) ResetView() {
+	m.cards = nil
+	m.index = 0
+	m.reviewed = 0
+	m.revealed = false
+	m.isLoading = true
+	m.feedbackMsg = ""
+	m.render()
+}
+
+func (m Model) Init() tea.Cmd {
+	return tea.Batch(loadQueueCmd(m), getDimensionsCmd())
+}
+
+// grades maps keys to grades, by number and by first letter.
+var grades = map[string]app.Grade{
+	"1": app.GradeAgain, "a": app.GradeAgain,
+	"2": app.GradeHard, "h": app.GradeHard,
+	"3": app.GradeGood, "g": app.GradeGood,
+	"4": app.GradeEasy, "e": app.GradeEasy,
+}
+
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case queueMsg:
+		m.isLoading = false
+		if msg.err != nil {
+			m.feedbackMsg = "Error loading the notes to review: " + msg.err.Error()
+		} else {
+			m.cards = msg.cards
+		}
+		m.render()
+		return m, nil
+
+	case gradedMsg:
+		if m.current() == nil || msg.noteID != m.current().Note.ID {
+			return m, nil
+		}
+		if msg.err != nil {
+			m.feedbackMsg = "Error saving the review: " + msg.err.Error()
+		} else {
+			m.reviewed++
+			m.feedbackMsg = "Next review " + formatInterval(msg.state.Interval) + "."
+			m.next()
+		}
+		m.render()
+		return m, nil
+
+	case excludedMsg:
+		if m.current() == nil || msg.noteID != m.current().Note.ID {
+			return m, nil
+		}
+		if msg.err != nil {
+			m.feedbackMsg = "Error updating the note: " + msg.err.Error()
+		} else {
+			m.feedbackMsg = "Note opted out of review."
+			m.next()
+		}
+		m.render()
+		return m, nil
+
+	case dimensionsMsg:
+		m.setSize(msg.width, msg.height)
+
+	case tea.WindowSizeMsg:
+		m.setSize(msg.Width, msg.Height)
+
+	case tea.KeyPressMsg:
+		card := m.current()
+		if m.isLoading {
+			return m, nil
+		}
+		key := msg.String()
+		if card == nil {
+			if key == "r" {
+				m.ResetView()
+				return m, loadQueueCmd(m)
+			}
+			return m, nil
+		}
+		switch {
+		case !m.revealed && (key == "space" || key == "enter"):
+			m.revealed = true
+			m.feedbackMsg = ""
+			m.render()
+			return m, nil
+		case key == "s":
+			m.feedbackMsg = ""
+			m.next()
+			m.render()
+			return m, nil
+		case key == "x":
+			return m, excludeCmd(m, card.Note.ID)
+		case m.revealed:
+			if grade, ok := grades[key]; ok {
+				return m, gradeCmd(m, card.Note.ID, grade)
+			}
+		}
+	}
+
+	var cmd tea.Cmd
+	m.viewport, cmd = m.viewport.Update(msg)
+	return m, cmd
+}
+
+// current is the card shown, or nil once there are none left.
+func (m *Model) current() *app.ReviewCard {
+	if m.index >= len(m.cards) {
+		return nil
+	}
+	return &m.cards[m.index]
+}
+
+func (m *Model) next() {
+	m.index++
+	m.revealed = false
+	m.viewport.GotoTop()
+}
+
+func (m *Model) setSize(width, height int) {
+	m.viewport.SetWidth(width - 2)
+	m.viewport.SetHeight(height - 9)
+	m.render()
+}
+
+var (
+	docStyles      = lipgloss.NewStyle().Margin(1, 1).Render
+	headingStyles  = lipgloss.NewStyle().Bold(true).Render
+	feedbackStyles = lipgloss.NewStyle().Foreground(lipgloss.Color("#525252")).Render
+)
+
+func (m *Model) render() {
+	card := m.current()
+	if card == nil {
+		m.viewport.SetContent("")
+		return
+	}
+	m.viewport.SetContent(utils.FormatReviewCard(card.Note, m.revealed))
+}
+
+func (m Model) View() tea.View {
+	var b strings.Builder
+	card := m.current()
+	switch {
+	case m.isLoading:
+		b.WriteString(feedbackStyles("Loading the notes to review..."))
+	case card == nil && len(m.cards) == 0:
+		b.WriteString("Nothing to review. Come back later.")
+	case card == nil:
+		b.WriteString(fmt.Sprintf("Reviewed %d of %d notes. Press r to check for more.", m.reviewed, len(m.cards)))
+	default:
+		due := "new"
+		if !card.New {
+			due = "due " + humanize.RelTime(card.State.Due, time.Now(), "ago", "from now")
+		}
+		b.WriteString(headingStyles(fmt.Sprintf("Note %d of %d", m.index+1, len(m.cards))) + "  " + feedbackStyles(due) + "\n\n")
+		b.WriteString(m.viewport.View() + "\n")
+		if m.revealed {
+			b.WriteString(feedbackStyles("1 again • 2 hard • 3 good • 4 easy • s skip • x stop reviewing this note"))
+		} else {
+			b.WriteString(feedbackStyles("space reveal the summary • s skip • x stop reviewing this note"))
+		}
+	}
+	if m.feedbackMsg != "" {
+		b.WriteString("\n\n" + feedbackStyles(m.feedbackMsg))
+	}
+	return tea.NewView(docStyles(b.String()))
+}
+
+// formatInterval describes when a note comes back after interval days.
+func formatInterval(days int) string {
+	if days == 1 {
+		return "tomorrow"
+	}
+	return fmt.Sprintf("in %d days", days)
+}
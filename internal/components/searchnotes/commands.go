@@ -3,15 +3,18 @@ package searchnotes
 import (
 	"context"
 	"log"
-	"os"
 	"time"
 
 	tea "charm.land/bubbletea/v2"
+	"github.com/yagnikpt/flashback/internal/app"
 	"github.com/yagnikpt/flashback/internal/models"
-	"golang.org/x/term"
+	"github.com/yagnikpt/flashback/internal/utils"
 )
 
-type searchResultsMsg []models.FlashbackWithMetadata
+type searchResultsMsg struct {
+	result app.SearchResult
+	err    error
+}
 type relayChooseMsg models.FlashbackWithMetadata
 type dimensionsMsg struct {
 	width  int
@@ -23,12 +26,18 @@ func searchNotesCmd(m Model, query string) tea.Cmd {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 
-		embeddings, _ := m.app.GenerateEmbeddingForNote(ctx, query, "RETRIEVAL_QUERY")
-		flashbacks, err := m.app.RetrieveNotesBySimilarity(ctx, embeddings)
+		opts, err := app.ParseSearchQuery(query)
+		if err != nil {
+			return searchResultsMsg{err: err}
+		}
+		if opts.Filter.Collection == "" {
+			opts.Filter.Collection = m.collection
+		}
+		result, err := m.app.SearchNotes(ctx, opts)
 		if err != nil {
-			log.Fatal("Error retrieving notes:", err)
+			log.Println("Error retrieving notes:", err)
 		}
-		return searchResultsMsg(flashbacks)
+		return searchResultsMsg{result: result, err: err}
 	}
 }
 
@@ -40,10 +49,7 @@ func relayChooseCmd(note models.FlashbackWithMetadata) tea.Cmd {
 
 func getDimensionsCmd() tea.Cmd {
 	return func() tea.Msg {
-		width, height, err := term.GetSize(int(os.Stdout.Fd()))
-		if err != nil {
-			panic(err)
-		}
+		width, height := utils.TerminalSize()
 		return dimensionsMsg{
 			width:  width,
 			height: height,
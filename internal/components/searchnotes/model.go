@@ -1,6 +1,7 @@
 package searchnotes
 
 import (
+	"fmt"
 	"strings"
 	"time"
 
@@ -9,10 +10,10 @@ import (
 	"charm.land/lipgloss/v2"
 	"github.com/dustin/go-humanize"
 	"github.com/yagnikpt/flashback/internal/app"
+	"github.com/yagnikpt/flashback/internal/components/notedetail"
 	"github.com/yagnikpt/flashback/internal/components/spinner"
 	"github.com/yagnikpt/flashback/internal/components/textarea"
 	"github.com/yagnikpt/flashback/internal/models"
-	"github.com/yagnikpt/flashback/internal/utils"
 )
 
 type Model struct {
@@ -21,9 +22,14 @@ type Model struct {
 	spinner      spinner.Model
 	list         list.Model
 	showFeedback bool
+	feedbackMsg  string
 	isLoading    bool
 	showingNote  bool
 	activeNote   models.FlashbackWithMetadata
+	detail       notedetail.Model
+	// collection limits searches to a collection unless the query names
+	// one with in:.
+	collection string
 }
 
 type item struct {
@@ -38,7 +44,7 @@ func (i item) FilterValue() string { return i.title }
 func NewModel(app *app.App) Model {
 	t := textarea.NewModel()
 	t.SetHeight(3)
-	t.SetPlaceholder("Search the notes...")
+	t.SetPlaceholder("Search the notes... (filters: tag:k8s type:url after:7d)")
 	s := spinner.NewModel(nil)
 	s.SetDisplayText("Searching the notes...")
 
@@ -55,20 +61,27 @@ func NewModel(app *app.App) Model {
 		spinner:      s,
 		list:         l,
 		showFeedback: false,
+		feedbackMsg:  "",
 		isLoading:    false,
 		showingNote:  false,
 		activeNote:   models.FlashbackWithMetadata{},
+		detail:       notedetail.NewModel(app),
 	}
 }
 
 func (m *Model) ResetView() {
 	m.showFeedback = false
+	m.feedbackMsg = ""
 	m.isLoading = false
 	m.activeNote = models.FlashbackWithMetadata{}
 	m.showingNote = false
 	m.textarea.Focus()
 }
 
+func (m *Model) SetCollection(name string) {
+	m.collection = name
+}
+
 func (m Model) Init() tea.Cmd {
 	return tea.Batch(m.textarea.Init(), m.spinner.Init(), getDimensionsCmd())
 }
@@ -79,14 +92,26 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case searchResultsMsg:
-		notes := []models.FlashbackWithMetadata(msg)
+		m.feedbackMsg = ""
+		if msg.err != nil {
+			m.feedbackMsg = "Error retrieving notes: " + msg.err.Error()
+		} else if msg.result.Fallback != nil {
+			m.feedbackMsg = "Semantic search unavailable, showing keyword matches only."
+		} else if msg.result.Incomparable > 0 {
+			m.feedbackMsg = fmt.Sprintf("%d notes have embeddings from another model; run flashback reindex --embeddings.", msg.result.Incomparable)
+		}
+		notes := msg.result.Notes
 		items := make([]list.Item, len(notes))
 		for i := range items {
 			t, _ := time.Parse(time.RFC3339, notes[i].CreatedAt)
+			desc := humanize.Time(t)
+			if snippet := msg.result.Snippets[notes[i].ID]; snippet != "" {
+				desc += " · " + snippet
+			}
 			items[i] = item{
 				full:  notes[i],
 				title: notes[i].Content,
-				desc:  humanize.Time(t),
+				desc:  desc,
 			}
 		}
 		m.list.SetItems(items)
@@ -100,13 +125,16 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		note := models.FlashbackWithMetadata(msg)
 		m.activeNote = note
 		m.showingNote = true
+		return m, m.detail.Show(note)
 
 	case dimensionsMsg:
 		dims := dimensionsMsg(msg)
 		m.list.SetSize(dims.width, dims.height-8)
+		m.detail.SetSize(dims.width-2, dims.height-5)
 
 	case tea.WindowSizeMsg:
 		m.list.SetSize(msg.Width, msg.Height-8)
+		m.detail.SetSize(msg.Width-2, msg.Height-5)
 
 	case tea.KeyPressMsg:
 		switch msg.String() {
@@ -120,6 +148,9 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			return m, nil
 		case "enter":
+			if m.showingNote {
+				break
+			}
 			query := m.textarea.Value()
 			if m.textarea.Focused() && query != "" {
 				m.showFeedback = false
@@ -130,6 +161,12 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 	}
 
+	if m.showingNote {
+		newDetail, cmd := m.detail.Update(msg)
+		m.detail = newDetail.(notedetail.Model)
+		return m, tea.Batch(append(cmds, cmd)...)
+	}
+
 	if m.isLoading {
 		newSpinner, cmd := m.spinner.Update(msg)
 		m.spinner = newSpinner.(spinner.Model)
@@ -146,13 +183,14 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 var (
-	docStyles = lipgloss.NewStyle().Margin(1, 1).Render
+	docStyles      = lipgloss.NewStyle().Margin(1, 1).Render
+	feedbackStyles = lipgloss.NewStyle().Foreground(lipgloss.Color("#525252")).Render
 )
 
 func (m Model) View() tea.View {
 	var builder strings.Builder
 	if m.showingNote {
-		return tea.NewView(docStyles(utils.FormatSingleNoteForTUI(m.activeNote)))
+		return tea.NewView(docStyles(m.detail.View().Content))
 	}
 	if m.isLoading {
 		builder.WriteString(m.spinner.View().Content)
@@ -160,6 +198,9 @@ func (m Model) View() tea.View {
 		builder.WriteString(m.textarea.View().Content)
 	}
 	if m.showFeedback {
+		if m.feedbackMsg != "" {
+			builder.WriteString("\n\n" + feedbackStyles(m.feedbackMsg))
+		}
 		builder.WriteString("\n\n" + m.list.View())
 	}
 	return tea.NewView(docStyles(builder.String()))
@@ -10,10 +10,10 @@ import (
 func Run(status <-chan string, altScreen bool) {
 	model := NewModel(status)
 	model.SetAltScreen(altScreen)
-	p := tea.NewProgram(model)
+	p := tea.NewProgram(model, tea.WithOutput(os.Stderr))
 	res, err := p.Run()
 	if err != nil {
-		fmt.Printf("Alas, there's been an error: %v", err)
+		fmt.Fprintf(os.Stderr, "Alas, there's been an error: %v", err)
 		os.Exit(1)
 	}
 	model = res.(Model)
@@ -1,20 +1,15 @@
 package textarea
 
 import (
-	"os"
-
 	tea "charm.land/bubbletea/v2"
-	"golang.org/x/term"
+	"github.com/yagnikpt/flashback/internal/utils"
 )
 
 type setWidthMsg int
 
 func setWidthCmd() tea.Cmd {
 	return func() tea.Msg {
-		width, _, err := term.GetSize(int(os.Stdout.Fd()))
-		if err != nil {
-			panic(err)
-		}
+		width, _ := utils.TerminalSize()
 		return setWidthMsg(width)
 	}
 }
@@ -6,9 +6,43 @@ import (
 	"github.com/BurntSushi/toml"
 )
 
+const (
+	ProviderGemini = "gemini"
+	ProviderOpenAI = "openai"
+)
+
 type Config struct {
-	ShowHelp bool   `toml:"show_help"`
-	APIKey   string `toml:"api_key"`
+	ShowHelp        bool   `toml:"show_help"`
+	APIKey          string `toml:"api_key"`
+	Provider        string `toml:"provider,omitempty"`
+	BaseURL         string `toml:"base_url,omitempty"`
+	GenerationModel string `toml:"generation_model,omitempty"`
+	EmbeddingModel  string `toml:"embedding_model,omitempty"`
+	// EmbeddingDimensions asks the embedding model for vectors of this
+	// size; 0 uses the provider's default.
+	EmbeddingDimensions int `toml:"embedding_dimensions,omitempty"`
+	// SnapshotHTML keeps the raw HTML of saved pages along with their
+	// Markdown.
+	SnapshotHTML bool `toml:"snapshot_html,omitempty"`
+	// RequestsPerMinute limits calls to the AI provider; 0 means no limit.
+	RequestsPerMinute int `toml:"requests_per_minute,omitempty"`
+	// MaxRetries overrides how often failed AI calls are retried.
+	MaxRetries *int `toml:"max_retries,omitempty"`
+	// ReviewNewPerDay overrides how many notes that were never reviewed are
+	// added to review each day.
+	ReviewNewPerDay *int `toml:"review_new_per_day,omitempty"`
+	// TrashRetentionDays overrides how long removed notes stay in the
+	// trash; 0 keeps them until the trash is emptied.
+	TrashRetentionDays *int `toml:"trash_retention_days,omitempty"`
+	// DefaultCollection is the collection new notes are added to when none
+	// is given.
+	DefaultCollection string `toml:"default_collection,omitempty"`
+}
+
+// NeedsAPIKey reports whether the configured provider can't work without
+// an API key. OpenAI-compatible servers running locally usually don't use one.
+func (c Config) NeedsAPIKey() bool {
+	return c.APIKey == "" && (c.Provider == "" || c.Provider == ProviderGemini)
 }
 
 func LoadConfig(filePath string) (Config, error) {
@@ -18,6 +52,7 @@ func LoadConfig(filePath string) (Config, error) {
 		cfg = Config{
 			ShowHelp: true,
 			APIKey:   "",
+			Provider: ProviderGemini,
 		}
 		SaveConfig(filePath, cfg)
 		return cfg, nil
@@ -1,8 +1,10 @@
 package contentloaders
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"strings"
@@ -31,7 +33,34 @@ func formatHead(head *goquery.Selection) string {
 	return strings.Join(lines, "\n\n")
 }
 
+// Page is a fetched web page, cleaned of scripts, styles and navigation.
+type Page struct {
+	// Head lists the tags in the page's head, such as its title and
+	// OpenGraph properties.
+	Head string
+	// Markdown is the body of the page converted to Markdown.
+	Markdown string
+	// HTML is the page as it was served.
+	HTML string
+}
+
+// maxPageSize bounds how much of a page is read.
+const maxPageSize = 10 << 20
+
+// String returns the head followed by the body, as sent to the AI provider.
+func (p *Page) String() string {
+	return p.Head + "\n" + p.Markdown
+}
+
 func GetWebPage(ctx context.Context, target string) (string, error) {
+	page, err := FetchWebPage(ctx, target)
+	if err != nil {
+		return "", err
+	}
+	return page.String(), nil
+}
+
+func FetchWebPage(ctx context.Context, target string) (*Page, error) {
 	target = strings.TrimRight(target, ".,;:!?)")
 	if !strings.HasPrefix(target, "http://") && !strings.HasPrefix(target, "https://") {
 		target = "https://" + target
@@ -46,27 +75,27 @@ func GetWebPage(ctx context.Context, target string) (string, error) {
 	for {
 		req, err := http.NewRequestWithContext(ctx, "GET", target, nil)
 		if err != nil {
-			return "", err
+			return nil, err
 		}
 		req.Header.Set("User-Agent", "facebookexternalhit/1.1")
 		req.Header.Set("sec-ch-ua", `"Chromium";v="142", "Google Chrome";v="142", "Not_A Brand";v="99"`)
 		resp, err := client.Do(req)
 		if err != nil {
-			return "", err
+			return nil, err
 		}
 
 		if resp.StatusCode >= 300 && resp.StatusCode < 400 {
 			location := resp.Header.Get("Location")
 			resp.Body.Close()
 			if location == "" {
-				return "", fmt.Errorf("redirect without location")
+				return nil, fmt.Errorf("redirect without location")
 			}
 
 			// Resolve relative URLs
 			if !strings.HasPrefix(location, "http") {
 				baseURL, err := url.Parse(target)
 				if err != nil {
-					return "", err
+					return nil, err
 				}
 				resolved := baseURL.ResolveReference(&url.URL{Path: location})
 				location = resolved.String()
@@ -76,9 +105,13 @@ func GetWebPage(ctx context.Context, target string) (string, error) {
 		} else if resp.StatusCode == 200 {
 			defer resp.Body.Close()
 
-			doc, err := goquery.NewDocumentFromReader(resp.Body)
+			raw, err := io.ReadAll(io.LimitReader(resp.Body, maxPageSize))
+			if err != nil {
+				return nil, err
+			}
+			doc, err := goquery.NewDocumentFromReader(bytes.NewReader(raw))
 			if err != nil {
-				return "", err
+				return nil, err
 			}
 
 			doc.Find("script").Remove()
@@ -115,18 +148,18 @@ func GetWebPage(ctx context.Context, target string) (string, error) {
 			body := doc.Find("body")
 			bodyHtml, err := body.Html()
 			if err != nil {
-				return "", err
+				return nil, err
 			}
 
 			bodyMarkdown, err := h2m.ConvertString(bodyHtml)
 			if err != nil {
-				return "", err
+				return nil, err
 			}
 
-			return formattedHead + "\n" + bodyMarkdown, nil
+			return &Page{Head: formattedHead, Markdown: bodyMarkdown, HTML: string(raw)}, nil
 		} else {
 			resp.Body.Close()
-			return "", fmt.Errorf("status code error: %d %s", resp.StatusCode, resp.Status)
+			return nil, fmt.Errorf("status code error: %d %s", resp.StatusCode, resp.Status)
 		}
 	}
 }
@@ -0,0 +1,53 @@
+package importer
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/yagnikpt/flashback/internal/app"
+	"github.com/yagnikpt/flashback/internal/models"
+)
+
+// loadBookmarks reads a Netscape bookmark file as exported by browsers,
+// Pocket and Raindrop.
+func loadBookmarks(path string) ([]app.ImportedNote, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	doc, err := goquery.NewDocumentFromReader(f)
+	if err != nil {
+		return nil, err
+	}
+
+	notes := []app.ImportedNote{}
+	doc.Find("a[href]").Each(func(i int, s *goquery.Selection) {
+		href, _ := s.Attr("href")
+		if !strings.HasPrefix(href, "http://") && !strings.HasPrefix(href, "https://") {
+			return
+		}
+		note := app.ImportedNote{Content: href, Type: "url"}
+
+		if addDate, ok := s.Attr("add_date"); ok {
+			if seconds, err := strconv.ParseInt(addDate, 10, 64); err == nil {
+				note.CreatedAt = time.Unix(seconds, 0)
+			}
+		}
+		if tags, ok := s.Attr("tags"); ok {
+			note.Tags = userTags(strings.Split(tags, ","))
+		}
+		if title := strings.TrimSpace(s.Text()); title != "" && title != href {
+			note.Metadata = append(note.Metadata, models.ExportedMetadata{Key: "title", Value: title})
+		}
+		if description := strings.TrimSpace(s.Parent().NextFiltered("dd").Text()); description != "" {
+			note.Metadata = append(note.Metadata, models.ExportedMetadata{Key: "description", Value: description})
+		}
+		notes = append(notes, note)
+	})
+	return notes, nil
+}
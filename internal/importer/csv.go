@@ -0,0 +1,100 @@
+package importer
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yagnikpt/flashback/internal/app"
+	"github.com/yagnikpt/flashback/internal/models"
+	"github.com/yagnikpt/flashback/internal/utils"
+)
+
+// loadCSV reads a Pocket or Raindrop CSV export. Columns are found by name:
+// url, title, tags, time_added or created, and note or excerpt.
+func loadCSV(path string) ([]app.ImportedNote, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	reader := csv.NewReader(f)
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", path, err)
+	}
+	if len(records) == 0 {
+		return []app.ImportedNote{}, nil
+	}
+
+	columns := map[string]int{}
+	for i, name := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	column := func(record []string, names ...string) string {
+		for _, name := range names {
+			if i, ok := columns[name]; ok && i < len(record) {
+				if value := strings.TrimSpace(record[i]); value != "" {
+					return value
+				}
+			}
+		}
+		return ""
+	}
+	if _, ok := columns["url"]; !ok {
+		return nil, fmt.Errorf("%s has no url column", path)
+	}
+
+	notes := []app.ImportedNote{}
+	for _, record := range records[1:] {
+		url := column(record, "url")
+		if url == "" {
+			continue
+		}
+		note := app.ImportedNote{Content: url, Type: "url"}
+		note.CreatedAt = parseCSVTime(column(record, "time_added", "created"))
+
+		// Pocket separates tags with "|", Raindrop with ",".
+		if tags := column(record, "tags"); tags != "" {
+			if strings.Contains(tags, "|") {
+				note.Tags = userTags(strings.Split(tags, "|"))
+			} else {
+				note.Tags = userTags(strings.Split(tags, ","))
+			}
+		}
+		if title := column(record, "title"); title != "" && title != url {
+			note.Metadata = append(note.Metadata, models.ExportedMetadata{Key: "title", Value: title})
+		}
+		if description := column(record, "note", "excerpt"); description != "" {
+			note.Metadata = append(note.Metadata, models.ExportedMetadata{Key: "description", Value: description})
+		}
+		notes = append(notes, note)
+	}
+	return notes, nil
+}
+
+// parseCSVTime accepts Unix seconds (Pocket) and RFC 3339 (Raindrop).
+func parseCSVTime(value string) time.Time {
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0)
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t
+	}
+	t, _ := utils.ParseTimestamp(value)
+	return t
+}
+
+func userTags(names []string) []models.ExportedTag {
+	names = utils.CleanTags(names)
+	tags := make([]models.ExportedTag, len(names))
+	for i, name := range names {
+		tags[i] = models.ExportedTag{Name: name, Source: app.TagSourceUser}
+	}
+	return tags
+}
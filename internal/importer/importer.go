@@ -0,0 +1,204 @@
+// Package importer reads notes exported from flashback or other tools and
+// stores them, resuming interrupted imports.
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/yagnikpt/flashback/internal/app"
+)
+
+type Format string
+
+const (
+	FormatJSON      Format = "json"
+	FormatMarkdown  Format = "markdown"
+	FormatBookmarks Format = "bookmarks"
+	FormatCSV       Format = "csv"
+)
+
+var Formats = []Format{FormatJSON, FormatMarkdown, FormatBookmarks, FormatCSV}
+
+func ParseFormat(value string) (Format, error) {
+	switch value {
+	case "md":
+		value = string(FormatMarkdown)
+	case "html":
+		value = string(FormatBookmarks)
+	}
+	for _, f := range Formats {
+		if string(f) == value {
+			return f, nil
+		}
+	}
+
+	names := make([]string, len(Formats))
+	for i, f := range Formats {
+		names[i] = string(f)
+	}
+	return "", fmt.Errorf("invalid import format %q (expected %s)", value, strings.Join(names, ", "))
+}
+
+// Detect guesses the format of path from its extension, or its first bytes
+// when the extension isn't known. Directories are Markdown vaults.
+func Detect(path string) (Format, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if info.IsDir() {
+		return FormatMarkdown, nil
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return FormatJSON, nil
+	case ".html", ".htm":
+		return FormatBookmarks, nil
+	case ".csv":
+		return FormatCSV, nil
+	case ".md", ".markdown":
+		return FormatMarkdown, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	head, _ := bufio.NewReader(f).Peek(512)
+	head = bytes.TrimSpace(head)
+	switch {
+	case bytes.HasPrefix(head, []byte("{")):
+		return FormatJSON, nil
+	case bytes.HasPrefix(bytes.ToUpper(head), []byte("<!DOCTYPE NETSCAPE-BOOKMARK-FILE")):
+		return FormatBookmarks, nil
+	}
+	return "", fmt.Errorf("can't tell the format of %s, pass --format", path)
+}
+
+// Load reads every note in path.
+func Load(path string, format Format) ([]app.ImportedNote, error) {
+	switch format {
+	case FormatJSON:
+		return loadJSON(path)
+	case FormatMarkdown:
+		return loadMarkdown(path)
+	case FormatBookmarks:
+		return loadBookmarks(path)
+	case FormatCSV:
+		return loadCSV(path)
+	default:
+		return nil, fmt.Errorf("unsupported import format %q", format)
+	}
+}
+
+// Options control how notes are stored.
+type Options struct {
+	// NoAI stores notes without queueing jobs to generate metadata or
+	// embeddings.
+	NoAI bool
+}
+
+// Progress is reported after every note.
+type Progress struct {
+	app.ImportProgress
+	// Current is the content of the note that was just handled.
+	Current string
+	// Resumed is set when the import continued an interrupted one.
+	Resumed bool
+}
+
+// Run stores notes, skipping those whose content already exists. Progress is
+// saved after every note under source, so running it again with the same
+// notes continues after the last one handled. Unless opts.NoAI is set, jobs
+// are queued to generate the metadata and embeddings notes are missing.
+func Run(ctx context.Context, a *app.App, source string, notes []app.ImportedNote, opts Options, report func(Progress)) (app.ImportProgress, error) {
+	progress, err := a.GetImportProgress(ctx, source, fingerprint(notes))
+	if err != nil {
+		return progress, err
+	}
+	resumed := progress.Position > 0 && progress.Position < len(notes)
+	if progress.Position >= len(notes) {
+		progress = app.ImportProgress{Source: source, Fingerprint: progress.Fingerprint}
+	}
+	progress.Total = len(notes)
+
+	for progress.Position < len(notes) {
+		if err := ctx.Err(); err != nil {
+			return progress, err
+		}
+		note := notes[progress.Position]
+
+		existing, err := a.FindNoteByContent(ctx, note.Content)
+		if err != nil {
+			return progress, err
+		}
+		if existing != "" {
+			progress.Skipped++
+		} else {
+			if !opts.NoAI {
+				note.Job = enrichmentJob(note)
+			}
+			if _, err := a.InsertImportedNote(ctx, note); err != nil {
+				if ctx.Err() != nil {
+					return progress, ctx.Err()
+				}
+				log.Println("Error importing note:", err, note.Content)
+				progress.Failed++
+			} else {
+				progress.Imported++
+			}
+		}
+
+		progress.Position++
+		// A cancelled ctx must not prevent recording the note just stored.
+		if err := a.SaveImportProgress(context.WithoutCancel(ctx), progress); err != nil {
+			return progress, err
+		}
+		report(Progress{ImportProgress: progress, Current: note.Content, Resumed: resumed})
+	}
+
+	return progress, nil
+}
+
+// enrichmentJob returns the job that generates what note is missing.
+func enrichmentJob(note app.ImportedNote) string {
+	switch {
+	case !hasGeneratedMetadata(note):
+		return app.JobEnrich
+	case len(note.Embedding) == 0:
+		return app.JobEmbed
+	default:
+		return ""
+	}
+}
+
+func hasGeneratedMetadata(note app.ImportedNote) bool {
+	for _, m := range note.Metadata {
+		if m.Key == "tldr" {
+			return true
+		}
+	}
+	return false
+}
+
+// fingerprint identifies a list of notes, to tell whether saved progress
+// belongs to it.
+func fingerprint(notes []app.ImportedNote) string {
+	h := sha256.New()
+	for _, note := range notes {
+		h.Write([]byte(note.Content))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,47 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/yagnikpt/flashback/internal/app"
+	"github.com/yagnikpt/flashback/internal/models"
+	"github.com/yagnikpt/flashback/internal/utils"
+)
+
+// loadJSON reads a dump written by flashback export.
+func loadJSON(path string) ([]app.ImportedNote, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var dump models.ExportDump
+	if err := json.Unmarshal(data, &dump); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", path, err)
+	}
+	if dump.Version < 1 || dump.Version > models.ExportVersion {
+		return nil, fmt.Errorf("unsupported export version %d", dump.Version)
+	}
+
+	notes := make([]app.ImportedNote, 0, len(dump.Notes))
+	for _, n := range dump.Notes {
+		createdAt, _ := utils.ParseTimestamp(n.CreatedAt)
+		noteType, err := app.ParseNoteType(n.Type)
+		if err != nil {
+			noteType = n.Type
+		}
+		notes = append(notes, app.ImportedNote{
+			Content:          n.Content,
+			Type:             noteType,
+			CreatedAt:        createdAt,
+			Metadata:         n.Metadata,
+			Tags:             n.Tags,
+			Embedding:        n.Embedding,
+			EmbeddingModel:   n.EmbeddingModel,
+			EmbeddingVersion: n.EmbeddingVersion,
+		})
+	}
+	return notes, nil
+}
@@ -0,0 +1,173 @@
+package importer
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/yagnikpt/flashback/internal/app"
+	"github.com/yagnikpt/flashback/internal/models"
+	"github.com/yagnikpt/flashback/internal/utils"
+	"go.yaml.in/yaml/v3"
+)
+
+// Front matter keys that describe the note itself rather than metadata.
+var reservedKeys = map[string]bool{
+	"id": true, "type": true, "tags": true, "url": true,
+	"created": true, "created_at": true, "date": true,
+}
+
+var fencedBlock = regexp.MustCompile("(?s)^```[a-z]*\n(.*)\n```$")
+
+// loadMarkdown reads a Markdown file, or every Markdown file below a
+// directory, such as a vault written by flashback export. Files may have
+// YAML (---) or TOML (+++) front matter.
+func loadMarkdown(path string) ([]app.ImportedNote, error) {
+	notes := []app.ImportedNote{}
+	err := filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != path && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		ext := strings.ToLower(filepath.Ext(p))
+		if ext != ".md" && ext != ".markdown" {
+			return nil
+		}
+
+		note, err := loadMarkdownFile(p)
+		if err != nil {
+			return fmt.Errorf("%s: %w", p, err)
+		}
+		if note.Content != "" {
+			notes = append(notes, note)
+		}
+		return nil
+	})
+	return notes, err
+}
+
+func loadMarkdownFile(path string) (app.ImportedNote, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return app.ImportedNote{}, err
+	}
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+
+	header := map[string]any{}
+	body := text
+	if strings.HasPrefix(text, "+++\n") {
+		content, metadata, tags, err := utils.ParseNoteFile(text)
+		if err != nil {
+			return app.ImportedNote{}, err
+		}
+		for key, value := range metadata {
+			header[key] = value
+		}
+		header["tags"] = tags
+		body = content
+	} else if rest, ok := strings.CutPrefix(text, "---\n"); ok {
+		raw, content, found := strings.Cut(rest, "\n---")
+		if !found {
+			return app.ImportedNote{}, fmt.Errorf("front matter is not closed with ---")
+		}
+		if err := yaml.Unmarshal([]byte(raw), &header); err != nil {
+			return app.ImportedNote{}, fmt.Errorf("error parsing front matter: %w", err)
+		}
+		body = strings.TrimPrefix(content, "\n")
+	}
+	body = strings.TrimSpace(body)
+
+	note := app.ImportedNote{Type: "text"}
+	if noteType, ok := header["type"].(string); ok {
+		if t, err := app.ParseNoteType(noteType); err == nil {
+			note.Type = t
+		}
+	}
+
+	switch url, _ := header["url"].(string); {
+	case url != "":
+		// The body of an exported URL note only repeats the link and tldr.
+		note.Type = "url"
+		note.Content = url
+	case note.Type == "command":
+		if m := fencedBlock.FindStringSubmatch(body); m != nil {
+			body = m[1]
+		}
+		note.Content = body
+	default:
+		note.Content = body
+	}
+
+	note.CreatedAt = frontMatterTime(header)
+	if note.CreatedAt.IsZero() {
+		if info, err := os.Stat(path); err == nil {
+			note.CreatedAt = info.ModTime()
+		}
+	}
+
+	note.Tags = userTags(frontMatterStrings(header["tags"]))
+	for _, key := range sortedAnyKeys(header) {
+		if reservedKeys[key] {
+			continue
+		}
+		value := header[key]
+		if s, ok := value.(string); ok {
+			note.Metadata = append(note.Metadata, models.ExportedMetadata{Key: key, Value: s})
+		} else if value != nil {
+			note.Metadata = append(note.Metadata, models.ExportedMetadata{Key: key, Value: fmt.Sprint(value)})
+		}
+	}
+	return note, nil
+}
+
+func frontMatterTime(header map[string]any) time.Time {
+	for _, key := range []string{"created", "created_at", "date"} {
+		switch value := header[key].(type) {
+		case time.Time:
+			return value
+		case string:
+			if t, err := utils.ParseTimestamp(value); err == nil {
+				return t
+			}
+			if t, err := time.Parse(time.DateOnly, value); err == nil {
+				return t
+			}
+		}
+	}
+	return time.Time{}
+}
+
+// frontMatterStrings accepts a list or a comma separated string, as both are
+// common for tags.
+func frontMatterStrings(value any) []string {
+	switch value := value.(type) {
+	case []string:
+		return value
+	case []any:
+		var out []string
+		for _, v := range value {
+			out = append(out, fmt.Sprint(v))
+		}
+		return out
+	case string:
+		return strings.Split(value, ",")
+	}
+	return nil
+}
+
+func sortedAnyKeys(m map[string]any) []string {
+	keys := make(map[string]string, len(m))
+	for key := range m {
+		keys[key] = ""
+	}
+	return utils.SortedKeys(keys)
+}
@@ -0,0 +1,431 @@
+// Package ingest turns user input into a stored note. Notes go through a
+// pipeline of stages:
+//
+//	detect → dedupe → load → enrich → embed → store
+//
+// The add command and the TUI store the note right after detecting its type
+// and checking for duplicates (New), and a worker runs the remaining stages
+// later from the jobs queue (Enrichment). Progress is reported as Events on
+// a channel.
+package ingest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/yagnikpt/flashback/internal/app"
+	"github.com/yagnikpt/flashback/internal/contentloaders"
+	"github.com/yagnikpt/flashback/internal/providers"
+	"github.com/yagnikpt/flashback/internal/utils"
+)
+
+type Stage string
+
+const (
+	StageDetect Stage = "detect"
+	StageDedupe Stage = "dedupe"
+	StageLoad   Stage = "load"
+	StageEnrich Stage = "enrich"
+	StageEmbed  Stage = "embed"
+	StageStore  Stage = "store"
+)
+
+// Event reports that a stage is working on a note.
+type Event struct {
+	Stage   Stage
+	Message string
+}
+
+// Note is the state passed from stage to stage. Stages skip work whose
+// result is already present, e.g. Enrich doesn't run when Generated is set.
+type Note struct {
+	// ID is set by Store, or up front when enriching a stored note.
+	ID      string
+	Content string
+	// Tags are the user's own tags.
+	Tags []string
+	// Collections are the collections the note is added to; the configured
+	// default collection is used when there are none.
+	Collections []string
+	Type        string
+	// Page is the text of the page behind a URL note, set by Load, as sent
+	// to the AI provider.
+	Page string
+	// Body is the page's main text, which is saved as a snapshot and split
+	// into passages with their own embeddings. HTML is the raw page, kept
+	// when the config asks for it.
+	Body   string
+	HTML   string
+	Chunks []app.Chunk
+	// Metadata is the metadata from the user or an import, which takes
+	// precedence over Generated.
+	Metadata  map[string]string
+	Generated map[string]string
+	Embedding []float32
+	// Duplicate is a stored note this one duplicates, and OnDuplicate what
+	// to do about it; empty means DuplicateAsk. Deduplicate looks for one
+	// unless it is already set or OnDuplicate is DuplicateKeep.
+	Duplicate   *app.Duplicate
+	OnDuplicate DuplicateAction
+}
+
+// DuplicateAction is what to do with a new note that duplicates a stored one.
+type DuplicateAction string
+
+const (
+	// DuplicateAsk leaves the choice to the user: Deduplicate stops with
+	// ErrDuplicate.
+	DuplicateAsk DuplicateAction = "ask"
+	// DuplicateMerge adds the new note's tags to the stored note instead of
+	// saving it.
+	DuplicateMerge DuplicateAction = "merge"
+	// DuplicateUpdate replaces the content of the stored note with the new
+	// one and generates its metadata again.
+	DuplicateUpdate DuplicateAction = "update"
+	// DuplicateKeep saves the new note alongside the stored one.
+	DuplicateKeep DuplicateAction = "keep"
+)
+
+// ErrDuplicate is returned by Deduplicate when the note duplicates a stored
+// one and OnDuplicate is DuplicateAsk. Duplicate is set, so the caller can
+// ask what to do, set OnDuplicate and run the pipeline again.
+var ErrDuplicate = errors.New("the note duplicates a saved one")
+
+func ParseDuplicateAction(action string) (DuplicateAction, error) {
+	switch DuplicateAction(action) {
+	case DuplicateAsk, DuplicateMerge, DuplicateUpdate, DuplicateKeep:
+		return DuplicateAction(action), nil
+	default:
+		return "", fmt.Errorf("invalid duplicate action %q (expected ask, merge, update or keep)", action)
+	}
+}
+
+// AllMetadata merges Metadata over Generated. It is nil when both are.
+func (n *Note) AllMetadata() map[string]string {
+	if n.Metadata == nil && n.Generated == nil {
+		return nil
+	}
+	all := make(map[string]string, len(n.Metadata)+len(n.Generated))
+	for key, value := range n.Generated {
+		all[key] = value
+	}
+	for key, value := range n.Metadata {
+		all[key] = value
+	}
+	return all
+}
+
+// Step is one stage of a pipeline. emit reports progress.
+type Step struct {
+	Stage Stage
+	Run   func(ctx context.Context, note *Note, emit func(message string)) error
+}
+
+type Pipeline struct {
+	Steps []Step
+}
+
+// New returns the pipeline used when adding a note. The note is stored
+// without metadata or embedding, and a job is queued to enrich it, unless it
+// duplicates a stored note.
+func New(a *app.App) *Pipeline {
+	return &Pipeline{Steps: []Step{Detect(), Deduplicate(a), Store(a)}}
+}
+
+// Enrichment returns the pipeline that generates the metadata and embedding
+// of a stored note.
+func Enrichment(a *app.App) *Pipeline {
+	return &Pipeline{Steps: []Step{Load(a), Enrich(a), Embed(a), Save(a)}}
+}
+
+// Error is returned when a stage fails.
+type Error struct {
+	Stage Stage
+	Err   error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %v", e.Stage.describe(), e.Err)
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+func (s Stage) describe() string {
+	switch s {
+	case StageDedupe:
+		return "error checking for duplicates"
+	case StageLoad:
+		return "error fetching webpage"
+	case StageEnrich:
+		return "error generating metadata"
+	case StageEmbed:
+		return "error generating embedding"
+	case StageStore:
+		return "error saving note"
+	default:
+		return "error processing note"
+	}
+}
+
+// Run passes note through every step in order, stopping at the first error,
+// which is returned as an *Error. Progress is sent on events, which may be
+// nil, and which Run closes when it returns.
+func (p *Pipeline) Run(ctx context.Context, note *Note, events chan<- Event) error {
+	if events != nil {
+		defer close(events)
+	}
+
+	for _, step := range p.Steps {
+		emit := func(message string) {
+			if events == nil {
+				return
+			}
+			select {
+			case events <- Event{Stage: step.Stage, Message: message}:
+			case <-ctx.Done():
+			}
+		}
+		if err := step.Run(ctx, note, emit); err != nil {
+			return &Error{Stage: step.Stage, Err: err}
+		}
+	}
+	return nil
+}
+
+// shellPrograms are the programs that make a one-line note starting with
+// one of them a command. Programs named like English words (go, make, find)
+// are left out so that sentences aren't taken for commands.
+var shellPrograms = map[string]bool{
+	"apt": true, "apt-get": true, "aws": true, "brew": true, "cargo": true,
+	"chmod": true, "chown": true, "curl": true, "docker": true, "ffmpeg": true,
+	"gcloud": true, "git": true, "grep": true, "helm": true, "kubectl": true,
+	"npm": true, "npx": true, "pip": true, "pnpm": true, "podman": true,
+	"psql": true, "rsync": true, "scp": true, "sed": true, "ssh": true,
+	"sudo": true, "systemctl": true, "tar": true, "terraform": true,
+	"wget": true, "yarn": true,
+}
+
+// shellBlock matches a note made of one fenced shell code block.
+var shellBlock = regexp.MustCompile("(?s)^```(?:sh|bash|zsh|shell|console)\n(.*)\n```$")
+
+// DetectType tells URL notes, commands and text apart. A URL note is a
+// single http(s) link. A command is a fenced shell code block, or a single
+// line starting with a "$ " prompt or a common command-line program
+// followed by arguments.
+func DetectType(content string) string {
+	content = strings.TrimSpace(content)
+	fields := strings.Fields(content)
+	isLink := strings.HasPrefix(content, "http://") || strings.HasPrefix(content, "https://")
+	if isLink && len(fields) == 1 {
+		return "url"
+	}
+	if shellBlock.MatchString(content) {
+		return "command"
+	}
+	if !strings.Contains(content, "\n") && len(fields) >= 2 && (fields[0] == "$" || shellPrograms[fields[0]]) {
+		return "command"
+	}
+	return "text"
+}
+
+// commandText returns the command in content without the code fence or the
+// prompt DetectType accepts around it.
+func commandText(content string) string {
+	if m := shellBlock.FindStringSubmatch(content); m != nil {
+		return strings.TrimSpace(m[1])
+	}
+	return strings.TrimSpace(strings.TrimPrefix(content, "$ "))
+}
+
+// Detect sets the note's type unless it is already known.
+func Detect() Step {
+	return Step{Stage: StageDetect, Run: func(ctx context.Context, note *Note, emit func(string)) error {
+		note.Content = strings.TrimSpace(note.Content)
+		if note.Content == "" {
+			return fmt.Errorf("note is empty")
+		}
+		if note.Type == "" {
+			note.Type = DetectType(note.Content)
+			if note.Type == "command" {
+				note.Content = commandText(note.Content)
+			}
+		}
+		return nil
+	}}
+}
+
+// Deduplicate canonicalizes URLs and looks for stored notes the new note
+// duplicates, then does what OnDuplicate says. When the stored note is
+// merged or updated its ID is set, and Store doesn't save the note again.
+func Deduplicate(a *app.App) Step {
+	return Step{Stage: StageDedupe, Run: func(ctx context.Context, note *Note, emit func(string)) error {
+		if note.Type == "url" {
+			note.Content = utils.CanonicalURL(note.Content)
+		}
+		if note.OnDuplicate == "" {
+			note.OnDuplicate = DuplicateAsk
+		}
+		if note.Duplicate == nil && note.OnDuplicate != DuplicateKeep {
+			emit("Checking for duplicates...")
+			duplicates, err := FindDuplicates(ctx, a, note)
+			if err != nil {
+				return err
+			}
+			if len(duplicates) > 0 {
+				note.Duplicate = &duplicates[0]
+			}
+		}
+		if note.Duplicate == nil {
+			return nil
+		}
+
+		id := note.Duplicate.Note.ID
+		switch note.OnDuplicate {
+		case DuplicateMerge:
+			emit("Merging with the saved note...")
+			if err := a.AddNoteTags(ctx, id, note.Tags); err != nil {
+				return err
+			}
+			note.ID = id
+		case DuplicateUpdate:
+			emit("Updating the saved note...")
+			if err := a.ReplaceNoteContent(ctx, id, note.Content, note.Tags); err != nil {
+				return err
+			}
+			note.ID = id
+		case DuplicateAsk:
+			return ErrDuplicate
+		}
+		return nil
+	}}
+}
+
+// FindDuplicates returns the stored notes a new note would duplicate, exact
+// matches first.
+func FindDuplicates(ctx context.Context, a *app.App, note *Note) ([]app.Duplicate, error) {
+	content := strings.TrimSpace(note.Content)
+	noteType := note.Type
+	if noteType == "" {
+		noteType = DetectType(content)
+	}
+	if noteType == "url" {
+		content = utils.CanonicalURL(content)
+	}
+	return a.FindDuplicates(ctx, content, noteType, app.DuplicateSimilarity)
+}
+
+// Load fetches the page behind URL notes.
+func Load(a *app.App) Step {
+	return Step{Stage: StageLoad, Run: func(ctx context.Context, note *Note, emit func(string)) error {
+		if note.Type != "url" || note.Page != "" || note.Generated != nil {
+			return nil
+		}
+		emit("Fetching webpage content...")
+		page, err := contentloaders.FetchWebPage(ctx, note.Content)
+		if err != nil {
+			return err
+		}
+		note.Page = fmt.Sprintf("URL: %s\n\n%s", note.Content, page)
+		note.Body = page.Markdown
+		if a.Config.SnapshotHTML {
+			note.HTML = page.HTML
+		}
+		note.Chunks = nil
+		return nil
+	}}
+}
+
+// Enrich generates metadata for notes that have none.
+func Enrich(a *app.App) Step {
+	return Step{Stage: StageEnrich, Run: func(ctx context.Context, note *Note, emit func(string)) error {
+		if note.Generated != nil {
+			return nil
+		}
+		var err error
+		if note.Type == "url" {
+			emit("Generating metadata for webpage...")
+			note.Generated, err = a.GenerateMetadataForWebNote(ctx, note.Page)
+		} else {
+			emit("Generating metadata for note...")
+			note.Generated, err = a.GenerateMetadataForSimpleNote(ctx, note.Content)
+		}
+		return err
+	}}
+}
+
+// Embed generates the note's embedding from its content, metadata and tags,
+// and one for each passage of its page unless the stored passages were
+// already embedded from the same page by the configured embedder.
+func Embed(a *app.App) Step {
+	return Step{Stage: StageEmbed, Run: func(ctx context.Context, note *Note, emit func(string)) error {
+		if note.Embedding == nil {
+			emit("Generating embedding...")
+			embedding, err := a.GenerateDocumentEmbedding(ctx, note.Content, note.AllMetadata(), note.Tags)
+			if err != nil {
+				return err
+			}
+			note.Embedding = embedding
+		}
+
+		if note.Body == "" || note.Chunks != nil {
+			return nil
+		}
+		if note.ID != "" {
+			current, err := a.ChunksCurrent(ctx, note.ID, note.Body)
+			if err != nil || current {
+				return err
+			}
+		}
+		passages := app.SplitChunks(note.Body)
+		chunks := make([]app.Chunk, 0, len(passages))
+		for i, passage := range passages {
+			emit(fmt.Sprintf("Embedding page passages (%d/%d)...", i+1, len(passages)))
+			embedding, err := a.GenerateEmbeddingForNote(ctx, passage, providers.TaskRetrievalDocument)
+			if err != nil {
+				return err
+			}
+			chunks = append(chunks, app.Chunk{Text: passage, Embedding: embedding})
+		}
+		note.Chunks = chunks
+		return nil
+	}}
+}
+
+// Store saves a new note. Without an embedding, a job is queued to run the
+// missing stages. Notes merged into a stored one by Deduplicate aren't saved
+// again, but the stored note is still added to the note's collections.
+func Store(a *app.App) Step {
+	return Step{Stage: StageStore, Run: func(ctx context.Context, note *Note, emit func(string)) error {
+		if note.ID == "" {
+			emit("Saving the note...")
+			id, err := a.InsertNote(ctx, note.Content, note.Type, note.AllMetadata(), note.Tags, note.Embedding)
+			if err != nil {
+				return err
+			}
+			note.ID = id
+		}
+
+		collections := note.Collections
+		if len(collections) == 0 && a.Config.DefaultCollection != "" {
+			collections = []string{a.Config.DefaultCollection}
+		}
+		return a.AddNoteToCollections(ctx, note.ID, collections)
+	}}
+}
+
+// Save stores the generated metadata and embedding of an existing note.
+func Save(a *app.App) Step {
+	return Step{Stage: StageStore, Run: func(ctx context.Context, note *Note, emit func(string)) error {
+		emit("Saving the note...")
+		if err := a.StoreEnrichment(ctx, note.ID, note.Generated, note.Embedding); err != nil {
+			return err
+		}
+		if note.Body == "" {
+			return nil
+		}
+		return a.StorePage(ctx, note.ID, note.Body, note.HTML, note.Chunks)
+	}}
+}
@@ -0,0 +1,194 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/yagnikpt/flashback/internal/app"
+	"github.com/yagnikpt/flashback/internal/models"
+)
+
+func TestDetectType(t *testing.T) {
+	tests := []struct {
+		content string
+		want    string
+	}{
+		{"https://example.com/article", "url"},
+		{"http://example.com", "url"},
+		{"  https://example.com/article\n", "url"},
+		{"https://example.com/a https://example.com/b", "text"},
+		{"read https://example.com/article later", "text"},
+		{"ftp://example.com/file", "text"},
+		{"kubectl rollout restart deployment web", "command"},
+		{"$ make build", "command"},
+		{"```sh\nls -la\n```", "command"},
+		{"git", "text"},
+		{"go to the store", "text"},
+		{"git push\ngit pull", "text"},
+		{"", "text"},
+	}
+	for _, tt := range tests {
+		if got := DetectType(tt.content); got != tt.want {
+			t.Errorf("DetectType(%q) = %q, want %q", tt.content, got, tt.want)
+		}
+	}
+}
+
+func TestDetectCommand(t *testing.T) {
+	tests := []struct {
+		content string
+		want    string
+	}{
+		{"$ make build", "make build"},
+		{"```bash\ndocker compose up -d\n```", "docker compose up -d"},
+		{"git push --force-with-lease", "git push --force-with-lease"},
+	}
+	for _, tt := range tests {
+		note := Note{Content: tt.content}
+		if err := Detect().Run(context.Background(), &note, func(string) {}); err != nil {
+			t.Fatal(err)
+		}
+		if note.Type != "command" || note.Content != tt.want {
+			t.Errorf("Detect(%q) = %s %q, want command %q", tt.content, note.Type, note.Content, tt.want)
+		}
+	}
+}
+
+func TestDeduplicate(t *testing.T) {
+	saved := &app.Duplicate{Note: models.FlashbackWithMetadata{Flashback: models.Flashback{ID: "saved"}}, Exact: true, Similarity: 1}
+
+	tests := []struct {
+		name        string
+		note        Note
+		wantErr     error
+		wantContent string
+		wantAction  DuplicateAction
+	}{
+		{
+			name:        "keep skips the check and canonicalizes URLs",
+			note:        Note{Content: "https://Example.com/a/?utm_source=feed#top", Type: "url", OnDuplicate: DuplicateKeep},
+			wantContent: "https://example.com/a",
+			wantAction:  DuplicateKeep,
+		},
+		{
+			name:        "keep saves a duplicate alongside",
+			note:        Note{Content: "buy milk", Type: "text", Duplicate: saved, OnDuplicate: DuplicateKeep},
+			wantContent: "buy milk",
+			wantAction:  DuplicateKeep,
+		},
+		{
+			name:        "ask stops on a duplicate",
+			note:        Note{Content: "buy milk", Type: "text", Duplicate: saved, OnDuplicate: DuplicateAsk},
+			wantErr:     ErrDuplicate,
+			wantContent: "buy milk",
+			wantAction:  DuplicateAsk,
+		},
+		{
+			name:        "no action means ask",
+			note:        Note{Content: "buy milk", Type: "text", Duplicate: saved},
+			wantErr:     ErrDuplicate,
+			wantContent: "buy milk",
+			wantAction:  DuplicateAsk,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			note := tt.note
+			err := Deduplicate(&app.App{}).Run(context.Background(), &note, func(string) {})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if note.Content != tt.wantContent {
+				t.Errorf("content = %q, want %q", note.Content, tt.wantContent)
+			}
+			if note.OnDuplicate != tt.wantAction {
+				t.Errorf("action = %q, want %q", note.OnDuplicate, tt.wantAction)
+			}
+			if note.ID != "" {
+				t.Errorf("ID = %q, want the note left unsaved", note.ID)
+			}
+		})
+	}
+}
+
+func TestPipelineRunWrapsErrors(t *testing.T) {
+	errFetch := errors.New("connection refused")
+	var ran []Stage
+	step := func(stage Stage, err error) Step {
+		return Step{Stage: stage, Run: func(ctx context.Context, note *Note, emit func(string)) error {
+			ran = append(ran, stage)
+			emit(string(stage))
+			return err
+		}}
+	}
+
+	tests := []struct {
+		name      string
+		pipeline  *Pipeline
+		content   string
+		wantStage Stage
+		wantErr   error
+		wantRan   []Stage
+	}{
+		{
+			name:      "failing step",
+			pipeline:  &Pipeline{Steps: []Step{step(StageDetect, nil), step(StageLoad, errFetch), step(StageEnrich, nil)}},
+			content:   "https://example.com",
+			wantStage: StageLoad,
+			wantErr:   errFetch,
+			wantRan:   []Stage{StageDetect, StageLoad},
+		},
+		{
+			name:      "empty note",
+			pipeline:  &Pipeline{Steps: []Step{Detect(), step(StageStore, nil)}},
+			content:   "   ",
+			wantStage: StageDetect,
+		},
+		{
+			name:     "success",
+			pipeline: &Pipeline{Steps: []Step{Detect(), step(StageStore, nil)}},
+			content:  "buy milk",
+			wantRan:  []Stage{StageStore},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ran = nil
+			events := make(chan Event)
+			done := make(chan error, 1)
+			go func() {
+				done <- tt.pipeline.Run(context.Background(), &Note{Content: tt.content}, events)
+			}()
+			var stages []Stage
+			for event := range events {
+				stages = append(stages, event.Stage)
+			}
+			err := <-done
+
+			if !slices.Equal(ran, tt.wantRan) {
+				t.Errorf("ran %v, want %v", ran, tt.wantRan)
+			}
+			if !slices.Equal(stages, tt.wantRan) {
+				t.Errorf("events from %v, want %v", stages, tt.wantRan)
+			}
+			if tt.wantStage == "" {
+				if err != nil {
+					t.Fatalf("err = %v, want nil", err)
+				}
+				return
+			}
+			var stageErr *Error
+			if !errors.As(err, &stageErr) {
+				t.Fatalf("err = %v, want an *Error", err)
+			}
+			if stageErr.Stage != tt.wantStage {
+				t.Errorf("stage = %s, want %s", stageErr.Stage, tt.wantStage)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want it to wrap %v", err, tt.wantErr)
+			}
+		})
+	}
+}
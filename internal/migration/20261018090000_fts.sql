@@ -0,0 +1,67 @@
+-- +goose Up
+CREATE VIRTUAL TABLE IF NOT EXISTS flashbacks_fts USING fts5(
+    flashback_id UNINDEXED,
+    content,
+    metadata
+);
+
+INSERT INTO flashbacks_fts (flashback_id, content, metadata)
+SELECT f.id, f.content, COALESCE((
+    SELECT group_concat(m.value, ' ') FROM metadata m
+    WHERE m.flashback_id = f.id AND m.key NOT IN ('image', 'image_main')
+), '')
+FROM flashbacks f;
+
+-- +goose StatementBegin
+CREATE TRIGGER IF NOT EXISTS flashbacks_fts_insert AFTER INSERT ON flashbacks BEGIN
+    INSERT INTO flashbacks_fts (flashback_id, content, metadata) VALUES (new.id, new.content, '');
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER IF NOT EXISTS flashbacks_fts_update AFTER UPDATE OF content ON flashbacks BEGIN
+    UPDATE flashbacks_fts SET content = new.content WHERE flashback_id = new.id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER IF NOT EXISTS flashbacks_fts_delete AFTER DELETE ON flashbacks BEGIN
+    DELETE FROM flashbacks_fts WHERE flashback_id = old.id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER IF NOT EXISTS metadata_fts_insert AFTER INSERT ON metadata BEGIN
+    UPDATE flashbacks_fts SET metadata = COALESCE((
+        SELECT group_concat(m.value, ' ') FROM metadata m
+        WHERE m.flashback_id = new.flashback_id AND m.key NOT IN ('image', 'image_main')
+    ), '') WHERE flashback_id = new.flashback_id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER IF NOT EXISTS metadata_fts_update AFTER UPDATE ON metadata BEGIN
+    UPDATE flashbacks_fts SET metadata = COALESCE((
+        SELECT group_concat(m.value, ' ') FROM metadata m
+        WHERE m.flashback_id = new.flashback_id AND m.key NOT IN ('image', 'image_main')
+    ), '') WHERE flashback_id = new.flashback_id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER IF NOT EXISTS metadata_fts_delete AFTER DELETE ON metadata BEGIN
+    UPDATE flashbacks_fts SET metadata = COALESCE((
+        SELECT group_concat(m.value, ' ') FROM metadata m
+        WHERE m.flashback_id = old.flashback_id AND m.key NOT IN ('image', 'image_main')
+    ), '') WHERE flashback_id = old.flashback_id;
+END;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TRIGGER IF EXISTS metadata_fts_delete;
+DROP TRIGGER IF EXISTS metadata_fts_update;
+DROP TRIGGER IF EXISTS metadata_fts_insert;
+DROP TRIGGER IF EXISTS flashbacks_fts_delete;
+DROP TRIGGER IF EXISTS flashbacks_fts_update;
+DROP TRIGGER IF EXISTS flashbacks_fts_insert;
+DROP TABLE IF EXISTS flashbacks_fts;
@@ -0,0 +1,156 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS tags (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE COLLATE NOCASE
+);
+CREATE TABLE IF NOT EXISTS flashback_tags (
+    flashback_id TEXT NOT NULL,
+    tag_id INTEGER NOT NULL,
+    source TEXT NOT NULL DEFAULT 'ai',   -- "user" or "ai"
+    PRIMARY KEY (flashback_id, tag_id),
+    FOREIGN KEY (flashback_id) REFERENCES flashbacks(id) ON DELETE CASCADE,
+    FOREIGN KEY (tag_id) REFERENCES tags(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_flashback_tags_tag_id ON flashback_tags(tag_id);
+
+-- Older notes sometimes stored tags with single quotes, e.g. ['a', 'b'].
+INSERT OR IGNORE INTO tags (name)
+SELECT DISTINCT trim(j.value)
+FROM metadata m, json_each(replace(m.value, '''', '"')) j
+WHERE m.key = 'tags' AND json_valid(replace(m.value, '''', '"')) AND trim(j.value) != '';
+
+INSERT OR IGNORE INTO flashback_tags (flashback_id, tag_id, source)
+SELECT m.flashback_id, t.id, CASE WHEN m.source = 'user' THEN 'user' ELSE 'ai' END
+FROM metadata m, json_each(replace(m.value, '''', '"')) j
+JOIN tags t ON t.name = trim(j.value)
+WHERE m.key = 'tags' AND json_valid(replace(m.value, '''', '"'));
+
+DELETE FROM metadata WHERE key = 'tags';
+
+-- Tags now live outside of metadata, so the full-text index has to pick
+-- them up from flashback_tags as well.
+DROP TRIGGER IF EXISTS metadata_fts_insert;
+DROP TRIGGER IF EXISTS metadata_fts_update;
+DROP TRIGGER IF EXISTS metadata_fts_delete;
+
+-- +goose StatementBegin
+CREATE TRIGGER IF NOT EXISTS metadata_fts_insert AFTER INSERT ON metadata BEGIN
+    UPDATE flashbacks_fts SET metadata = COALESCE((
+        SELECT group_concat(m.value, ' ') FROM metadata m
+        WHERE m.flashback_id = new.flashback_id AND m.key NOT IN ('image', 'image_main')
+    ), '') || ' ' || COALESCE((
+        SELECT group_concat(t.name, ' ') FROM flashback_tags ft JOIN tags t ON t.id = ft.tag_id
+        WHERE ft.flashback_id = new.flashback_id
+    ), '') WHERE flashback_id = new.flashback_id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER IF NOT EXISTS metadata_fts_update AFTER UPDATE ON metadata BEGIN
+    UPDATE flashbacks_fts SET metadata = COALESCE((
+        SELECT group_concat(m.value, ' ') FROM metadata m
+        WHERE m.flashback_id = new.flashback_id AND m.key NOT IN ('image', 'image_main')
+    ), '') || ' ' || COALESCE((
+        SELECT group_concat(t.name, ' ') FROM flashback_tags ft JOIN tags t ON t.id = ft.tag_id
+        WHERE ft.flashback_id = new.flashback_id
+    ), '') WHERE flashback_id = new.flashback_id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER IF NOT EXISTS metadata_fts_delete AFTER DELETE ON metadata BEGIN
+    UPDATE flashbacks_fts SET metadata = COALESCE((
+        SELECT group_concat(m.value, ' ') FROM metadata m
+        WHERE m.flashback_id = old.flashback_id AND m.key NOT IN ('image', 'image_main')
+    ), '') || ' ' || COALESCE((
+        SELECT group_concat(t.name, ' ') FROM flashback_tags ft JOIN tags t ON t.id = ft.tag_id
+        WHERE ft.flashback_id = old.flashback_id
+    ), '') WHERE flashback_id = old.flashback_id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER IF NOT EXISTS flashback_tags_fts_insert AFTER INSERT ON flashback_tags BEGIN
+    UPDATE flashbacks_fts SET metadata = COALESCE((
+        SELECT group_concat(m.value, ' ') FROM metadata m
+        WHERE m.flashback_id = new.flashback_id AND m.key NOT IN ('image', 'image_main')
+    ), '') || ' ' || COALESCE((
+        SELECT group_concat(t.name, ' ') FROM flashback_tags ft JOIN tags t ON t.id = ft.tag_id
+        WHERE ft.flashback_id = new.flashback_id
+    ), '') WHERE flashback_id = new.flashback_id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER IF NOT EXISTS flashback_tags_fts_delete AFTER DELETE ON flashback_tags BEGIN
+    UPDATE flashbacks_fts SET metadata = COALESCE((
+        SELECT group_concat(m.value, ' ') FROM metadata m
+        WHERE m.flashback_id = old.flashback_id AND m.key NOT IN ('image', 'image_main')
+    ), '') || ' ' || COALESCE((
+        SELECT group_concat(t.name, ' ') FROM flashback_tags ft JOIN tags t ON t.id = ft.tag_id
+        WHERE ft.flashback_id = old.flashback_id
+    ), '') WHERE flashback_id = old.flashback_id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER IF NOT EXISTS tags_fts_update AFTER UPDATE OF name ON tags BEGIN
+    UPDATE flashbacks_fts SET metadata = COALESCE((
+        SELECT group_concat(m.value, ' ') FROM metadata m
+        WHERE m.flashback_id = flashbacks_fts.flashback_id AND m.key NOT IN ('image', 'image_main')
+    ), '') || ' ' || COALESCE((
+        SELECT group_concat(t.name, ' ') FROM flashback_tags ft JOIN tags t ON t.id = ft.tag_id
+        WHERE ft.flashback_id = flashbacks_fts.flashback_id
+    ), '') WHERE flashback_id IN (SELECT flashback_id FROM flashback_tags WHERE tag_id = new.id);
+END;
+-- +goose StatementEnd
+
+UPDATE flashbacks_fts SET metadata = COALESCE((
+    SELECT group_concat(m.value, ' ') FROM metadata m
+    WHERE m.flashback_id = flashbacks_fts.flashback_id AND m.key NOT IN ('image', 'image_main')
+), '') || ' ' || COALESCE((
+    SELECT group_concat(t.name, ' ') FROM flashback_tags ft JOIN tags t ON t.id = ft.tag_id
+    WHERE ft.flashback_id = flashbacks_fts.flashback_id
+), '');
+
+-- +goose Down
+DROP TRIGGER IF EXISTS tags_fts_update;
+DROP TRIGGER IF EXISTS flashback_tags_fts_delete;
+DROP TRIGGER IF EXISTS flashback_tags_fts_insert;
+INSERT INTO metadata (flashback_id, key, value, source)
+SELECT ft.flashback_id, 'tags', json_group_array(t.name), MAX(CASE WHEN ft.source = 'user' THEN 'user' ELSE 'system' END)
+FROM flashback_tags ft JOIN tags t ON t.id = ft.tag_id
+GROUP BY ft.flashback_id;
+DROP TABLE IF EXISTS flashback_tags;
+DROP TABLE IF EXISTS tags;
+
+DROP TRIGGER IF EXISTS metadata_fts_insert;
+DROP TRIGGER IF EXISTS metadata_fts_update;
+DROP TRIGGER IF EXISTS metadata_fts_delete;
+
+-- +goose StatementBegin
+CREATE TRIGGER IF NOT EXISTS metadata_fts_insert AFTER INSERT ON metadata BEGIN
+    UPDATE flashbacks_fts SET metadata = COALESCE((
+        SELECT group_concat(m.value, ' ') FROM metadata m
+        WHERE m.flashback_id = new.flashback_id AND m.key NOT IN ('image', 'image_main')
+    ), '') WHERE flashback_id = new.flashback_id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER IF NOT EXISTS metadata_fts_update AFTER UPDATE ON metadata BEGIN
+    UPDATE flashbacks_fts SET metadata = COALESCE((
+        SELECT group_concat(m.value, ' ') FROM metadata m
+        WHERE m.flashback_id = new.flashback_id AND m.key NOT IN ('image', 'image_main')
+    ), '') WHERE flashback_id = new.flashback_id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER IF NOT EXISTS metadata_fts_delete AFTER DELETE ON metadata BEGIN
+    UPDATE flashbacks_fts SET metadata = COALESCE((
+        SELECT group_concat(m.value, ' ') FROM metadata m
+        WHERE m.flashback_id = old.flashback_id AND m.key NOT IN ('image', 'image_main')
+    ), '') WHERE flashback_id = old.flashback_id;
+END;
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- Progress of imports, so an interrupted import can continue where it
+-- stopped. source is the absolute path that was imported and fingerprint
+-- identifies its contents at the time.
+CREATE TABLE IF NOT EXISTS imports (
+    source TEXT PRIMARY KEY,
+    fingerprint TEXT NOT NULL,
+    position INTEGER NOT NULL DEFAULT 0,
+    total INTEGER NOT NULL DEFAULT 0,
+    imported INTEGER NOT NULL DEFAULT 0,
+    skipped INTEGER NOT NULL DEFAULT 0,
+    failed INTEGER NOT NULL DEFAULT 0,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_flashbacks_content ON flashbacks(content);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_flashbacks_content;
+DROP TABLE IF EXISTS imports;
@@ -0,0 +1,6 @@
+-- +goose Up
+-- The TUI used to store URL notes as "link" while the CLI used "url".
+UPDATE flashbacks SET type = 'url' WHERE type = 'link';
+
+-- +goose Down
+SELECT 1;
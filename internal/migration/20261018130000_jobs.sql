@@ -0,0 +1,20 @@
+-- +goose Up
+-- Background work on notes, such as generating metadata and embeddings after
+-- a note was saved. Jobs are deleted once they succeed.
+CREATE TABLE IF NOT EXISTS jobs (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    flashback_id TEXT NOT NULL,
+    kind TEXT NOT NULL,                         -- "enrich" or "embed"
+    status TEXT NOT NULL DEFAULT 'pending',     -- "pending", "running" or "failed"
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    run_after DATETIME DEFAULT CURRENT_TIMESTAMP,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (flashback_id) REFERENCES flashbacks(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_jobs_status ON jobs(status, run_after);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_jobs_status;
+DROP TABLE IF EXISTS jobs;
@@ -0,0 +1,17 @@
+-- +goose Up
+-- Which model and prompt version produced generated metadata and embeddings,
+-- so flashback reindex can tell stale values apart. NULL means unknown,
+-- which is the case for metadata stored before this migration.
+ALTER TABLE metadata ADD COLUMN model TEXT;
+ALTER TABLE metadata ADD COLUMN prompt_version INTEGER;
+ALTER TABLE embeddings ADD COLUMN model TEXT;
+ALTER TABLE embeddings ADD COLUMN version INTEGER;
+-- Embeddings stored so far all came from Gemini's embedding model, with the
+-- layout of document version 1, so search keeps comparing them.
+UPDATE embeddings SET model = 'gemini/gemini-embedding-2', version = 1 WHERE model IS NULL;
+
+-- +goose Down
+ALTER TABLE embeddings DROP COLUMN version;
+ALTER TABLE embeddings DROP COLUMN model;
+ALTER TABLE metadata DROP COLUMN prompt_version;
+ALTER TABLE metadata DROP COLUMN model;
@@ -0,0 +1,35 @@
+-- +goose Up
+-- Embeddings used to be fixed at 768 dimensions. The vector column is no
+-- longer sized so other models can be used; dimension records the length of
+-- each vector, and search only compares vectors with the same model and
+-- dimension.
+CREATE TABLE embeddings_new (
+    flashback_id TEXT PRIMARY KEY,
+    vector F32_BLOB NOT NULL,
+    model TEXT,
+    version INTEGER,
+    dimension INTEGER NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (flashback_id) REFERENCES flashbacks(id) ON DELETE CASCADE
+);
+INSERT OR IGNORE INTO embeddings_new (flashback_id, vector, model, version, dimension, created_at)
+SELECT e.flashback_id, e.vector, e.model, e.version, 768, f.created_at
+FROM embeddings e
+JOIN flashbacks f ON f.id = e.flashback_id;
+DROP TABLE embeddings;
+ALTER TABLE embeddings_new RENAME TO embeddings;
+CREATE INDEX IF NOT EXISTS idx_embeddings_model ON embeddings(model, dimension);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_embeddings_model;
+CREATE TABLE embeddings_old (
+    flashback_id TEXT,
+    vector F32_BLOB(768) NOT NULL,
+    model TEXT,
+    version INTEGER,
+    FOREIGN KEY (flashback_id) REFERENCES flashbacks(id) ON DELETE CASCADE
+);
+INSERT INTO embeddings_old (flashback_id, vector, model, version)
+SELECT flashback_id, vector, model, version FROM embeddings WHERE dimension = 768;
+DROP TABLE embeddings;
+ALTER TABLE embeddings_old RENAME TO embeddings;
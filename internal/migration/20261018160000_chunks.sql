@@ -0,0 +1,28 @@
+-- +goose Up
+-- The text of the page behind a URL note, and overlapping passages of it
+-- with their own embeddings, so search can match the body of long pages
+-- rather than only their summary.
+CREATE TABLE IF NOT EXISTS pages (
+    flashback_id TEXT PRIMARY KEY,
+    text TEXT NOT NULL,
+    fetched_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (flashback_id) REFERENCES flashbacks(id) ON DELETE CASCADE
+);
+CREATE TABLE IF NOT EXISTS chunks (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    flashback_id TEXT NOT NULL,
+    position INTEGER NOT NULL,
+    text TEXT NOT NULL,
+    vector F32_BLOB NOT NULL,
+    model TEXT,
+    dimension INTEGER NOT NULL,
+    FOREIGN KEY (flashback_id) REFERENCES flashbacks(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_chunks_flashback ON chunks(flashback_id, position);
+CREATE INDEX IF NOT EXISTS idx_chunks_model ON chunks(model, dimension);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_chunks_model;
+DROP INDEX IF EXISTS idx_chunks_flashback;
+DROP TABLE IF EXISTS chunks;
+DROP TABLE IF EXISTS pages;
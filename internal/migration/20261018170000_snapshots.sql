@@ -0,0 +1,32 @@
+-- +goose Up
+-- Saved copies of the page behind URL notes, for reading offline and after
+-- the page disappears. Refreshing a page adds a snapshot when it changed, so
+-- earlier versions are kept. markdown and html are gzip-compressed unless
+-- encoding is 'none', as for the page texts copied from pages below.
+CREATE TABLE IF NOT EXISTS snapshots (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    flashback_id TEXT NOT NULL,
+    markdown BLOB NOT NULL,
+    html BLOB,
+    encoding TEXT NOT NULL DEFAULT 'gzip',
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (flashback_id) REFERENCES flashbacks(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_snapshots_flashback ON snapshots(flashback_id, id);
+
+INSERT INTO snapshots (flashback_id, markdown, encoding, created_at)
+SELECT flashback_id, CAST(text AS BLOB), 'none', fetched_at FROM pages;
+DROP TABLE pages;
+
+-- +goose Down
+CREATE TABLE IF NOT EXISTS pages (
+    flashback_id TEXT PRIMARY KEY,
+    text TEXT NOT NULL,
+    fetched_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (flashback_id) REFERENCES flashbacks(id) ON DELETE CASCADE
+);
+INSERT INTO pages (flashback_id, text, fetched_at)
+SELECT flashback_id, CAST(markdown AS TEXT), created_at FROM snapshots s
+WHERE encoding = 'none' AND id = (SELECT MAX(id) FROM snapshots WHERE flashback_id = s.flashback_id);
+DROP INDEX IF EXISTS idx_snapshots_flashback;
+DROP TABLE IF EXISTS snapshots;
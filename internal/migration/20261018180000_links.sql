@@ -0,0 +1,16 @@
+-- +goose Up
+-- Links between notes made by the user. A link shows on both notes, so each
+-- pair is stored once, with the lower ID first.
+CREATE TABLE IF NOT EXISTS links (
+    source_id TEXT NOT NULL,
+    target_id TEXT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (source_id, target_id),
+    CHECK (source_id < target_id),
+    FOREIGN KEY (source_id) REFERENCES flashbacks(id) ON DELETE CASCADE,
+    FOREIGN KEY (target_id) REFERENCES flashbacks(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_links_target_id ON links(target_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS links;
@@ -0,0 +1,32 @@
+-- +goose Up
+-- Spaced-repetition schedule of each note reviewed at least once (SM-2).
+CREATE TABLE IF NOT EXISTS reviews (
+    flashback_id TEXT PRIMARY KEY,
+    ease REAL NOT NULL DEFAULT 2.5,
+    interval_days INTEGER NOT NULL DEFAULT 0,
+    repetitions INTEGER NOT NULL DEFAULT 0,
+    lapses INTEGER NOT NULL DEFAULT 0,
+    due_at DATETIME NOT NULL,
+    reviewed_at DATETIME NOT NULL,
+    first_reviewed_at DATETIME NOT NULL,
+    FOREIGN KEY (flashback_id) REFERENCES flashbacks(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_reviews_due_at ON reviews(due_at);
+
+-- Notes and tags the user opted in to or out of review. A note's own
+-- choice wins over its tags'.
+CREATE TABLE IF NOT EXISTS review_notes (
+    flashback_id TEXT PRIMARY KEY,
+    included INTEGER NOT NULL,
+    FOREIGN KEY (flashback_id) REFERENCES flashbacks(id) ON DELETE CASCADE
+);
+CREATE TABLE IF NOT EXISTS review_tags (
+    tag_id INTEGER PRIMARY KEY,
+    included INTEGER NOT NULL,
+    FOREIGN KEY (tag_id) REFERENCES tags(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS review_tags;
+DROP TABLE IF EXISTS review_notes;
+DROP TABLE IF EXISTS reviews;
@@ -0,0 +1,10 @@
+-- +goose Up
+-- Removed notes stay in the trash, with their embeddings and metadata, until
+-- it is emptied or they have been there longer than the retention period.
+ALTER TABLE flashbacks ADD COLUMN deleted_at DATETIME;
+CREATE INDEX IF NOT EXISTS idx_flashbacks_deleted_at ON flashbacks(deleted_at);
+
+-- +goose Down
+DELETE FROM flashbacks WHERE deleted_at IS NOT NULL;
+DROP INDEX IF EXISTS idx_flashbacks_deleted_at;
+ALTER TABLE flashbacks DROP COLUMN deleted_at;
@@ -0,0 +1,30 @@
+-- +goose Up
+-- Every version of a note's content and of the metadata and tags the user
+-- owns. Revisions are numbered from 1 for each note; generated values aren't
+-- kept since they can be regenerated.
+CREATE TABLE IF NOT EXISTS revisions (
+    flashback_id TEXT NOT NULL,
+    revision INTEGER NOT NULL,
+    content TEXT NOT NULL,
+    metadata TEXT NOT NULL DEFAULT '{}',   -- JSON object of user metadata
+    tags TEXT NOT NULL DEFAULT '[]',       -- JSON array of user tags
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (flashback_id, revision),
+    FOREIGN KEY (flashback_id) REFERENCES flashbacks(id) ON DELETE CASCADE
+);
+
+INSERT INTO revisions (flashback_id, revision, content, metadata, tags, created_at)
+SELECT f.id, 1, f.content,
+    COALESCE((
+        SELECT json_group_object(m.key, m.value) FROM metadata m
+        WHERE m.flashback_id = f.id AND m.source = 'user'
+    ), '{}'),
+    COALESCE((
+        SELECT json_group_array(t.name) FROM flashback_tags ft JOIN tags t ON t.id = ft.tag_id
+        WHERE ft.flashback_id = f.id AND ft.source = 'user'
+    ), '[]'),
+    f.created_at
+FROM flashbacks f;
+
+-- +goose Down
+DROP TABLE IF EXISTS revisions;
@@ -0,0 +1,21 @@
+-- +goose Up
+-- Named collections for organising notes by project. A note can belong to
+-- several collections.
+CREATE TABLE IF NOT EXISTS collections (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE COLLATE NOCASE,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+CREATE TABLE IF NOT EXISTS collection_notes (
+    collection_id INTEGER NOT NULL,
+    flashback_id TEXT NOT NULL,
+    PRIMARY KEY (collection_id, flashback_id),
+    FOREIGN KEY (collection_id) REFERENCES collections(id) ON DELETE CASCADE,
+    FOREIGN KEY (flashback_id) REFERENCES flashbacks(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_collection_notes_flashback_id ON collection_notes(flashback_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_collection_notes_flashback_id;
+DROP TABLE IF EXISTS collection_notes;
+DROP TABLE IF EXISTS collections;
@@ -0,0 +1,10 @@
+-- +goose Up
+-- url_key identifies the page a URL note points to (utils.URLKey), so that
+-- adding a URL finds the notes saved for the same page through an index.
+-- Notes saved before this migration get theirs on the next duplicate check.
+ALTER TABLE flashbacks ADD COLUMN url_key TEXT;
+CREATE INDEX IF NOT EXISTS idx_flashbacks_url_key ON flashbacks(url_key);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_flashbacks_url_key;
+ALTER TABLE flashbacks DROP COLUMN url_key;
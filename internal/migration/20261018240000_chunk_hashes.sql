@@ -0,0 +1,8 @@
+-- +goose Up
+-- body_hash is the SHA-256 of the page text a passage was split from, so
+-- enrichment can skip embedding the passages of a page that didn't change.
+-- Passages stored before have none and are embedded again once.
+ALTER TABLE chunks ADD COLUMN body_hash TEXT;
+
+-- +goose Down
+ALTER TABLE chunks DROP COLUMN body_hash;
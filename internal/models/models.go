@@ -1,13 +1,80 @@
 package models
 
 type Flashback struct {
-	ID        string `json:"id"`
-	Content   string `json:"content"`
-	Type      string `json:"type"`
-	CreatedAt string `json:"created_at"`
+	ID        string `json:"id" yaml:"id"`
+	Content   string `json:"content" yaml:"content"`
+	Type      string `json:"type" yaml:"type"`
+	CreatedAt string `json:"created_at" yaml:"created_at"`
 }
 
 type FlashbackWithMetadata struct {
+	Flashback `yaml:",inline"`
+	Metadata  map[string]string `json:"metadata" yaml:"metadata"`
+	Tags      []string          `json:"tags" yaml:"tags"`
+}
+
+// ScoredFlashback is a search result. Score is the fused ranking score and
+// Similarity the cosine similarity to the query, when semantic search ran.
+// Snippet is the passage of the note's page that best matched the query.
+type ScoredFlashback struct {
+	FlashbackWithMetadata `yaml:",inline"`
+	Score                 *float64 `json:"score,omitempty" yaml:"score,omitempty"`
+	Similarity            *float64 `json:"similarity,omitempty" yaml:"similarity,omitempty"`
+	Snippet               string   `json:"snippet,omitempty" yaml:"snippet,omitempty"`
+}
+
+// ExportVersion is the version of the ExportDump format. Bump it when the
+// layout changes incompatibly.
+const ExportVersion = 1
+
+// ExportDump is the JSON export of a knowledge base.
+type ExportDump struct {
+	Version    int            `json:"version"`
+	ExportedAt string         `json:"exported_at"`
+	Notes      []ExportedNote `json:"notes"`
+}
+
+// ExportedNote is a note with the source of each metadata value and tag, and
+// optionally its embedding, so it can be restored as it was.
+type ExportedNote struct {
 	Flashback
-	Metadata map[string]string `json:"metadata"`
+	Metadata  []ExportedMetadata `json:"metadata"`
+	Tags      []ExportedTag      `json:"tags"`
+	Embedding []float32          `json:"embedding,omitempty"`
+	// EmbeddingModel and EmbeddingVersion tell whether Embedding can be
+	// compared with vectors from the configured model.
+	EmbeddingModel   string `json:"embedding_model,omitempty"`
+	EmbeddingVersion int    `json:"embedding_version,omitempty"`
+}
+
+type ExportedMetadata struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Source string `json:"source"`
+	// Model and PromptVersion are set for generated values.
+	Model         string `json:"model,omitempty"`
+	PromptVersion int    `json:"prompt_version,omitempty"`
+}
+
+type ExportedTag struct {
+	Name   string `json:"name"`
+	Source string `json:"source"`
+}
+
+// MetadataMap returns the note's metadata values by key.
+func (n ExportedNote) MetadataMap() map[string]string {
+	metadata := make(map[string]string, len(n.Metadata))
+	for _, m := range n.Metadata {
+		metadata[m.Key] = m.Value
+	}
+	return metadata
+}
+
+// TagNames returns the names of the note's tags.
+func (n ExportedNote) TagNames() []string {
+	names := make([]string, len(n.Tags))
+	for i, t := range n.Tags {
+		names[i] = t.Name
+	}
+	return names
 }
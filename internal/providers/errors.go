@@ -0,0 +1,188 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+// ErrorClass tells apart provider failures that call for different
+// handling: transient ones are retried, the others need the user.
+type ErrorClass int
+
+const (
+	ErrorUnknown ErrorClass = iota
+	// ErrorTransient covers network failures and overloaded servers.
+	ErrorTransient
+	// ErrorQuota means a rate limit or quota was exceeded.
+	ErrorQuota
+	// ErrorAuth means the API key is missing, invalid or lacks permission.
+	ErrorAuth
+	// ErrorInvalidInput means the request itself was rejected, e.g. an
+	// unknown model or content that is too long.
+	ErrorInvalidInput
+)
+
+func (c ErrorClass) String() string {
+	switch c {
+	case ErrorTransient:
+		return "transient"
+	case ErrorQuota:
+		return "quota"
+	case ErrorAuth:
+		return "auth"
+	case ErrorInvalidInput:
+		return "invalid input"
+	default:
+		return "unknown"
+	}
+}
+
+// Error is a classified provider error.
+type Error struct {
+	Class      ErrorClass
+	StatusCode int
+	// RetryAfter is the delay the server asked for, if any.
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *Error) Error() string {
+	var message string
+	switch e.Class {
+	case ErrorTransient:
+		message = "the AI provider is unreachable or temporarily unavailable"
+	case ErrorQuota:
+		message = "the AI provider's rate limit or quota was exceeded"
+		if e.RetryAfter > 0 {
+			message += fmt.Sprintf(", try again in %s", e.RetryAfter.Round(time.Second))
+		}
+	case ErrorAuth:
+		message = "the AI provider rejected the API key, check api_key in the config"
+	case ErrorInvalidInput:
+		message = "the AI provider rejected the request"
+	default:
+		message = "the AI provider failed"
+	}
+	return fmt.Sprintf("%s (%v)", message, e.Err)
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// Retryable reports whether trying again later may succeed.
+func (e *Error) Retryable() bool {
+	return e.Class == ErrorTransient || e.Class == ErrorQuota
+}
+
+// IsRetryable reports whether err may go away when retried later: a provider
+// error for a rate limit or an unavailable server, or a network timeout.
+// Anything else, such as a missing note, is not retried.
+func IsRetryable(err error) bool {
+	var providerErr *Error
+	if errors.As(err, &providerErr) {
+		return providerErr.Retryable()
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// HTTPError is returned by the OpenAI-compatible provider for non-2xx
+// responses.
+type HTTPError struct {
+	StatusCode int
+	Status     string
+	Body       string
+	RetryAfter time.Duration
+}
+
+func (e *HTTPError) Error() string {
+	if e.Body == "" {
+		return e.Status
+	}
+	return fmt.Sprintf("%s: %s", e.Status, e.Body)
+}
+
+// Classify wraps err in an *Error. Context cancellation is returned as-is,
+// and errors that are already classified are returned unchanged.
+func Classify(err error) error {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	var providerErr *Error
+	if errors.As(err, &providerErr) {
+		return err
+	}
+
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return &Error{Class: classifyStatus(httpErr.StatusCode), StatusCode: httpErr.StatusCode, RetryAfter: httpErr.RetryAfter, Err: err}
+	}
+	var apiErr genai.APIError
+	if errors.As(err, &apiErr) {
+		return &Error{
+			Class:      classifyStatus(apiErr.Code),
+			StatusCode: apiErr.Code,
+			RetryAfter: geminiRetryDelay(apiErr),
+			Err:        errors.New(apiErr.Message),
+		}
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return &Error{Class: ErrorTransient, Err: err}
+	}
+	return &Error{Class: ErrorUnknown, Err: err}
+}
+
+func classifyStatus(code int) ErrorClass {
+	switch {
+	case code == http.StatusTooManyRequests:
+		return ErrorQuota
+	case code == http.StatusUnauthorized || code == http.StatusForbidden:
+		return ErrorAuth
+	case code == http.StatusRequestTimeout || code >= 500:
+		return ErrorTransient
+	case code >= 400:
+		return ErrorInvalidInput
+	default:
+		return ErrorUnknown
+	}
+}
+
+// geminiRetryDelay reads the google.rpc.RetryInfo detail of a Gemini error,
+// e.g. {"@type": ".../google.rpc.RetryInfo", "retryDelay": "26s"}.
+func geminiRetryDelay(err genai.APIError) time.Duration {
+	for _, detail := range err.Details {
+		kind, _ := detail["@type"].(string)
+		if !strings.HasSuffix(kind, "google.rpc.RetryInfo") {
+			continue
+		}
+		if delay, ok := detail["retryDelay"].(string); ok {
+			if d, err := time.ParseDuration(delay); err == nil {
+				return d
+			}
+		}
+	}
+	return 0
+}
+
+// parseRetryAfter reads a Retry-After header given in seconds or as an HTTP
+// date.
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return max(time.Until(t), 0)
+	}
+	return 0
+}
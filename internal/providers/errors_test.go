@@ -0,0 +1,97 @@
+package providers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestOpenAIErrorStatus(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		retryAfter string
+		class      ErrorClass
+		wait       time.Duration
+	}{
+		{"rate limited", http.StatusTooManyRequests, "7", ErrorQuota, 7 * time.Second},
+		{"unauthorized", http.StatusUnauthorized, "", ErrorAuth, 0},
+		{"unavailable", http.StatusServiceUnavailable, "", ErrorTransient, 0},
+		{"bad request", http.StatusBadRequest, "", ErrorInvalidInput, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newTestOpenAI(t, func(w http.ResponseWriter, r *http.Request) {
+				if tt.retryAfter != "" {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+				http.Error(w, "nope", tt.status)
+			})
+
+			_, err := o.Embed(context.Background(), "hello", "")
+			var httpErr *HTTPError
+			if !errors.As(err, &httpErr) {
+				t.Fatalf("err = %v, want an *HTTPError", err)
+			}
+			if httpErr.StatusCode != tt.status || httpErr.Body != "nope" {
+				t.Errorf("err = %+v", httpErr)
+			}
+
+			var providerErr *Error
+			if !errors.As(Classify(err), &providerErr) {
+				t.Fatalf("Classify(%v) isn't an *Error", err)
+			}
+			if providerErr.Class != tt.class || providerErr.RetryAfter != tt.wait {
+				t.Errorf("class = %s, retry after = %s; want %s, %s", providerErr.Class, providerErr.RetryAfter, tt.class, tt.wait)
+			}
+		})
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", 0},
+		{"30", 30 * time.Second},
+		{"soon", 0},
+		{time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat), 0},
+	}
+	for _, tt := range tests {
+		if got := parseRetryAfter(tt.value); got != tt.want {
+			t.Errorf("parseRetryAfter(%q) = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+
+	future := time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)
+	if got := parseRetryAfter(future); got <= 0 || got > time.Minute {
+		t.Errorf("parseRetryAfter(%q) = %s, want at most a minute", future, got)
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"rate limited", &Error{Class: ErrorQuota}, true},
+		{"unavailable", &Error{Class: ErrorTransient}, true},
+		{"bad key", &Error{Class: ErrorAuth}, false},
+		{"rejected", &Error{Class: ErrorInvalidInput}, false},
+		{"wrapped", fmt.Errorf("embedding: %w", &Error{Class: ErrorQuota}), true},
+		{"timeout", context.DeadlineExceeded, true},
+		{"missing note", sql.ErrNoRows, false},
+		{"other", errors.New("boom"), false},
+	}
+	for _, tt := range tests {
+		if got := IsRetryable(tt.err); got != tt.want {
+			t.Errorf("%s: IsRetryable(%v) = %t, want %t", tt.name, tt.err, got, tt.want)
+		}
+	}
+}
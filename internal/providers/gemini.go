@@ -0,0 +1,114 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/yagnikpt/flashback/internal/config"
+	"google.golang.org/genai"
+)
+
+const (
+	defaultGeminiGenerationModel = "gemini-flash-latest"
+	defaultGeminiEmbeddingModel  = "gemini-embedding-2"
+)
+
+type Gemini struct {
+	client          *genai.Client
+	generationModel string
+	embeddingModel  string
+	dimensions      int
+}
+
+func NewGemini(cfg config.Config) (*Gemini, error) {
+	client, err := genai.NewClient(context.Background(), &genai.ClientConfig{
+		APIKey: cfg.APIKey,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	g := &Gemini{
+		client:          client,
+		generationModel: cfg.GenerationModel,
+		embeddingModel:  cfg.EmbeddingModel,
+		dimensions:      cfg.EmbeddingDimensions,
+	}
+	if g.generationModel == "" {
+		g.generationModel = defaultGeminiGenerationModel
+	}
+	if g.embeddingModel == "" {
+		g.embeddingModel = defaultGeminiEmbeddingModel
+	}
+	if g.dimensions == 0 {
+		g.dimensions = DefaultGeminiDimensions
+	}
+	return g, nil
+}
+
+func (g *Gemini) GenerationModel() string  { return config.ProviderGemini + "/" + g.generationModel }
+func (g *Gemini) EmbeddingModel() string   { return config.ProviderGemini + "/" + g.embeddingModel }
+func (g *Gemini) EmbeddingDimensions() int { return g.dimensions }
+
+func (g *Gemini) GenerateJSON(ctx context.Context, req GenerateRequest) (string, error) {
+	config := &genai.GenerateContentConfig{
+		SystemInstruction:  genai.NewContentFromText(req.SystemPrompt, genai.RoleUser),
+		ResponseMIMEType:   "application/json",
+		ResponseJsonSchema: req.Schema,
+	}
+
+	var contents []*genai.Content
+	if req.Image != nil {
+		parts := []*genai.Part{
+			{InlineData: &genai.Blob{Data: req.Image, MIMEType: req.ImageMIMEType}},
+		}
+		contents = []*genai.Content{{Parts: parts}}
+	} else {
+		contents = genai.Text(req.Content)
+	}
+
+	result, err := g.client.Models.GenerateContent(ctx, g.generationModel, contents, config)
+	if err != nil {
+		return "", err
+	}
+	return result.Text(), nil
+}
+
+func (g *Gemini) GenerateText(ctx context.Context, req GenerateRequest, emit func(string)) (string, error) {
+	config := &genai.GenerateContentConfig{
+		SystemInstruction: genai.NewContentFromText(req.SystemPrompt, genai.RoleUser),
+	}
+
+	var text strings.Builder
+	for result, err := range g.client.Models.GenerateContentStream(ctx, g.generationModel, genai.Text(req.Content), config) {
+		if err != nil {
+			return text.String(), err
+		}
+		part := result.Text()
+		text.WriteString(part)
+		emit(part)
+	}
+	return text.String(), nil
+}
+
+func (g *Gemini) Embed(ctx context.Context, content, taskType string) ([]float32, error) {
+	contents := []*genai.Content{
+		genai.NewContentFromText(content, genai.RoleUser),
+	}
+	result, err := g.client.Models.EmbedContent(ctx,
+		g.embeddingModel,
+		contents,
+		&genai.EmbedContentConfig{
+			TaskType:             taskType,
+			OutputDimensionality: genai.Ptr(int32(g.dimensions)),
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Embeddings) == 0 {
+		return nil, fmt.Errorf("empty response received from embedding")
+	}
+	return result.Embeddings[0].Values, nil
+}
@@ -0,0 +1,254 @@
+package providers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/yagnikpt/flashback/internal/config"
+)
+
+const (
+	defaultOpenAIBaseURL         = "http://localhost:11434/v1"
+	defaultOpenAIGenerationModel = "llama3.2"
+	defaultOpenAIEmbeddingModel  = "nomic-embed-text"
+
+	// openAITimeout bounds a whole request, including reading a streamed
+	// answer, so a server that stops responding doesn't hang the caller.
+	openAITimeout = 2 * time.Minute
+)
+
+// OpenAI talks to any server implementing the OpenAI chat completions and
+// embeddings endpoints, such as Ollama, llama.cpp server or LM Studio.
+type OpenAI struct {
+	client          *http.Client
+	baseURL         string
+	apiKey          string
+	generationModel string
+	embeddingModel  string
+	// dimensions is only sent when configured, as not every server or
+	// model accepts it.
+	dimensions int
+}
+
+func NewOpenAI(cfg config.Config) *OpenAI {
+	o := &OpenAI{
+		client:          &http.Client{Timeout: openAITimeout},
+		baseURL:         strings.TrimRight(cfg.BaseURL, "/"),
+		apiKey:          cfg.APIKey,
+		generationModel: cfg.GenerationModel,
+		embeddingModel:  cfg.EmbeddingModel,
+		dimensions:      cfg.EmbeddingDimensions,
+	}
+	if o.baseURL == "" {
+		o.baseURL = defaultOpenAIBaseURL
+	}
+	if o.generationModel == "" {
+		o.generationModel = defaultOpenAIGenerationModel
+	}
+	if o.embeddingModel == "" {
+		o.embeddingModel = defaultOpenAIEmbeddingModel
+	}
+	return o
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content any    `json:"content"`
+}
+
+type chatContentPart struct {
+	Type     string         `json:"type"`
+	Text     string         `json:"text,omitempty"`
+	ImageURL map[string]any `json:"image_url,omitempty"`
+}
+
+type chatRequest struct {
+	Model          string         `json:"model"`
+	Messages       []chatMessage  `json:"messages"`
+	ResponseFormat map[string]any `json:"response_format,omitempty"`
+	Stream         bool           `json:"stream,omitempty"`
+}
+
+type chatResponse struct {
+	Choices []struct {
+		Message struct {
+			Content string `json:"content"`
+		} `json:"message"`
+	} `json:"choices"`
+}
+
+// chatStreamChunk is one server-sent event of a streamed chat completion.
+type chatStreamChunk struct {
+	Choices []struct {
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+	} `json:"choices"`
+}
+
+type embeddingRequest struct {
+	Model      string `json:"model"`
+	Input      string `json:"input"`
+	Dimensions int    `json:"dimensions,omitempty"`
+}
+
+type embeddingResponse struct {
+	Data []struct {
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+}
+
+func (o *OpenAI) GenerationModel() string  { return config.ProviderOpenAI + "/" + o.generationModel }
+func (o *OpenAI) EmbeddingModel() string   { return config.ProviderOpenAI + "/" + o.embeddingModel }
+func (o *OpenAI) EmbeddingDimensions() int { return o.dimensions }
+
+func (o *OpenAI) GenerateJSON(ctx context.Context, req GenerateRequest) (string, error) {
+	var userContent any = req.Content
+	if req.Image != nil {
+		dataURL := fmt.Sprintf("data:%s;base64,%s", req.ImageMIMEType, base64.StdEncoding.EncodeToString(req.Image))
+		userContent = []chatContentPart{
+			{Type: "image_url", ImageURL: map[string]any{"url": dataURL}},
+		}
+	}
+
+	body := chatRequest{
+		Model: o.generationModel,
+		Messages: []chatMessage{
+			{Role: "system", Content: req.SystemPrompt},
+			{Role: "user", Content: userContent},
+		},
+	}
+	if req.Schema != nil {
+		body.ResponseFormat = map[string]any{
+			"type": "json_schema",
+			"json_schema": map[string]any{
+				"name":   "metadata",
+				"schema": req.Schema,
+			},
+		}
+	}
+
+	var res chatResponse
+	if err := o.post(ctx, "/chat/completions", body, &res); err != nil {
+		return "", err
+	}
+	if len(res.Choices) == 0 {
+		return "", fmt.Errorf("empty response received from %s", o.baseURL)
+	}
+	return res.Choices[0].Message.Content, nil
+}
+
+func (o *OpenAI) GenerateText(ctx context.Context, req GenerateRequest, emit func(string)) (string, error) {
+	body := chatRequest{
+		Model: o.generationModel,
+		Messages: []chatMessage{
+			{Role: "system", Content: req.SystemPrompt},
+			{Role: "user", Content: req.Content},
+		},
+		Stream: true,
+	}
+
+	resp, err := o.send(ctx, "/chat/completions", body)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var text strings.Builder
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data:")
+		if !ok {
+			continue
+		}
+		data = strings.TrimSpace(data)
+		if data == "[DONE]" {
+			break
+		}
+		var chunk chatStreamChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return text.String(), err
+		}
+		if len(chunk.Choices) == 0 || chunk.Choices[0].Delta.Content == "" {
+			continue
+		}
+		part := chunk.Choices[0].Delta.Content
+		text.WriteString(part)
+		emit(part)
+	}
+	return text.String(), scanner.Err()
+}
+
+func (o *OpenAI) Embed(ctx context.Context, content, taskType string) ([]float32, error) {
+	body := embeddingRequest{
+		Model:      o.embeddingModel,
+		Input:      content,
+		Dimensions: o.dimensions,
+	}
+
+	var res embeddingResponse
+	if err := o.post(ctx, "/embeddings", body, &res); err != nil {
+		return nil, err
+	}
+	if len(res.Data) == 0 {
+		return nil, fmt.Errorf("empty response received from embedding")
+	}
+	return res.Data[0].Embedding, nil
+}
+
+func (o *OpenAI) post(ctx context.Context, path string, body, out any) error {
+	resp, err := o.send(ctx, path, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+// send posts body as JSON to path and returns the response, or an
+// *HTTPError when its status isn't 200. The caller closes the body.
+func (o *OpenAI) send(ctx context.Context, path string, body any) (*http.Response, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", o.baseURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if o.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+o.apiKey)
+	}
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(resp.Body)
+		return nil, &HTTPError{
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+			Body:       strings.TrimSpace(string(data)),
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+		}
+	}
+	return resp, nil
+}
@@ -0,0 +1,78 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/yagnikpt/flashback/internal/config"
+)
+
+// newTestOpenAI returns a provider talking to a server running handler.
+func newTestOpenAI(t *testing.T, handler http.HandlerFunc) *OpenAI {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return NewOpenAI(config.Config{BaseURL: server.URL, APIKey: "secret", EmbeddingDimensions: 3})
+}
+
+func TestOpenAIEmbed(t *testing.T) {
+	o := newTestOpenAI(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/embeddings" {
+			t.Errorf("path = %s, want /embeddings", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+			t.Errorf("Authorization = %q, want %q", got, "Bearer secret")
+		}
+		var req embeddingRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatal(err)
+		}
+		if req.Model != defaultOpenAIEmbeddingModel || req.Input != "hello" || req.Dimensions != 3 {
+			t.Errorf("request = %+v", req)
+		}
+		w.Write([]byte(`{"data": [{"embedding": [0.1, 0.2, 0.3]}]}`))
+	})
+
+	embedding, err := o.Embed(context.Background(), "hello", "RETRIEVAL_DOCUMENT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []float32{0.1, 0.2, 0.3}; !slices.Equal(embedding, want) {
+		t.Errorf("embedding = %v, want %v", embedding, want)
+	}
+}
+
+func TestOpenAIGenerateJSON(t *testing.T) {
+	o := newTestOpenAI(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/chat/completions" {
+			t.Errorf("path = %s, want /chat/completions", r.URL.Path)
+		}
+		var req chatRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatal(err)
+		}
+		if len(req.Messages) != 2 || req.Messages[0].Content != "system" || req.Messages[1].Content != "content" {
+			t.Errorf("messages = %+v", req.Messages)
+		}
+		if req.ResponseFormat["type"] != "json_schema" {
+			t.Errorf("response_format = %v, want a json_schema", req.ResponseFormat)
+		}
+		w.Write([]byte(`{"choices": [{"message": {"content": "{\"tldr\": \"hi\"}"}}]}`))
+	})
+
+	text, err := o.GenerateJSON(context.Background(), GenerateRequest{
+		SystemPrompt: "system",
+		Content:      "content",
+		Schema:       map[string]any{"type": "object"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text != `{"tldr": "hi"}` {
+		t.Errorf("text = %q", text)
+	}
+}
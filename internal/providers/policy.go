@@ -0,0 +1,175 @@
+package providers
+
+import (
+	"context"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/yagnikpt/flashback/internal/config"
+)
+
+const (
+	defaultMaxRetries = 3
+	defaultBaseDelay  = 500 * time.Millisecond
+	defaultMaxDelay   = 30 * time.Second
+)
+
+// Policy controls how provider calls are retried and rate limited.
+type Policy struct {
+	// MaxRetries is how often a failed call is retried; 0 disables retries.
+	MaxRetries int
+	// BaseDelay is the delay before the first retry. It doubles with every
+	// attempt, up to MaxDelay.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// RequestsPerMinute spaces out calls to stay within a budget; 0 means
+	// no limit.
+	RequestsPerMinute int
+}
+
+// PolicyFromConfig returns the default policy adjusted by the config.
+func PolicyFromConfig(cfg config.Config) Policy {
+	policy := Policy{
+		MaxRetries:        defaultMaxRetries,
+		BaseDelay:         defaultBaseDelay,
+		MaxDelay:          defaultMaxDelay,
+		RequestsPerMinute: cfg.RequestsPerMinute,
+	}
+	if cfg.MaxRetries != nil {
+		policy.MaxRetries = max(*cfg.MaxRetries, 0)
+	}
+	return policy
+}
+
+// WithPolicy wraps p so that every call is rate limited, transient and quota
+// errors are retried with exponential backoff and jitter, and errors are
+// classified as *Error.
+func WithPolicy(p Provider, policy Policy) Provider {
+	guarded := &guarded{next: p, policy: policy}
+	if policy.RequestsPerMinute > 0 {
+		guarded.interval = time.Minute / time.Duration(policy.RequestsPerMinute)
+	}
+	return guarded
+}
+
+type guarded struct {
+	next   Provider
+	policy Policy
+
+	mu       sync.Mutex
+	interval time.Duration
+	nextCall time.Time
+}
+
+func (g *guarded) GenerationModel() string  { return g.next.GenerationModel() }
+func (g *guarded) EmbeddingModel() string   { return g.next.EmbeddingModel() }
+func (g *guarded) EmbeddingDimensions() int { return g.next.EmbeddingDimensions() }
+
+func (g *guarded) GenerateJSON(ctx context.Context, req GenerateRequest) (string, error) {
+	return withRetries(ctx, g, func() (string, error) {
+		return g.next.GenerateJSON(ctx, req)
+	})
+}
+
+func (g *guarded) GenerateText(ctx context.Context, req GenerateRequest, emit func(string)) (string, error) {
+	return withRetries(ctx, g, func() (string, error) {
+		streamed := false
+		text, err := g.next.GenerateText(ctx, req, func(part string) {
+			streamed = true
+			emit(part)
+		})
+		if err != nil && streamed {
+			// Retrying would repeat what the caller has already seen.
+			return text, finalError{err}
+		}
+		return text, err
+	})
+}
+
+func (g *guarded) Embed(ctx context.Context, content, taskType string) ([]float32, error) {
+	return withRetries(ctx, g, func() ([]float32, error) {
+		return g.next.Embed(ctx, content, taskType)
+	})
+}
+
+func withRetries[T any](ctx context.Context, g *guarded, call func() (T, error)) (T, error) {
+	var zero T
+	for attempt := 0; ; attempt++ {
+		if err := g.wait(ctx); err != nil {
+			return zero, err
+		}
+		result, err := call()
+		if err == nil {
+			return result, nil
+		}
+		if final, ok := err.(finalError); ok {
+			return result, Classify(final.err)
+		}
+
+		err = Classify(err)
+		providerErr, ok := err.(*Error)
+		if !ok || !providerErr.Retryable() || attempt >= g.policy.MaxRetries {
+			return zero, err
+		}
+		delay := g.backoff(attempt, providerErr.RetryAfter)
+		if delay < 0 {
+			// The server asked us to come back much later, e.g. when a
+			// daily quota is used up.
+			return zero, err
+		}
+		if err := sleep(ctx, delay); err != nil {
+			return zero, err
+		}
+	}
+}
+
+// finalError marks an error that must not be retried.
+type finalError struct{ err error }
+
+func (e finalError) Error() string { return e.err.Error() }
+
+// backoff returns the delay before retry attempt+1: the server's hint when
+// given, otherwise an exponential delay with full jitter. It is negative
+// when the hint exceeds MaxDelay.
+func (g *guarded) backoff(attempt int, retryAfter time.Duration) time.Duration {
+	if retryAfter > 0 {
+		if retryAfter > g.policy.MaxDelay {
+			return -1
+		}
+		return retryAfter
+	}
+	delay := min(g.policy.BaseDelay<<attempt, g.policy.MaxDelay)
+	return delay/2 + rand.N(delay/2+1)
+}
+
+// wait blocks until the request budget allows another call.
+func (g *guarded) wait(ctx context.Context) error {
+	if g.interval == 0 {
+		return nil
+	}
+	g.mu.Lock()
+	now := time.Now()
+	at := g.nextCall
+	if at.Before(now) {
+		at = now
+	}
+	g.nextCall = at.Add(g.interval)
+	g.mu.Unlock()
+
+	return sleep(ctx, at.Sub(now))
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,76 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/yagnikpt/flashback/internal/config"
+)
+
+const (
+	TaskRetrievalDocument = "RETRIEVAL_DOCUMENT"
+	TaskRetrievalQuery    = "RETRIEVAL_QUERY"
+)
+
+// DefaultGeminiDimensions is the size of Gemini embeddings unless
+// configured otherwise.
+const DefaultGeminiDimensions = 768
+
+type GenerateRequest struct {
+	SystemPrompt  string
+	Content       string
+	Image         []byte
+	ImageMIMEType string
+	Schema        map[string]any
+}
+
+// Enricher produces structured metadata for a note. The returned string is
+// the raw JSON object described by the request schema. GenerationModel
+// identifies the model, e.g. "gemini/gemini-flash-latest".
+type Enricher interface {
+	GenerateJSON(ctx context.Context, req GenerateRequest) (string, error)
+	GenerationModel() string
+}
+
+// Generator writes free text for a request, passing each piece to emit as it
+// is streamed, and returns the whole text. Schema and Image are ignored.
+type Generator interface {
+	GenerateText(ctx context.Context, req GenerateRequest, emit func(string)) (string, error)
+	GenerationModel() string
+}
+
+// Embedder turns text into a vector. taskType is one of the Task* constants;
+// providers that don't distinguish between documents and queries ignore it.
+// Vectors are only comparable when EmbeddingModel and their length are the
+// same. EmbeddingDimensions is the length Embed returns, or 0 when it is up
+// to the model.
+type Embedder interface {
+	Embed(ctx context.Context, content, taskType string) ([]float32, error)
+	EmbeddingModel() string
+	EmbeddingDimensions() int
+}
+
+type Provider interface {
+	Enricher
+	Generator
+	Embedder
+}
+
+// New returns the configured provider, wrapped with the retry and rate
+// limiting policy from the config.
+func New(cfg config.Config) (Provider, error) {
+	var provider Provider
+	switch cfg.Provider {
+	case "", config.ProviderGemini:
+		gemini, err := NewGemini(cfg)
+		if err != nil {
+			return nil, err
+		}
+		provider = gemini
+	case config.ProviderOpenAI:
+		provider = NewOpenAI(cfg)
+	default:
+		return nil, fmt.Errorf("unknown provider %q", cfg.Provider)
+	}
+	return WithPolicy(provider, PolicyFromConfig(cfg)), nil
+}
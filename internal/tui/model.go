@@ -1,14 +1,19 @@
 package tui
 
 import (
+	"context"
 	"strings"
+	"time"
 
 	tea "charm.land/bubbletea/v2"
 	"charm.land/lipgloss/v2"
 	"github.com/yagnikpt/flashback/internal/app"
+	"github.com/yagnikpt/flashback/internal/components/asknotes"
 	"github.com/yagnikpt/flashback/internal/components/insertnote"
 	"github.com/yagnikpt/flashback/internal/components/notelist"
+	"github.com/yagnikpt/flashback/internal/components/reviewnotes"
 	"github.com/yagnikpt/flashback/internal/components/searchnotes"
+	"github.com/yagnikpt/flashback/internal/worker"
 )
 
 type Model struct {
@@ -17,14 +22,30 @@ type Model struct {
 	notelist    notelist.Model
 	insertnote  insertnote.Model
 	searchnotes searchnotes.Model
+	asknotes    asknotes.Model
+	reviewnotes reviewnotes.Model
+	// jobStatus describes what the background worker is doing.
+	jobStatus string
+	// collections are the names the switcher cycles through after all
+	// notes, and collection the one chosen; empty means all notes.
+	collections []string
+	collection  string
 }
 
+// jobEventMsg relays progress of the background worker.
+type jobEventMsg worker.Event
+
+type collectionsMsg []string
+
 type Screen int
 
 const (
 	screenListNotes Screen = iota
 	screenInsertNote
 	screenSearchNotes
+	screenAskNotes
+	screenReviewNotes
+	screenCount
 )
 
 func NewModel(app *app.App) Model {
@@ -34,11 +55,63 @@ func NewModel(app *app.App) Model {
 		notelist:    notelist.NewModel(app),
 		insertnote:  insertnote.NewModel(app),
 		searchnotes: searchnotes.NewModel(app),
+		asknotes:    asknotes.NewModel(app),
+		reviewnotes: reviewnotes.NewModel(app),
 	}
 }
 
 func (m Model) Init() tea.Cmd {
-	return m.notelist.Init()
+	return tea.Batch(m.notelist.Init(), loadCollectionsCmd(m.app))
+}
+
+func loadCollectionsCmd(app *app.App) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		collections, err := app.ListCollections(ctx)
+		if err != nil {
+			return collectionsMsg(nil)
+		}
+		names := make([]string, len(collections))
+		for i, c := range collections {
+			names[i] = c.Name
+		}
+		return collectionsMsg(names)
+	}
+}
+
+// switchCollection moves to the next collection, after the last one back
+// to all notes, and reloads the screen shown.
+func (m *Model) switchCollection() tea.Cmd {
+	next := ""
+	if len(m.collections) > 0 {
+		index := -1
+		for i, name := range m.collections {
+			if strings.EqualFold(name, m.collection) {
+				index = i
+			}
+		}
+		if m.collection == "" || index >= 0 {
+			if index+1 < len(m.collections) {
+				next = m.collections[index+1]
+			}
+		}
+	}
+	m.collection = next
+	m.notelist.SetCollection(next)
+	m.insertnote.SetCollection(next)
+	m.searchnotes.SetCollection(next)
+
+	cmds := []tea.Cmd{loadCollectionsCmd(m.app)}
+	switch m.active {
+	case screenListNotes:
+		m.notelist.ResetView()
+		cmds = append(cmds, m.notelist.Init())
+	case screenSearchNotes:
+		m.searchnotes.ResetView()
+	}
+	return tea.Batch(cmds...)
 }
 
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -48,12 +121,36 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	)
 
 	switch msg := msg.(type) {
+	case jobEventMsg:
+		switch {
+		case !msg.Done:
+			m.jobStatus = msg.Message
+		case msg.Err != nil:
+			m.jobStatus = "Generating metadata failed, see flashback jobs"
+		default:
+			m.jobStatus = ""
+			if m.active == screenListNotes {
+				cmds = append(cmds, m.notelist.Init())
+			}
+		}
+		return m, tea.Batch(cmds...)
+	case collectionsMsg:
+		if msg != nil {
+			m.collections = msg
+		}
+		return m, nil
 	case tea.KeyPressMsg:
 		switch msg.String() {
 		case "ctrl+c":
 			return m, tea.Quit
+		case "ctrl+o":
+			return m, m.switchCollection()
 		case "tab":
-			m.active = (m.active + 1) % 3
+			if m.active == screenAskNotes {
+				// Stop any answer being generated.
+				m.asknotes.ResetView()
+			}
+			m.active = (m.active + 1) % screenCount
 			switch m.active {
 			case screenListNotes:
 				cmd = m.notelist.Init()
@@ -64,10 +161,20 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			case screenSearchNotes:
 				cmd = m.searchnotes.Init()
 				m.searchnotes.ResetView()
+			case screenAskNotes:
+				cmd = m.asknotes.Init()
+				m.asknotes.ResetView()
+			case screenReviewNotes:
+				cmd = m.reviewnotes.Init()
+				m.reviewnotes.ResetView()
 			}
 			return m, cmd
 		case "shift+tab":
-			m.active = ((m.active-1)%3 + 3) % 3
+			if m.active == screenAskNotes {
+				// Stop any answer being generated.
+				m.asknotes.ResetView()
+			}
+			m.active = ((m.active-1)%screenCount + screenCount) % screenCount
 			switch m.active {
 			case screenListNotes:
 				cmd = m.notelist.Init()
@@ -78,6 +185,12 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			case screenSearchNotes:
 				cmd = m.searchnotes.Init()
 				m.searchnotes.ResetView()
+			case screenAskNotes:
+				cmd = m.asknotes.Init()
+				m.asknotes.ResetView()
+			case screenReviewNotes:
+				cmd = m.reviewnotes.Init()
+				m.reviewnotes.ResetView()
 			}
 			return m, cmd
 		}
@@ -96,18 +209,29 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		newSearchnotes, cmd := m.searchnotes.Update(msg)
 		m.searchnotes = newSearchnotes.(searchnotes.Model)
 		cmds = append(cmds, cmd)
+	case screenAskNotes:
+		newAsknotes, cmd := m.asknotes.Update(msg)
+		m.asknotes = newAsknotes.(asknotes.Model)
+		cmds = append(cmds, cmd)
+	case screenReviewNotes:
+		newReviewnotes, cmd := m.reviewnotes.Update(msg)
+		m.reviewnotes = newReviewnotes.(reviewnotes.Model)
+		cmds = append(cmds, cmd)
 	}
 
 	return m, tea.Batch(cmds...)
 }
 
 var (
-	tabStyles       = lipgloss.NewStyle().Padding(0, 1).Margin(0, 1).Render
-	activeTabStyles = lipgloss.NewStyle().Padding(0, 1).Margin(0, 1).Background(lipgloss.Blue).Foreground(lipgloss.White).Bold(true).Render
+	jobStatusStyles  = lipgloss.NewStyle().Faint(true).Margin(0, 1).Render
+	tabStyles        = lipgloss.NewStyle().Padding(0, 1).Margin(0, 1).Render
+	activeTabStyles  = lipgloss.NewStyle().Padding(0, 1).Margin(0, 1).Background(lipgloss.Blue).Foreground(lipgloss.White).Bold(true).Render
+	collectionStyles = lipgloss.NewStyle().Padding(0, 1).Margin(0, 1).Foreground(lipgloss.Blue).Bold(true).Render
+	switcherStyles   = lipgloss.NewStyle().Faint(true).Render
 )
 
 func (m Model) View() tea.View {
-	views := []string{"Manage Notes", "Add Note", "Search Notes"}
+	views := []string{"Manage Notes", "Add Note", "Search Notes", "Ask", "Review"}
 	var builder strings.Builder
 	for i, v := range views {
 		if Screen(i) == m.active {
@@ -116,6 +240,14 @@ func (m Model) View() tea.View {
 			builder.WriteString(tabStyles(v))
 		}
 	}
+	collection := "All notes"
+	if m.collection != "" {
+		collection = m.collection
+	}
+	builder.WriteString(collectionStyles(collection) + switcherStyles("ctrl+o"))
+	if m.jobStatus != "" {
+		builder.WriteString(jobStatusStyles(m.jobStatus))
+	}
 	builder.WriteString("\n\n")
 	switch m.active {
 	case screenListNotes:
@@ -124,6 +256,10 @@ func (m Model) View() tea.View {
 		builder.WriteString(m.insertnote.View().Content)
 	case screenSearchNotes:
 		builder.WriteString(m.searchnotes.View().Content)
+	case screenAskNotes:
+		builder.WriteString(m.asknotes.View().Content)
+	case screenReviewNotes:
+		builder.WriteString(m.reviewnotes.View().Content)
 	}
 
 	v := tea.NewView(builder.String())
@@ -1,15 +1,32 @@
 package tui
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log"
 	"os"
 
 	tea "charm.land/bubbletea/v2"
 	"github.com/yagnikpt/flashback/internal/app"
+	"github.com/yagnikpt/flashback/internal/worker"
 )
 
 func Run(app *app.App) {
 	p := tea.NewProgram(NewModel(app))
+
+	// Process queued jobs while the TUI is open.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		err := worker.Run(ctx, app, worker.Options{}, func(event worker.Event) {
+			p.Send(jobEventMsg(event))
+		})
+		if err != nil && !errors.Is(err, context.Canceled) {
+			log.Println("Error running background jobs:", err)
+		}
+	}()
+
 	_, err := p.Run()
 	if err != nil {
 		fmt.Printf("Alas, there's been an error: %v", err)
@@ -1,6 +1,10 @@
 package utils
 
-import "strings"
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+)
 
 func UniqueStrings(input []string) []string {
 	seen := make(map[string]bool)
@@ -15,3 +19,44 @@ func UniqueStrings(input []string) []string {
 	}
 	return out
 }
+
+// ParseTags decodes a JSON array of tags as returned by metadata generation.
+// Some models answer with single quotes, e.g. ['a', 'b'], which is accepted too.
+func ParseTags(value string) ([]string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	var tags []string
+	err := json.Unmarshal([]byte(strings.ReplaceAll(value, `'`, `"`)), &tags)
+	if err != nil {
+		return nil, err
+	}
+	return CleanTags(tags), nil
+}
+
+// SplitTags parses a comma separated tag list such as "k8s, infra".
+func SplitTags(value string) []string {
+	return CleanTags(strings.Split(value, ","))
+}
+
+// CleanTags trims tags and drops empty and duplicate (case-insensitive) ones.
+func CleanTags(tags []string) []string {
+	cleaned := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag != "" {
+			cleaned = append(cleaned, tag)
+		}
+	}
+	return UniqueStrings(cleaned)
+}
+
+func SortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
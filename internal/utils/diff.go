@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"strings"
+
+	"charm.land/lipgloss/v2"
+	"github.com/aymanbagabas/go-udiff"
+)
+
+var (
+	diffHeaderStyles  = lipgloss.NewStyle().Bold(true)
+	diffHunkStyles    = lipgloss.NewStyle().Foreground(lipgloss.Cyan)
+	diffAddedStyles   = lipgloss.NewStyle().Foreground(lipgloss.Green)
+	diffRemovedStyles = lipgloss.NewStyle().Foreground(lipgloss.Red)
+)
+
+// FormatDiff is a coloured unified diff from old to new, or an empty string
+// when they are the same.
+func FormatDiff(oldLabel, newLabel, old, new string) string {
+	diff := udiff.Unified(oldLabel, newLabel, old, new)
+	if diff == "" {
+		return ""
+	}
+
+	lines := strings.Split(strings.TrimSuffix(diff, "\n"), "\n")
+	for i, line := range lines {
+		switch {
+		case i < 2:
+			lines[i] = diffHeaderStyles.Render(line)
+		case strings.HasPrefix(line, "@@"):
+			lines[i] = diffHunkStyles.Render(line)
+		case strings.HasPrefix(line, "+"):
+			lines[i] = diffAddedStyles.Render(line)
+		case strings.HasPrefix(line, "-"):
+			lines[i] = diffRemovedStyles.Render(line)
+		}
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
@@ -0,0 +1,208 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/yagnikpt/flashback/internal/models"
+	"go.yaml.in/yaml/v3"
+)
+
+type ExportFormat string
+
+const (
+	ExportJSON      ExportFormat = "json"
+	ExportMarkdown  ExportFormat = "markdown"
+	ExportBookmarks ExportFormat = "bookmarks"
+)
+
+var ExportFormats = []ExportFormat{ExportJSON, ExportMarkdown, ExportBookmarks}
+
+func ParseExportFormat(value string) (ExportFormat, error) {
+	switch value {
+	case "md":
+		value = string(ExportMarkdown)
+	case "html":
+		value = string(ExportBookmarks)
+	}
+	for _, f := range ExportFormats {
+		if string(f) == value {
+			return f, nil
+		}
+	}
+
+	names := make([]string, len(ExportFormats))
+	for i, f := range ExportFormats {
+		names[i] = string(f)
+	}
+	return "", fmt.Errorf("invalid export format %q (expected %s)", value, strings.Join(names, ", "))
+}
+
+// WriteExportJSON writes notes as a versioned JSON dump.
+func WriteExportJSON(w io.Writer, notes []models.ExportedNote) error {
+	dump := models.ExportDump{
+		Version:    models.ExportVersion,
+		ExportedAt: time.Now().UTC().Format(time.RFC3339),
+		Notes:      notes,
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(dump)
+}
+
+// WriteMarkdownVault writes one Markdown file per note into dir, with YAML
+// front matter as understood by Obsidian and Logseq. Existing files with the
+// same name are overwritten, so exporting again updates the vault.
+func WriteMarkdownVault(dir string, notes []models.ExportedNote) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	for _, note := range notes {
+		data, err := RenderMarkdownNote(note)
+		if err != nil {
+			return err
+		}
+		path := filepath.Join(dir, MarkdownFileName(note))
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var unsafeFileNameChars = regexp.MustCompile(`[^\p{L}\p{N} _.-]+`)
+
+// MarkdownFileName is a readable, unique file name for a note.
+func MarkdownFileName(note models.ExportedNote) string {
+	title := noteTitle(note)
+	title = unsafeFileNameChars.ReplaceAllString(title, " ")
+	title = strings.Join(strings.Fields(title), " ")
+	if runes := []rune(title); len(runes) > 60 {
+		title = strings.TrimSpace(string(runes[:60]))
+	}
+	if title == "" {
+		return note.ID + ".md"
+	}
+	return fmt.Sprintf("%s (%s).md", title, note.ID)
+}
+
+// RenderMarkdownNote renders a note as a Markdown file with YAML front matter.
+func RenderMarkdownNote(note models.ExportedNote) ([]byte, error) {
+	header := map[string]any{}
+	for key, value := range note.MetadataMap() {
+		if ignoreList[key] {
+			continue
+		}
+		header[key] = value
+	}
+	header["id"] = note.ID
+	header["type"] = note.Type
+	header["created"] = note.CreatedAt
+	header["tags"] = note.TagNames()
+	if isURLNote(note.Type) {
+		header["url"] = note.Content
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("---\n")
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(header); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	buf.WriteString("---\n\n")
+
+	metadata := note.MetadataMap()
+	switch {
+	case isURLNote(note.Type):
+		if title := metadata["title"]; title != "" {
+			fmt.Fprintf(&buf, "[%s](%s)\n", title, note.Content)
+		} else {
+			fmt.Fprintf(&buf, "<%s>\n", note.Content)
+		}
+		if tldr := metadata["tldr"]; tldr != "" {
+			fmt.Fprintf(&buf, "\n%s\n", tldr)
+		}
+	case note.Type == "command":
+		fmt.Fprintf(&buf, "```sh\n%s\n```\n", note.Content)
+	default:
+		buf.WriteString(note.Content + "\n")
+	}
+	return buf.Bytes(), nil
+}
+
+// WriteBookmarksHTML writes the URL notes in the Netscape bookmark format
+// that browsers and bookmark managers import. Other notes are skipped.
+func WriteBookmarksHTML(w io.Writer, notes []models.ExportedNote) error {
+	var b strings.Builder
+	b.WriteString(`<!DOCTYPE NETSCAPE-Bookmark-file-1>
+<!-- This is an automatically generated file.
+     It will be read and overwritten.
+     DO NOT EDIT! -->
+<META HTTP-EQUIV="Content-Type" CONTENT="text/html; charset=UTF-8">
+<TITLE>Bookmarks</TITLE>
+<H1>Bookmarks</H1>
+<DL><p>
+`)
+	for _, note := range notes {
+		if !isURLNote(note.Type) {
+			continue
+		}
+		fmt.Fprintf(&b, `    <DT><A HREF="%s"`, html.EscapeString(note.Content))
+		if t, err := ParseTimestamp(note.CreatedAt); err == nil {
+			fmt.Fprintf(&b, ` ADD_DATE="%d"`, t.Unix())
+		}
+		if tags := note.TagNames(); len(tags) > 0 {
+			fmt.Fprintf(&b, ` TAGS="%s"`, html.EscapeString(strings.Join(tags, ",")))
+		}
+		fmt.Fprintf(&b, ">%s</A>\n", html.EscapeString(noteTitle(note)))
+		if description := note.MetadataMap()["description"]; description != "" {
+			fmt.Fprintf(&b, "    <DD>%s\n", html.EscapeString(description))
+		}
+	}
+	b.WriteString("</DL><p>\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// noteTitle picks a short human-readable title for a note.
+func noteTitle(note models.ExportedNote) string {
+	metadata := note.MetadataMap()
+	if title := metadata["title"]; title != "" {
+		return title
+	}
+	if isURLNote(note.Type) {
+		if tldr := metadata["tldr"]; tldr != "" {
+			return tldr
+		}
+		return note.Content
+	}
+	line, _, _ := strings.Cut(strings.TrimSpace(note.Content), "\n")
+	return line
+}
+
+func isURLNote(noteType string) bool {
+	return noteType == "url" || noteType == "link"
+}
+
+// ParseTimestamp parses a created_at value as stored by SQLite.
+func ParseTimestamp(value string) (time.Time, error) {
+	for _, layout := range []string{time.DateTime, time.RFC3339Nano, "2006-01-02T15:04:05"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid timestamp %q", value)
+}
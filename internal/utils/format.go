@@ -1,13 +1,9 @@
 package utils
 
 import (
-	"encoding/json"
-	"log"
-	"os"
+	"fmt"
 	"strings"
 
-	"golang.org/x/term"
-
 	"charm.land/lipgloss/v2"
 	"github.com/yagnikpt/flashback/internal/models"
 )
@@ -30,10 +26,7 @@ func FormatSingleNoteForTUI(note models.FlashbackWithMetadata) string {
 }
 
 func formatSingleNote(note models.FlashbackWithMetadata, wrapURLs bool) string {
-	width, _, err := term.GetSize(int(os.Stdout.Fd()))
-	if err != nil {
-		panic(err)
-	}
+	width, _ := TerminalSize()
 
 	result := keyStyles.Render("\nID: ") + note.ID + "\n" + keyStyles.Render("Content: ")
 	if !wrapURLs && note.Type == "url" {
@@ -47,24 +40,15 @@ func formatSingleNote(note models.FlashbackWithMetadata, wrapURLs bool) string {
 	}
 
 	result += "\n\nMetadata:\n"
+	if len(note.Tags) > 0 {
+		tags := lipgloss.Wrap(stringJoin(note.Tags, ", "), max(width-len("tags")-8, 20), " ")
+		tags = strings.ReplaceAll(tags, "\n", "\n"+strings.Repeat(" ", 4+len("tags")))
+		result += "  " + keyStyles.Render("tags") + ": " + tags + "\n"
+	}
 	for key, value := range note.Metadata {
 		if ignoreList[key] {
 			continue
 		}
-		if key == "tags" {
-			var tags []string
-			if value == "[]" || value == "" {
-				continue
-			}
-			jsonValue := strings.ReplaceAll(value, `'`, `"`)
-			err = json.Unmarshal([]byte(jsonValue), &tags)
-			if err != nil {
-				log.Println(err, value)
-				result += "  " + keyStyles.Render(key) + ": " + value + "\n"
-				continue
-			}
-			value = stringJoin(tags, ", ")
-		}
 
 		if !wrapURLs && key == "image" {
 			result += "  " + keyStyles.Render(key) + ": " + value + "\n"
@@ -87,10 +71,7 @@ func FormatSingleNoteCompact(note models.FlashbackWithMetadata) string {
 }
 
 func FormatMultipleNotes(notes []models.FlashbackWithMetadata) string {
-	width, _, err := term.GetSize(int(os.Stdout.Fd()))
-	if err != nil {
-		panic(err)
-	}
+	width, _ := TerminalSize()
 
 	result := ""
 	for _, note := range notes {
@@ -101,10 +82,17 @@ func FormatMultipleNotes(notes []models.FlashbackWithMetadata) string {
 }
 
 func FormatMultipleNotesCompact(notes []models.FlashbackWithMetadata) string {
-	width, _, err := term.GetSize(int(os.Stdout.Fd()))
-	if err != nil {
-		panic(err)
+	scored := make([]models.ScoredFlashback, len(notes))
+	for i, note := range notes {
+		scored[i].FlashbackWithMetadata = note
 	}
+	return FormatSearchResultsCompact(scored, "")
+}
+
+// FormatSearchResultsCompact is FormatMultipleNotesCompact with the snippet
+// of each result below its content, with the words of query highlighted.
+func FormatSearchResultsCompact(notes []models.ScoredFlashback, query string) string {
+	width, _ := TerminalSize()
 
 	const idColWidth = 24
 	const colGap = "  "
@@ -124,7 +112,122 @@ func FormatMultipleNotesCompact(notes []models.FlashbackWithMetadata) string {
 
 		idPadding := idColWidth - len(note.ID)
 		idPadding = max(idPadding, 1)
-		result += keyStyles.Render(note.ID) + strings.Repeat(" ", idPadding) + colGap + content + "\n\n"
+		result += keyStyles.Render(note.ID) + strings.Repeat(" ", idPadding) + colGap + content + "\n"
+		if note.Snippet != "" {
+			wrapWidth := max(width-(idColWidth+len(colGap)), 20)
+			snippet := lipgloss.Wrap(note.Snippet, wrapWidth, " ")
+			lines := strings.Split(snippet, "\n")
+			for i, line := range lines {
+				lines[i] = HighlightTerms(line, query)
+			}
+			result += indent + strings.Join(lines, "\n"+indent) + "\n"
+		}
+		result += "\n"
+	}
+	return result
+}
+
+// FormatCitations lists the notes an answer cites, in the order of cited,
+// by ID and title.
+func FormatCitations(notes []models.ScoredFlashback, cited []string) string {
+	width, _ := TerminalSize()
+
+	byID := make(map[string]models.ScoredFlashback, len(notes))
+	for _, note := range notes {
+		byID[note.ID] = note
+	}
+	result := "Sources:\n"
+	for _, id := range cited {
+		if note, ok := byID[id]; ok {
+			result += formatNoteLine("["+id+"]", note.FlashbackWithMetadata, "", width)
+		}
+	}
+	return result
+}
+
+// FormatRelatedNotes lists the notes the user linked to a note and the notes
+// similar to it, under separate headings. Sections without notes are left
+// out, so the result is empty when there are none.
+func FormatRelatedNotes(links []models.FlashbackWithMetadata, similar []models.ScoredFlashback) string {
+	width, _ := TerminalSize()
+
+	result := ""
+	if len(links) > 0 {
+		result += keyStyles.Render("Linked notes:") + "\n" + FormatNoteList(links)
+	}
+	if len(similar) > 0 {
+		if result != "" {
+			result += "\n"
+		}
+		result += keyStyles.Render("Related:") + "\n"
+		for _, note := range similar {
+			suffix := ""
+			if note.Similarity != nil {
+				suffix = fmt.Sprintf(" (%.0f%%)", *note.Similarity*100)
+			}
+			result += formatNoteLine(note.ID, note.FlashbackWithMetadata, suffix, width)
+		}
+	}
+	return result
+}
+
+// NoteTitle is a one-line title for note: its title or summary, or its
+// content for URL notes without a title and notes without metadata.
+func NoteTitle(note models.FlashbackWithMetadata) string {
+	title := note.Metadata["title"]
+	if title == "" && note.Type != "url" {
+		title = note.Metadata["tldr"]
+	}
+	if title == "" {
+		title = strings.Join(strings.Fields(note.Content), " ")
+	}
+	return title
+}
+
+// FormatNoteList lists notes one per line, by ID and title.
+func FormatNoteList(notes []models.FlashbackWithMetadata) string {
+	width, _ := TerminalSize()
+
+	result := ""
+	for _, note := range notes {
+		result += formatNoteLine(note.ID, note, "", width)
+	}
+	return result
+}
+
+// formatNoteLine is an indented line with label and the title of note,
+// shortened to fit width along with suffix.
+func formatNoteLine(label string, note models.FlashbackWithMetadata, suffix string, width int) string {
+	title := NoteTitle(note)
+	maxWidth := max(width-len(label)-len(suffix)-4, 20)
+	if runes := []rune(title); len(runes) > maxWidth {
+		title = string(runes[:maxWidth-1]) + "…"
+	}
+	return "  " + keyStyles.Render(label) + " " + title + suffix + "\n"
+}
+
+// FormatReviewCard shows note for review: its title and content, followed
+// by its summary and tags once revealed.
+func FormatReviewCard(note models.FlashbackWithMetadata, revealed bool) string {
+	width, _ := TerminalSize()
+	width = max(width-4, 20)
+
+	result := ""
+	if title := note.Metadata["title"]; title != "" {
+		result += keyStyles.Render(lipgloss.Wrap(title, width, " ")) + "\n"
+	}
+	result += lipgloss.Wrap(strings.TrimSpace(note.Content), width, " ") + "\n"
+	if !revealed {
+		return result
+	}
+
+	summary := note.Metadata["tldr"]
+	if summary == "" {
+		summary = "No summary yet."
+	}
+	result += "\n" + keyStyles.Render("Summary: ") + lipgloss.Wrap(summary, width, " ") + "\n"
+	if len(note.Tags) > 0 {
+		result += keyStyles.Render("Tags: ") + stringJoin(note.Tags, ", ") + "\n"
 	}
 	return result
 }
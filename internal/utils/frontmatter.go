@@ -0,0 +1,135 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+const frontMatterDelimiter = "+++"
+
+const frontMatterHint = "# Tags and metadata you change or add here are kept when metadata is regenerated.\n"
+
+// RenderNoteFile lays out a note for editing: tags and metadata as TOML
+// front matter followed by the content.
+func RenderNoteFile(content string, metadata map[string]string, tags []string) (string, error) {
+	return renderNoteFile(content, metadata, tags, frontMatterHint)
+}
+
+// RenderRevision lays out a revision like RenderNoteFile, without the
+// editing hint, for comparing revisions.
+func RenderRevision(content string, metadata map[string]string, tags []string) (string, error) {
+	return renderNoteFile(content, metadata, tags, "")
+}
+
+func renderNoteFile(content string, metadata map[string]string, tags []string, hint string) (string, error) {
+	header := make(map[string]any, len(metadata)+1)
+	for key, value := range metadata {
+		header[key] = value
+	}
+	if tags == nil {
+		tags = []string{}
+	}
+	header["tags"] = tags
+
+	var buf bytes.Buffer
+	buf.WriteString(frontMatterDelimiter + "\n")
+	buf.WriteString(hint)
+	if err := toml.NewEncoder(&buf).Encode(header); err != nil {
+		return "", err
+	}
+	buf.WriteString(frontMatterDelimiter + "\n\n")
+	buf.WriteString(content)
+	buf.WriteString("\n")
+	return buf.String(), nil
+}
+
+// ParseNoteFile is the inverse of RenderNoteFile. metadata and tags are nil
+// when the file has no front matter.
+func ParseNoteFile(data string) (content string, metadata map[string]string, tags []string, err error) {
+	data = strings.ReplaceAll(data, "\r\n", "\n")
+	if !strings.HasPrefix(data, frontMatterDelimiter+"\n") {
+		return strings.TrimSpace(data), nil, nil, nil
+	}
+
+	rest := strings.TrimPrefix(data, frontMatterDelimiter+"\n")
+	end := strings.Index(rest, "\n"+frontMatterDelimiter+"\n")
+	var header string
+	if strings.HasPrefix(rest, frontMatterDelimiter+"\n") {
+		header, content = "", strings.TrimPrefix(rest, frontMatterDelimiter+"\n")
+	} else if end >= 0 {
+		header, content = rest[:end], rest[end+len(frontMatterDelimiter)+2:]
+	} else {
+		return "", nil, nil, fmt.Errorf("front matter is not closed with %s", frontMatterDelimiter)
+	}
+
+	raw := map[string]any{}
+	if _, err := toml.Decode(header, &raw); err != nil {
+		return "", nil, nil, fmt.Errorf("error parsing front matter: %w", err)
+	}
+	metadata = make(map[string]string, len(raw))
+	tags = []string{}
+	for key, value := range raw {
+		if key == "tags" {
+			tags, err = frontMatterTags(value)
+			if err != nil {
+				return "", nil, nil, err
+			}
+			continue
+		}
+		if s, ok := value.(string); ok {
+			metadata[key] = s
+		} else {
+			metadata[key] = fmt.Sprint(value)
+		}
+	}
+
+	return strings.TrimSpace(content), metadata, tags, nil
+}
+
+func frontMatterTags(value any) ([]string, error) {
+	switch v := value.(type) {
+	case string:
+		return SplitTags(v), nil
+	case []any:
+		tags := make([]string, 0, len(v))
+		for _, tag := range v {
+			tags = append(tags, fmt.Sprint(tag))
+		}
+		return CleanTags(tags), nil
+	default:
+		return nil, fmt.Errorf("tags must be a list of strings")
+	}
+}
+
+// WriteTempNoteFile renders a note into a new temporary file and returns its
+// path. The caller removes the file when done.
+func WriteTempNoteFile(content string, metadata map[string]string, tags []string) (string, error) {
+	data, err := RenderNoteFile(content, metadata, tags)
+	if err != nil {
+		return "", err
+	}
+
+	f, err := os.CreateTemp("", "flashback-*.md")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	if _, err := f.WriteString(data); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+func ReadNoteFile(path string) (content string, metadata map[string]string, tags []string, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	return ParseNoteFile(string(data))
+}
@@ -0,0 +1,169 @@
+package utils
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/yagnikpt/flashback/internal/models"
+	"go.yaml.in/yaml/v3"
+)
+
+type OutputFormat string
+
+const (
+	OutputTable    OutputFormat = "table"
+	OutputJSON     OutputFormat = "json"
+	OutputNDJSON   OutputFormat = "ndjson"
+	OutputYAML     OutputFormat = "yaml"
+	OutputCSV      OutputFormat = "csv"
+	OutputMarkdown OutputFormat = "markdown"
+)
+
+var OutputFormats = []OutputFormat{OutputTable, OutputJSON, OutputNDJSON, OutputYAML, OutputCSV, OutputMarkdown}
+
+func ParseOutputFormat(value string) (OutputFormat, error) {
+	if value == "md" {
+		value = string(OutputMarkdown)
+	}
+	for _, f := range OutputFormats {
+		if string(f) == value {
+			return f, nil
+		}
+	}
+
+	names := make([]string, len(OutputFormats))
+	for i, f := range OutputFormats {
+		names[i] = string(f)
+	}
+	return "", fmt.Errorf("invalid output format %q (expected %s)", value, strings.Join(names, ", "))
+}
+
+// WriteNotes renders notes in one of the machine-readable formats. The table
+// format is rendered by the caller since it depends on the command.
+func WriteNotes(w io.Writer, format OutputFormat, notes []models.ScoredFlashback) error {
+	switch format {
+	case OutputJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(notes)
+	case OutputNDJSON:
+		encoder := json.NewEncoder(w)
+		for _, note := range notes {
+			if err := encoder.Encode(note); err != nil {
+				return err
+			}
+		}
+		return nil
+	case OutputYAML:
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(notes); err != nil {
+			return err
+		}
+		return encoder.Close()
+	case OutputCSV:
+		return writeNotesCSV(w, notes)
+	case OutputMarkdown:
+		for _, note := range notes {
+			if _, err := io.WriteString(w, FormatNoteMarkdown(note)); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported output format %q", format)
+	}
+}
+
+// WriteNote renders a single note; JSON and YAML produce an object rather
+// than a list.
+func WriteNote(w io.Writer, format OutputFormat, note models.ScoredFlashback) error {
+	switch format {
+	case OutputJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(note)
+	case OutputYAML:
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(note); err != nil {
+			return err
+		}
+		return encoder.Close()
+	default:
+		return WriteNotes(w, format, []models.ScoredFlashback{note})
+	}
+}
+
+func writeNotesCSV(w io.Writer, notes []models.ScoredFlashback) error {
+	writer := csv.NewWriter(w)
+	header := []string{"id", "type", "created_at", "content", "tags", "metadata", "score", "similarity", "snippet"}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, note := range notes {
+		metadata, err := json.Marshal(note.Metadata)
+		if err != nil {
+			return err
+		}
+		record := []string{
+			note.ID,
+			note.Type,
+			note.CreatedAt,
+			note.Content,
+			strings.Join(note.Tags, ", "),
+			string(metadata),
+			formatOptionalFloat(note.Score),
+			formatOptionalFloat(note.Similarity),
+			note.Snippet,
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func formatOptionalFloat(v *float64) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*v, 'f', 4, 64)
+}
+
+// FormatNoteMarkdown renders a note as a Markdown section.
+func FormatNoteMarkdown(note models.ScoredFlashback) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## %s\n\n", note.ID)
+	if isURLNote(note.Type) {
+		fmt.Fprintf(&b, "<%s>\n\n", note.Content)
+	} else {
+		b.WriteString(note.Content + "\n\n")
+	}
+	fmt.Fprintf(&b, "- **type:** %s\n", note.Type)
+	fmt.Fprintf(&b, "- **created:** %s\n", note.CreatedAt)
+	if len(note.Tags) > 0 {
+		fmt.Fprintf(&b, "- **tags:** %s\n", strings.Join(note.Tags, ", "))
+	}
+	for _, key := range SortedKeys(note.Metadata) {
+		if ignoreList[key] {
+			continue
+		}
+		fmt.Fprintf(&b, "- **%s:** %s\n", key, note.Metadata[key])
+	}
+	if note.Similarity != nil {
+		fmt.Fprintf(&b, "- **similarity:** %s\n", formatOptionalFloat(note.Similarity))
+	}
+	if note.Snippet != "" {
+		fmt.Fprintf(&b, "\n> %s\n", note.Snippet)
+	}
+	b.WriteString("\n")
+	return b.String()
+}
@@ -0,0 +1,34 @@
+package utils
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// ShowInPager shows text through $PAGER, or less, when stdout is a terminal,
+// and writes it to stdout otherwise or when no pager can be started.
+func ShowInPager(text string) error {
+	if !IsTerminal(os.Stdout) {
+		_, err := io.WriteString(os.Stdout, text)
+		return err
+	}
+
+	pager := os.Getenv("PAGER")
+	if pager == "" {
+		pager = "less -R"
+	}
+	parts := strings.Fields(pager)
+	path, err := exec.LookPath(parts[0])
+	if err != nil {
+		_, err := io.WriteString(os.Stdout, text)
+		return err
+	}
+
+	cmd := exec.Command(path, parts[1:]...)
+	cmd.Stdin = strings.NewReader(text)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
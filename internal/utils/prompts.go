@@ -1,5 +1,9 @@
 package utils
 
+// PromptVersion identifies the prompts below. Bump it when changing them so
+// that flashback reindex regenerates metadata made with older prompts.
+const PromptVersion = 1
+
 var SimpleTextExtractionPrompt = `
 You are a metadata extraction assistant.
 You are given plain text content.
@@ -58,3 +62,17 @@ Behavior:
 - Do not output commentary, reasoning steps, or anything outside the structured metadata.
 - If no useful data can be extracted, return an empty JSON object.
 `
+
+// AskPrompt answers a question from retrieved notes. It isn't covered by
+// PromptVersion as answers aren't stored.
+var AskPrompt = `
+You answer questions using only the user's saved notes, given below the question as <note> elements.
+Each note has an id attribute.
+
+Rules:
+- Base every statement on the notes. Do not use outside knowledge or make up information.
+- Cite the notes you use by putting their id in square brackets right after the statement, eg. [3Fw9xYbqTz]. Only cite ids of the notes given.
+- If the notes don't contain the answer, say that your notes don't cover it, in one sentence, and cite nothing.
+- Be concise. Quote commands, code and URLs exactly as they appear in the notes.
+- Answer in plain text or Markdown, in the language of the question.
+`
@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+
+	"charm.land/lipgloss/v2"
+)
+
+var snippetStyle = lipgloss.NewStyle().Faint(true)
+var highlightStyle = lipgloss.NewStyle().Bold(true)
+
+// Snippet cuts a window of about length characters out of text, centred on
+// the first word of query found in it. Whitespace is collapsed, and ellipses
+// mark the cuts.
+func Snippet(text, query string, length int) string {
+	runes := []rune(strings.Join(strings.Fields(text), " "))
+	if len(runes) <= length {
+		return string(runes)
+	}
+
+	lower := strings.ToLower(string(runes))
+	center := 0
+	for _, term := range queryTerms(query) {
+		if i := strings.Index(lower, term); i >= 0 {
+			center = len([]rune(lower[:i]))
+			break
+		}
+	}
+
+	start := max(center-length/3, 0)
+	end := min(start+length, len(runes))
+	start = max(end-length, 0)
+	// Don't cut words in half.
+	for start > 0 && runes[start-1] != ' ' && center-start < length/2 {
+		start--
+	}
+	for end < len(runes) && runes[end] != ' ' && end-start < length+20 {
+		end++
+	}
+
+	snippet := strings.TrimSpace(string(runes[start:end]))
+	if start > 0 {
+		snippet = "…" + snippet
+	}
+	if end < len(runes) {
+		snippet += "…"
+	}
+	return snippet
+}
+
+// HighlightTerms renders the words of query found in text in bold, and the
+// rest faint.
+func HighlightTerms(text, query string) string {
+	terms := queryTerms(query)
+	if len(terms) == 0 {
+		return snippetStyle.Render(text)
+	}
+	quoted := make([]string, len(terms))
+	for i, term := range terms {
+		quoted[i] = regexp.QuoteMeta(term)
+	}
+	pattern := regexp.MustCompile(`(?i)` + strings.Join(quoted, "|"))
+
+	var b strings.Builder
+	last := 0
+	for _, match := range pattern.FindAllStringIndex(text, -1) {
+		b.WriteString(snippetStyle.Render(text[last:match[0]]))
+		b.WriteString(highlightStyle.Render(text[match[0]:match[1]]))
+		last = match[1]
+	}
+	b.WriteString(snippetStyle.Render(text[last:]))
+	return b.String()
+}
+
+// queryTerms returns the lowercase words of query worth highlighting.
+func queryTerms(query string) []string {
+	var terms []string
+	for _, word := range strings.Fields(strings.ToLower(query)) {
+		word = strings.Trim(word, `"'.,;:!?()`)
+		if len([]rune(word)) >= 3 {
+			terms = append(terms, word)
+		}
+	}
+	return terms
+}
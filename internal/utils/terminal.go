@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"os"
+
+	"golang.org/x/term"
+)
+
+const (
+	defaultWidth  = 80
+	defaultHeight = 24
+)
+
+// TerminalSize returns the size of the terminal attached to stdout, or a
+// default size when stdout isn't a terminal (piped, redirected, cron).
+func TerminalSize() (width, height int) {
+	width, height, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil || width <= 0 || height <= 0 {
+		return defaultWidth, defaultHeight
+	}
+	return width, height
+}
+
+func IsTerminal(f *os.File) bool {
+	return term.IsTerminal(int(f.Fd()))
+}
+
+// IsInteractive reports whether both stdin and stdout are terminals, which
+// full-screen and editor based commands need.
+func IsInteractive() bool {
+	return IsTerminal(os.Stdin) && IsTerminal(os.Stdout)
+}
@@ -0,0 +1,191 @@
+// Package worker runs the jobs queued for notes, such as generating the
+// metadata and embedding of a note that was just added.
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/yagnikpt/flashback/internal/app"
+	"github.com/yagnikpt/flashback/internal/ingest"
+	"github.com/yagnikpt/flashback/internal/providers"
+)
+
+const (
+	pollInterval = 5 * time.Second
+	jobTimeout   = time.Minute
+)
+
+// Event reports that a job started, finished or failed.
+type Event struct {
+	Job app.Job
+	// Message describes the stage the job is in while it runs.
+	Message string
+	// Done is set once the job has finished; Err is its error, if any.
+	Done bool
+	Err  error
+}
+
+type Options struct {
+	// Once exits when no job is due instead of waiting for more.
+	Once bool
+}
+
+// Run processes due jobs one at a time until ctx is cancelled, or, with
+// opts.Once, until no job is due. report may be nil.
+func Run(ctx context.Context, a *app.App, opts Options, report func(Event)) error {
+	if report == nil {
+		report = func(Event) {}
+	}
+	if err := a.ReleaseStaleJobs(ctx); err != nil {
+		return err
+	}
+
+	for {
+		job, ok, err := a.ClaimJob(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+		if !ok {
+			if opts.Once {
+				return nil
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(pollInterval):
+			}
+			continue
+		}
+
+		err = Process(ctx, a, job, func(message string) {
+			report(Event{Job: job, Message: message})
+		})
+		report(Event{Job: job, Done: true, Err: err})
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+}
+
+// Process runs a claimed job and records its outcome.
+func Process(ctx context.Context, a *app.App, job app.Job, emit func(string)) error {
+	jobCtx, cancel := context.WithTimeout(ctx, jobTimeout)
+	defer cancel()
+
+	jobErr := run(jobCtx, a, job, emit)
+	// The outcome is recorded even when ctx was cancelled meanwhile.
+	recordCtx := context.WithoutCancel(ctx)
+	if jobErr == nil {
+		return a.CompleteJob(recordCtx, job.ID)
+	}
+
+	// Only rate limits, unavailable servers and timeouts are retried. Bad API
+	// keys, rejected requests and missing notes won't fix themselves; they
+	// are retried with flashback jobs retry once the user has intervened.
+	retry := providers.IsRetryable(jobErr)
+	if ctx.Err() != nil {
+		// Interrupted rather than failed: give the attempt back.
+		job.Attempts--
+	}
+	if err := a.FailJob(recordCtx, job, jobErr, retry); err != nil {
+		log.Println("Error recording failed job:", err)
+	}
+	return jobErr
+}
+
+var errUnknownJob = errors.New("unknown job kind")
+
+func run(ctx context.Context, a *app.App, job app.Job, emit func(string)) error {
+	switch job.Kind {
+	case app.JobEnrich:
+		return Enrich(ctx, a, job.FlashbackID, true, emit)
+	case app.JobEmbed:
+		return Enrich(ctx, a, job.FlashbackID, false, emit)
+	default:
+		return fmt.Errorf("%w %q", errUnknownJob, job.Kind)
+	}
+}
+
+// Enrich regenerates the embedding of a stored note and, with metadata set,
+// its generated metadata and AI tags first. Metadata from the user or an
+// import is kept.
+func Enrich(ctx context.Context, a *app.App, id string, metadata bool, emit func(string)) error {
+	note, err := storedNote(ctx, a, id)
+	if err != nil {
+		return err
+	}
+
+	pipeline := &ingest.Pipeline{Steps: []ingest.Step{ingest.Embed(a), ingest.Save(a)}}
+	if metadata {
+		kept, err := a.GetKeptMetadata(ctx, id)
+		if err != nil {
+			return err
+		}
+		tags, err := a.GetUserTags(ctx, id)
+		if err != nil {
+			return err
+		}
+		note.Metadata, note.Tags = kept, tags
+		pipeline = ingest.Enrichment(a)
+	}
+	return runPipeline(ctx, pipeline, note, emit)
+}
+
+// RefreshPage fetches the page behind a URL note again, saves it as a new
+// snapshot if it changed, and re-embeds its passages. The snapshot is saved
+// before anything is embedded, so it is kept even if embedding fails.
+func RefreshPage(ctx context.Context, a *app.App, id string, emit func(string)) error {
+	note, err := storedNote(ctx, a, id)
+	if err != nil {
+		return err
+	}
+	if note.Type != "url" {
+		return fmt.Errorf("note %s is not a URL", id)
+	}
+	note.Body = ""
+
+	saveSnapshot := ingest.Step{Stage: ingest.StageStore, Run: func(ctx context.Context, note *ingest.Note, emit func(string)) error {
+		emit("Saving the page...")
+		return a.StorePage(ctx, note.ID, note.Body, note.HTML, nil)
+	}}
+	pipeline := &ingest.Pipeline{Steps: []ingest.Step{ingest.Load(a), saveSnapshot, ingest.Embed(a), ingest.Save(a)}}
+	return runPipeline(ctx, pipeline, note, emit)
+}
+
+func storedNote(ctx context.Context, a *app.App, id string) (*ingest.Note, error) {
+	stored, err := a.GetNoteByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	body, err := a.GetPageText(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return &ingest.Note{
+		ID:       stored.ID,
+		Content:  stored.Content,
+		Tags:     stored.Tags,
+		Type:     stored.Type,
+		Metadata: stored.Metadata,
+		Body:     body,
+	}, nil
+}
+
+func runPipeline(ctx context.Context, pipeline *ingest.Pipeline, note *ingest.Note, emit func(string)) error {
+	events := make(chan ingest.Event)
+	done := make(chan error, 1)
+	go func() {
+		done <- pipeline.Run(ctx, note, events)
+	}()
+	for event := range events {
+		emit(event.Message)
+	}
+	return <-done
+}
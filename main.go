@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
@@ -20,19 +21,19 @@ import (
 func main() {
 	dataDir, err := utils.GetLocalDataDir()
 	if err != nil {
-		fmt.Println("Error getting local data directory:", err)
+		fmt.Fprintln(os.Stderr, "Error getting local data directory:", err)
 		os.Exit(1)
 	}
 
 	configDir, err := utils.GetConfigDir()
 	if err != nil {
-		fmt.Println("Error getting local data directory:", err)
+		fmt.Fprintln(os.Stderr, "Error getting local data directory:", err)
 		os.Exit(1)
 	}
 	configFile := filepath.Join(configDir, "config.toml")
 	cfg, err := config.LoadConfig(configFile)
 	if err != nil {
-		fmt.Println("Error loading config:", err)
+		fmt.Fprintln(os.Stderr, "Error loading config:", err)
 		os.Exit(1)
 	}
 
@@ -41,7 +42,7 @@ func main() {
 	fLog, err := os.OpenFile(logFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 	log.SetOutput(fLog)
 	if err != nil {
-		fmt.Println("fatal:", err)
+		fmt.Fprintln(os.Stderr, "fatal:", err)
 		os.Exit(1)
 	}
 	defer fLog.Close()
@@ -57,15 +58,30 @@ func main() {
 		log.Fatal(err)
 	}
 
-	if cfg.APIKey == "" {
+	if cfg.NeedsAPIKey() {
+		if !utils.IsInteractive() {
+			fmt.Fprintf(os.Stderr, "No API key set. Run flashback in a terminal or add api_key to %s.\n", configFile)
+			os.Exit(1)
+		}
 		apikeyinput.Run(configFile, cfg)
 		cfg, _ = config.LoadConfig(configFile)
-		if cfg.APIKey == "" {
-			fmt.Println("No API key set.")
+		if cfg.NeedsAPIKey() {
+			fmt.Fprintln(os.Stderr, "No API key set.")
 			os.Exit(0)
 		}
 	}
 
-	app := app.NewApp(db, cfg)
-	cmd.Execute(app)
+	app, err := app.NewApp(db, cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error initializing AI provider:", err)
+		os.Exit(1)
+	}
+	if _, err := app.PurgeExpiredTrash(context.Background()); err != nil {
+		log.Println("Error deleting expired notes from the trash:", err)
+	}
+	if code := cmd.Execute(app); code != 0 {
+		db.Close()
+		fLog.Close()
+		os.Exit(code)
+	}
 }